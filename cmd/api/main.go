@@ -1,18 +1,31 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"time"
 
 	"easy-storage/internal/config"
 	"easy-storage/internal/domain/access"
+	"easy-storage/internal/domain/auth"
 	"easy-storage/internal/domain/file"
 	"easy-storage/internal/domain/folder"
+	"easy-storage/internal/domain/group"
 	"easy-storage/internal/domain/share"
+	"easy-storage/internal/domain/transfer"
+	"easy-storage/internal/domain/trash"
+	"easy-storage/internal/domain/upload"
 	"easy-storage/internal/domain/user"
 	"easy-storage/internal/infrastructure/api"
 	"easy-storage/internal/infrastructure/auth/jwt"
+	"easy-storage/internal/infrastructure/jobs"
+	"easy-storage/internal/infrastructure/notification"
 	"easy-storage/internal/infrastructure/persistence"
 	"easy-storage/internal/infrastructure/persistence/gorm/repositories"
+	"easy-storage/internal/infrastructure/storage"
+	"easy-storage/internal/infrastructure/storage/local"
+	"easy-storage/internal/infrastructure/storage/memory"
 	"easy-storage/internal/infrastructure/storage/s3"
 
 	"github.com/gofiber/fiber/v2"
@@ -31,43 +44,111 @@ func main() {
 	}
 
 	// Initialize storage provider
-	storageProvider, err := s3.NewS3Provider(&cfg.Storage)
+	storageProvider, err := newStorageProvider(cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage provider: %v", err)
 	}
 
+	// Initialize email delivery
+	notifier, err := newNotifier(&cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to initialize mail notifier: %v", err)
+	}
+
+	// Initialize JWT provider
+	jwtProvider, err := newJWTProvider(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT provider: %v", err)
+	}
+
 	// Initialize repositories
 	userRepo := repositories.NewGormUserRepository(db)
 	fileRepo := repositories.NewGormFileRepository(db)
+	fileVersionRepo := repositories.NewGormFileVersionRepository(db)
 	folderRepo := repositories.NewGormFolderRepository(db)
 	shareRepo := repositories.NewShareRepository(db) // Add share repository
+	shareEventRepo := repositories.NewShareAccessEventRepository(db)
+	groupRepo := repositories.NewGormGroupRepository(db)
+	transferRepo := repositories.NewGormTransferRepository(db)
+	uploadRepo := repositories.NewGormUploadRepository(db)
+	reservationRepo := repositories.NewGormReservationRepository(db)
+	refreshTokenRepo := repositories.NewGormRefreshTokenRepository(db)
+	sessionRepo := repositories.NewGormSessionRepository(db)
+	personalTokenRepo := repositories.NewGormPersonalTokenRepository(db)
+	mfaRepo := repositories.NewGormMFARepository(db)
 
 	// Initialize domain services
 	userService := user.NewService(userRepo)
 	storageService := userService.GetStorageService()
-	fileService := file.NewService(fileRepo, folderRepo, storageProvider, storageService)
+	sessionCacheTTL := time.Duration(cfg.Auth.SessionCacheSeconds) * time.Second
+	mfaService := auth.NewMFAService(mfaRepo, cfg.Auth.TOTPIssuer)
+	authService := auth.NewService(refreshTokenRepo, sessionRepo, userService, jwtProvider, mfaService, sessionCacheTTL)
+	personalTokenService := auth.NewPersonalTokenService(personalTokenRepo)
+	fileService := file.NewService(fileRepo, fileVersionRepo, folderRepo, storageProvider, storageService, file.VersionRetention{
+		KeepLast: cfg.Versions.KeepLast,
+		KeepDays: cfg.Versions.KeepDays,
+	})
 	folderService := folder.NewService(folderRepo, fileService)
-	shareService := share.NewService(shareRepo)
-	accessService := access.NewService(fileService, shareService)
+	groupService := group.NewService(groupRepo)
+	passwordLockout := time.Duration(cfg.Share.PasswordLockoutMinutes) * time.Minute
+	shareService := share.NewService(shareRepo, shareEventRepo, groupService, userRepo, notifier, share.PasswordLimits{
+		MaxAttempts:      cfg.Share.PasswordMaxAttempts,
+		MaxAttemptsPerIP: cfg.Share.PasswordMaxAttemptsPerIP,
+		Lockout:          passwordLockout,
+	})
+	accessService := access.NewService(fileService, folderService, shareService)
+	transferService := transfer.NewService(transferRepo, fileService, folderService, userService)
+	trashService := trash.NewService(fileService, folderService, time.Duration(cfg.Trash.RetentionDays)*24*time.Hour)
+	uploadExpiry := time.Duration(cfg.Upload.ExpiryHours) * time.Hour
+	uploadService := upload.NewService(uploadRepo, storageProvider, fileService, storageService, upload.Options{
+		MaxSize:  int64(cfg.Upload.MaxSizeMB) * 1024 * 1024,
+		PartSize: int64(cfg.Storage.PartSizeMB) * 1024 * 1024,
+		Expiry:   uploadExpiry,
+	})
+	directUploadService := upload.NewDirectService(reservationRepo, storageProvider, fileService, storageService, upload.DirectOptions{
+		MaxSize: int64(cfg.Upload.MaxSizeMB) * 1024 * 1024,
+		Expiry:  time.Duration(cfg.Upload.DirectExpiryMinutes) * time.Minute,
+	})
 
-	// Initialize JWT provider
-	jwtProvider := jwt.NewProvider(
-		cfg.Auth.JWTSecret,
-		cfg.Auth.TokenExpiry,
-		cfg.Auth.RefreshExpiry,
-	)
+	// Background jobs
+	jobs.RunPeriodically(context.Background(), "purge-expired-uploads", time.Hour, uploadService.PurgeExpired)
+	jobs.RunPeriodically(context.Background(), "purge-trash", time.Hour, trashService.PurgeExpired)
+	jobs.RunPeriodically(context.Background(), "prune-expired-file-versions", 24*time.Hour, fileService.PruneExpiredVersions)
+	jobs.RunPeriodically(context.Background(), "release-expired-upload-reservations", 10*time.Minute, directUploadService.ReleaseExpired)
+	jobs.RunPeriodically(context.Background(), "purge-expired-sessions", 24*time.Hour, authService.PurgeExpired)
+	if sessionCacheTTL > 0 {
+		jobs.RunPeriodically(context.Background(), "prune-session-cache", 10*sessionCacheTTL, authService.PruneSessionCache)
+	}
+	jobs.RunPeriodically(context.Background(), "prune-mfa-attempts", 15*time.Minute, mfaService.PruneAttempts)
+	if passwordLockout > 0 {
+		jobs.RunPeriodically(context.Background(), "prune-share-password-attempts", passwordLockout, shareService.PruneAttempts)
+	}
+	jobs.RunPeriodically(context.Background(), "abort-stale-multipart-uploads", time.Hour, func() error {
+		// Leave resumable uploads alone until well after they expire
+		return storageProvider.AbortStaleMultipartUploads(2 * uploadExpiry)
+	})
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		AppName: "easy-storage",
+		// Stream large request bodies instead of rejecting them, needed for upload chunks
+		StreamRequestBody: true,
 	})
 
 	// Middleware
 	app.Use(fiberLogger.New())
-	app.Use(cors.New())
+	app.Use(cors.New(cors.Config{
+		// Let browser tus clients read the upload headers
+		ExposeHeaders: "Location,Upload-Offset,Upload-Length,Tus-Resumable,Tus-Version,Tus-Extension,Tus-Max-Size,X-File-Id",
+	}))
 
 	// Setup routes
-	api.SetupRoutes(app, userService, fileService, folderService, shareService, accessService, jwtProvider)
+	api.SetupRoutes(app, userService, authService, personalTokenService, mfaService, fileService, folderService, shareService, groupService, transferService, accessService, trashService, uploadService, directUploadService, jwtProvider)
+
+	// The local provider serves its own signed URLs through the API
+	if localProvider, ok := storageProvider.(*local.LocalProvider); ok {
+		api.SetupLocalStorageRoutes(app, localProvider)
+	}
 
 	// Default route
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -78,3 +159,50 @@ func main() {
 	log.Printf("Server starting on port %s", cfg.Server.Port)
 	log.Fatal(app.Listen(":" + cfg.Server.Port))
 }
+
+// newStorageProvider creates the storage backend selected by STORAGE_TYPE
+// The memory provider is refused unless STORAGE_ALLOW_MEMORY is set, whatever APP_ENV is
+func newStorageProvider(cfg *config.Config) (storage.Provider, error) {
+	switch cfg.Storage.Type {
+	case "s3":
+		return s3.NewS3Provider(&cfg.Storage)
+	case "local":
+		return local.NewLocalProvider(&cfg.Storage)
+	case "memory":
+		// Contents are lost on restart and its signed URLs can't be fetched, downloads and
+		// direct uploads don't work, so it is only meant for tests and local development
+		if !cfg.Storage.AllowMemory {
+			return nil, fmt.Errorf("STORAGE_TYPE=memory requires STORAGE_ALLOW_MEMORY=true")
+		}
+		log.Printf("Using in-memory storage provider, files will not be persisted and signed URLs will not work")
+		return memory.NewMemoryProvider(), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Storage.Type)
+	}
+}
+
+// newJWTProvider creates the JWT provider signing with JWT_ALGORITHM
+// The default HS256 secret is public, it is refused unless APP_ENV is development
+func newJWTProvider(cfg *config.Config) (*jwt.Provider, error) {
+	if cfg.Auth.JWTAlgorithm == "HS256" && cfg.Auth.JWTSecret == config.DefaultJWTSecret {
+		if !cfg.Server.IsDevelopment() {
+			return nil, fmt.Errorf("JWT_SECRET must be changed from its default value, or APP_ENV set to development")
+		}
+		log.Printf("Using the default JWT secret, tokens can be forged by anyone")
+	}
+
+	return jwt.NewProvider(&cfg.Auth)
+}
+
+// newNotifier creates the email delivery selected by MAIL_DRIVER
+func newNotifier(cfg *config.MailConfig) (share.Notifier, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return notification.NewSMTPNotifier(cfg)
+	case "log":
+		log.Printf("Using log mail driver, emails will not be sent")
+		return notification.NewLogNotifier(cfg.AppURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Driver)
+	}
+}
@@ -4,19 +4,34 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 )
 
+// DefaultJWTSecret is the JWT secret used when JWT_SECRET is not set, only accepted in development
+const DefaultJWTSecret = "your-secret-key"
+
 // Config stores all configuration for the application
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Storage  StorageConfig
 	Auth     AuthConfig
+	Upload   UploadConfig
+	Versions VersionConfig
+	Trash    TrashConfig
+	Share    ShareConfig
+	Mail     MailConfig
 }
 
 // ServerConfig stores server related configuration
 type ServerConfig struct {
-	Port string
+	Port        string
+	Environment string // "development" relaxes checks meant for deployments, anything else counts as production
+}
+
+// IsDevelopment checks if the API runs in development mode
+func (c *ServerConfig) IsDevelopment() bool {
+	return c.Environment == "development"
 }
 
 // DatabaseConfig stores database related configuration
@@ -30,48 +45,136 @@ type DatabaseConfig struct {
 
 // StorageConfig stores file storage related configuration
 type StorageConfig struct {
-	Type           string // "s3", "local", etc.
+	Type           string // "s3", "local" or "memory"
+	AllowMemory    bool   // Explicit opt-in for the "memory" storage type, which loses every file on restart
 	Endpoint       string
 	Region         string
 	Bucket         string
 	AccessKey      string
 	SecretKey      string
 	ForcePathStyle bool
+	LocalPath      string // Root directory for the "local" storage type
+	SigningKey     string // Secret used to sign local download URLs
+	PublicURL      string // Base URL the API is reachable at, used in signed URLs
+	PartSizeMB     int    // Size of each part of a multipart upload in megabytes
+	Concurrency    int    // Number of parts uploaded in parallel
 }
 
 // AuthConfig stores authentication related configuration
 type AuthConfig struct {
-	JWTSecret     string
-	TokenExpiry   int // in hours
-	RefreshExpiry int // in days
+	JWTSecret      string // Secret of HS256 tokens
+	JWTAlgorithm   string // "HS256", "RS256" or "EdDSA"
+	SigningKeyFile string // PEM private key signing RS256 and EdDSA tokens
+	// PEM public keys of previous signing keys, their tokens stay valid until they expire
+	VerificationKeyFiles []string
+	TokenExpiry          int // in hours
+	RefreshExpiry        int // in days
+	// How long the state of a session is cached when authenticating requests, in seconds
+	// A session signed out through another API instance is only rejected once it expires
+	SessionCacheSeconds int
+	TOTPIssuer          string // Name authenticator apps show next to the accounts
+}
+
+// UploadConfig stores resumable and direct upload related configuration
+type UploadConfig struct {
+	MaxSizeMB           int // Maximum size of an upload in megabytes
+	ExpiryHours         int // How long an unfinished upload can be resumed
+	DirectExpiryMinutes int // How long a signed direct upload URL is valid
+}
+
+// VersionConfig stores file version retention configuration, zero keeps versions forever
+type VersionConfig struct {
+	KeepLast int // Number of versions kept per file
+	KeepDays int // Number of days old versions are kept
+}
+
+// TrashConfig stores trash related configuration
+type TrashConfig struct {
+	RetentionDays int // Days items stay in the trash before being purged, zero keeps them forever
+}
+
+// ShareConfig stores share password throttling configuration, zero attempts disables a limit
+type ShareConfig struct {
+	PasswordMaxAttempts      int // Failed password attempts allowed per share before it is locked
+	PasswordMaxAttemptsPerIP int // Failed password attempts allowed per client IP before it is locked
+	PasswordLockoutMinutes   int // How long a share or client IP stays locked
+}
+
+// MailConfig stores email delivery configuration
+type MailConfig struct {
+	Driver       string // "smtp" or "log", which only writes emails to the application log
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string // Authentication is skipped when empty
+	SMTPPassword string
+	From         string // Sender address of the emails
+	AppURL       string // Base URL of the web app, used in links sent by email
 }
 
 // Load returns a Config struct filled with values from the environment
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port:        getEnv("PORT", "8080"),
+			Environment: getEnv("APP_ENV", "production"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "127.0.0.1"),
 			Port:     getEnv("DB_PORT", "5432"),
 			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
+			Password: getSecretEnv("DB_PASSWORD", "postgres"),
 			Name:     getEnv("DB_NAME", "easy_storage"),
 		},
 		Storage: StorageConfig{
 			Type:           getEnv("STORAGE_TYPE", "s3"),
+			AllowMemory:    getEnvAsBool("STORAGE_ALLOW_MEMORY", false),
 			Endpoint:       getEnv("STORAGE_ENDPOINT", "http://localhost:9000"),
 			Region:         getEnv("STORAGE_REGION", "us-east-1"),
 			Bucket:         getEnv("STORAGE_BUCKET", "easy-storage"),
 			AccessKey:      getEnv("STORAGE_ACCESS_KEY", "minio"),
-			SecretKey:      getEnv("STORAGE_SECRET_KEY", "minio123"),
+			SecretKey:      getSecretEnv("STORAGE_SECRET_KEY", "minio123"),
 			ForcePathStyle: getEnvAsBool("STORAGE_FORCE_PATH_STYLE", true),
+			LocalPath:      getEnv("STORAGE_LOCAL_PATH", "./data"),
+			SigningKey:     getSecretEnv("STORAGE_SIGNING_KEY", ""),
+			PublicURL:      getEnv("STORAGE_PUBLIC_URL", "http://localhost:8080"),
+			PartSizeMB:     getEnvAsInt("STORAGE_PART_SIZE_MB", 8),
+			Concurrency:    getEnvAsInt("STORAGE_PART_CONCURRENCY", 4),
 		},
 		Auth: AuthConfig{
-			JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
-			TokenExpiry:   getEnvAsInt("TOKEN_EXPIRY", 24),
-			RefreshExpiry: getEnvAsInt("REFRESH_EXPIRY", 7),
+			JWTSecret:            getSecretEnv("JWT_SECRET", DefaultJWTSecret),
+			JWTAlgorithm:         getEnv("JWT_ALGORITHM", "HS256"),
+			SigningKeyFile:       getEnv("JWT_SIGNING_KEY_FILE", ""),
+			VerificationKeyFiles: getEnvAsList("JWT_VERIFICATION_KEY_FILES"),
+			TokenExpiry:          getEnvAsInt("TOKEN_EXPIRY", 24),
+			RefreshExpiry:        getEnvAsInt("REFRESH_EXPIRY", 7),
+			SessionCacheSeconds:  getEnvAsInt("SESSION_CACHE_SECONDS", 30),
+			TOTPIssuer:           getEnv("TOTP_ISSUER", "Easy Storage"),
+		},
+		Upload: UploadConfig{
+			MaxSizeMB:           getEnvAsInt("UPLOAD_MAX_SIZE_MB", 50*1024),
+			ExpiryHours:         getEnvAsInt("UPLOAD_EXPIRY", 24),
+			DirectExpiryMinutes: getEnvAsInt("UPLOAD_DIRECT_EXPIRY_MINUTES", 60),
+		},
+		Versions: VersionConfig{
+			KeepLast: getEnvAsInt("VERSION_KEEP_LAST", 0),
+			KeepDays: getEnvAsInt("VERSION_KEEP_DAYS", 0),
+		},
+		Trash: TrashConfig{
+			RetentionDays: getEnvAsInt("TRASH_RETENTION_DAYS", 30),
+		},
+		Share: ShareConfig{
+			PasswordMaxAttempts:      getEnvAsInt("SHARE_PASSWORD_MAX_ATTEMPTS", 5),
+			PasswordMaxAttemptsPerIP: getEnvAsInt("SHARE_PASSWORD_MAX_ATTEMPTS_PER_IP", 20),
+			PasswordLockoutMinutes:   getEnvAsInt("SHARE_PASSWORD_LOCKOUT_MINUTES", 15),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "1025"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getSecretEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("MAIL_FROM", "Easy Storage <no-reply@easy-storage.local>"),
+			AppURL:       getEnv("APP_URL", "http://localhost:3000"),
 		},
 	}
 }
@@ -87,6 +190,15 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getSecretEnv reads a secret like getEnv without ever logging its value
+func getSecretEnv(key, defaultValue string) string {
+	log.Printf("Fetching environment variable: %s", key)
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsInt(key string, defaultValue int) int {
 	if value, exists := os.LookupEnv(key); exists {
 		if intVal, err := strconv.Atoi(value); err == nil {
@@ -96,6 +208,17 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsList reads a comma separated list, empty items are skipped
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value, exists := os.LookupEnv(key); exists {
 		if boolVal, err := strconv.ParseBool(value); err == nil {
@@ -0,0 +1,157 @@
+package access
+
+import (
+	"context"
+	"testing"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/folder"
+	"easy-storage/internal/domain/share"
+
+	"github.com/google/uuid"
+)
+
+// allCapabilities lists every capability a role can give
+var allCapabilities = []share.Capability{
+	share.CapView, share.CapDownload, share.CapComment, share.CapUpload,
+	share.CapRename, share.CapDelete, share.CapReshare, share.CapManage,
+}
+
+// granted lists the capabilities each role is expected to give, independently of share.Roles
+var granted = map[share.SharePermission][]share.Capability{
+	share.Viewer:    {share.CapView, share.CapDownload},
+	share.Commenter: {share.CapView, share.CapDownload, share.CapComment},
+	share.Editor:    {share.CapView, share.CapDownload, share.CapComment, share.CapUpload, share.CapRename, share.CapDelete},
+	share.CoOwner:   allCapabilities,
+}
+
+// wantAuthorized returns the error an authorization of a role should fail with, nil when it is allowed
+func wantAuthorized(role share.SharePermission, capability share.Capability, notFound error) error {
+	if role == "" {
+		return notFound
+	}
+	for _, allowed := range granted[role] {
+		if allowed == capability {
+			return nil
+		}
+	}
+	return ErrPermissionDenied
+}
+
+func TestCheckCapability(t *testing.T) {
+	tests := []struct {
+		role       share.SharePermission
+		capability share.Capability
+		want       error
+	}{
+		{role: "", capability: share.CapView, want: file.ErrFileNotFound},
+		{role: share.Viewer, capability: share.CapView, want: nil},
+		{role: share.Viewer, capability: share.CapDownload, want: nil},
+		{role: share.Viewer, capability: share.CapComment, want: ErrPermissionDenied},
+		{role: share.Commenter, capability: share.CapComment, want: nil},
+		{role: share.Commenter, capability: share.CapUpload, want: ErrPermissionDenied},
+		{role: share.Editor, capability: share.CapUpload, want: nil},
+		{role: share.Editor, capability: share.CapRename, want: nil},
+		{role: share.Editor, capability: share.CapDelete, want: nil},
+		{role: share.Editor, capability: share.CapReshare, want: ErrPermissionDenied},
+		{role: share.CoOwner, capability: share.CapReshare, want: nil},
+		{role: share.CoOwner, capability: share.CapManage, want: nil},
+		{role: share.Owner, capability: share.CapManage, want: nil},
+	}
+	for _, tt := range tests {
+		if got := checkCapability(tt.role, tt.capability, file.ErrFileNotFound); got != tt.want {
+			t.Errorf("checkCapability(%q, %s) = %v, want %v", tt.role, tt.capability, got, tt.want)
+		}
+	}
+}
+
+// sharedWith creates a folder of the owner holding a subfolder and a file
+// and shares the folder with the other user, an empty role shares nothing
+func (f *fixture) sharedWith(t *testing.T, role share.SharePermission) (*folder.Folder, *folder.Folder, *file.File) {
+	t.Helper()
+
+	shared := f.createFolder(t, "projects", "", f.owner)
+	child := f.createFolder(t, "drafts", shared.ID, f.owner)
+	inside := f.uploadFile(t, "notes.txt", child.ID, f.owner)
+
+	if role != "" {
+		_, err := f.shares.CreateUserShare(context.Background(), uuid.MustParse(f.owner), uuid.MustParse(shared.ID), "folder", uuid.MustParse(f.other), role)
+		if err != nil {
+			t.Fatalf("CreateUserShare: %v", err)
+		}
+	}
+	return shared, child, inside
+}
+
+func TestAuthorizeRoles(t *testing.T) {
+	roles := append([]share.SharePermission{""}, share.Roles...)
+
+	for _, role := range roles {
+		f := newFixture(t)
+		shared, child, inside := f.sharedWith(t, role)
+
+		name := string(role)
+		if name == "" {
+			name = "no role"
+		}
+		for _, capability := range allCapabilities {
+			t.Run(name+"/"+string(capability), func(t *testing.T) {
+				ctx := context.Background()
+				wantFile := wantAuthorized(role, capability, file.ErrFileNotFound)
+				wantFolder := wantAuthorized(role, capability, folder.ErrFolderNotFound)
+
+				if err := f.access.AuthorizeFile(ctx, f.other, inside, capability); err != wantFile {
+					t.Errorf("AuthorizeFile = %v, want %v", err, wantFile)
+				}
+				if err := f.access.AuthorizeFolder(ctx, f.other, child, capability); err != wantFolder {
+					t.Errorf("AuthorizeFolder on a subfolder = %v, want %v", err, wantFolder)
+				}
+				if err := f.access.Authorize(ctx, f.other, "folder", shared.ID, capability); err != wantFolder {
+					t.Errorf("Authorize folder = %v, want %v", err, wantFolder)
+				}
+				if err := f.access.Authorize(ctx, f.other, "file", inside.ID, capability); err != wantFile {
+					t.Errorf("Authorize file = %v, want %v", err, wantFile)
+				}
+
+				// The owner can do anything whatever is shared
+				if err := f.access.AuthorizeFile(ctx, f.owner, inside, capability); err != nil {
+					t.Errorf("AuthorizeFile by the owner = %v, want nil", err)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorizeUploadRoles(t *testing.T) {
+	tests := []struct {
+		role    share.SharePermission
+		wantErr error
+	}{
+		{role: "", wantErr: file.ErrInvalidFolder},
+		{role: share.Viewer, wantErr: ErrPermissionDenied},
+		{role: share.Commenter, wantErr: ErrPermissionDenied},
+		{role: share.Editor, wantErr: nil},
+		{role: share.CoOwner, wantErr: nil},
+	}
+	for _, tt := range tests {
+		f := newFixture(t)
+		_, child, _ := f.sharedWith(t, tt.role)
+
+		ownerID, err := f.access.AuthorizeUpload(context.Background(), f.other, child.ID)
+		if err != tt.wantErr {
+			t.Errorf("AuthorizeUpload with role %q = %v, want %v", tt.role, err, tt.wantErr)
+			continue
+		}
+		if err == nil && ownerID != f.owner {
+			t.Errorf("AuthorizeUpload with role %q returned owner %s, want %s", tt.role, ownerID, f.owner)
+		}
+	}
+}
+
+func TestAuthorizeInvalidResourceType(t *testing.T) {
+	f := newFixture(t)
+
+	if err := f.access.Authorize(context.Background(), f.owner, "album", uuid.New().String(), share.CapView); err != ErrInvalidResourceType {
+		t.Errorf("Authorize error = %v, want ErrInvalidResourceType", err)
+	}
+}
@@ -6,4 +6,10 @@ import "errors"
 var (
 	ErrInvalidResourceType   = errors.New("invalid resource type")
 	ErrServiceNotInitialized = errors.New("service not properly initialized")
+
+	// ErrResourceNotInShare is returned when a resource is outside the folder a share gives access to
+	ErrResourceNotInShare = errors.New("resource is not part of the share")
+
+	// ErrPermissionDenied is returned when the role of a user on a resource lacks a capability
+	ErrPermissionDenied = errors.New("permission denied")
 )
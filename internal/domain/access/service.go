@@ -3,7 +3,9 @@ package access
 import (
 	"context"
 	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/folder"
 	"easy-storage/internal/domain/share"
+	"io"
 	"log"
 
 	"github.com/google/uuid"
@@ -11,74 +13,507 @@ import (
 
 // Service handles file access control
 type Service struct {
-	fileService  *file.Service
-	shareService *share.Service
+	fileService   *file.Service
+	folderService *folder.Service
+	shareService  *share.Service
 }
 
 // NewService creates a new file access service
-func NewService(fileService *file.Service, shareService *share.Service) *Service {
+func NewService(fileService *file.Service, folderService *folder.Service, shareService *share.Service) *Service {
 	// Check if services are properly initialized
-	if fileService == nil || shareService == nil {
-		log.Printf("Error: fileService or shareService is nil")
+	if fileService == nil || folderService == nil || shareService == nil {
+		log.Printf("Error: fileService, folderService or shareService is nil")
 	}
 	return &Service{
-		fileService:  fileService,
-		shareService: shareService,
+		fileService:   fileService,
+		folderService: folderService,
+		shareService:  shareService,
 	}
 }
 
 // CheckFileAccess checks if a user has access to a file
+// Sharing a folder with a user gives access to every file inside it, at any depth
 func (s *Service) CheckFileAccess(ctx context.Context, fileID string, userID string) (bool, error) {
-	// Parse UUIDs
-	fileUUID, err := uuid.Parse(fileID)
+	permission, err := s.GetFilePermission(ctx, fileID, userID)
 	if err != nil {
 		return false, err
 	}
 
-	userUUID, err := uuid.Parse(userID)
+	return permission != "", nil
+}
+
+// CheckFolderAccess checks if a user has access to a folder
+// Sharing a folder with a user gives access to every folder inside it, at any depth
+func (s *Service) CheckFolderAccess(ctx context.Context, folderID string, userID string) (bool, error) {
+	permission, err := s.GetFolderPermission(ctx, folderID, userID)
 	if err != nil {
 		return false, err
 	}
 
+	return permission != "", nil
+}
+
+// GetFilePermission resolves the role of a user on a file
+func (s *Service) GetFilePermission(ctx context.Context, fileID string, userID string) (share.SharePermission, error) {
 	// Get file
 	file, err := s.fileService.GetFile(fileID)
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
+	return s.FileRole(ctx, file, userID)
+}
+
+// GetFolderPermission resolves the role of a user on a folder
+func (s *Service) GetFolderPermission(ctx context.Context, folderID string, userID string) (share.SharePermission, error) {
+	// Get folder
+	existingFolder, err := s.folderService.GetFolder(folderID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.FolderRole(ctx, existingFolder, userID)
+}
+
+// FileRole resolves the role of a user on a file
+// Owners have the owner role, other users get the highest role shared with them
+// on the file or on any folder containing it. An empty role means no access
+func (s *Service) FileRole(ctx context.Context, file *file.File, userID string) (share.SharePermission, error) {
 	// Check if user is the owner
 	if file.UserID == userID {
-		return true, nil
+		return share.Owner, nil
+	}
+
+	userUUID, ownerUUID, err := parseUserAndOwner(userID, file.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	fileUUID, err := uuid.Parse(file.ID)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if file has been shared with user
-	hasAccess, err := s.shareService.CheckAccessToResource(ctx, userUUID, fileUUID, "file")
+	permission, err := s.shareService.GetUserPermission(ctx, userUUID, ownerUUID, fileUUID, "file")
 	if err != nil {
-		return false, err
+		return "", err
+	}
+
+	// Check the folders containing the file
+	inherited, err := s.inheritedPermission(ctx, userUUID, ownerUUID, file.FolderID)
+	if err != nil {
+		return "", err
+	}
+
+	return share.Highest(permission, inherited), nil
+}
+
+// FolderRole resolves the role of a user on a folder
+// Owners have the owner role, other users get the highest role shared with them
+// on the folder or on any folder containing it. An empty role means no access
+func (s *Service) FolderRole(ctx context.Context, existingFolder *folder.Folder, userID string) (share.SharePermission, error) {
+	// Check if user is the owner
+	if existingFolder.UserID == userID {
+		return share.Owner, nil
+	}
+
+	userUUID, ownerUUID, err := parseUserAndOwner(userID, existingFolder.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.inheritedPermission(ctx, userUUID, ownerUUID, existingFolder.ID)
+}
+
+// Authorize checks that a user can perform an action on a file or folder
+// Resources the user has no role on are reported as not found, so their existence is not revealed
+func (s *Service) Authorize(ctx context.Context, userID, resourceType, resourceID string, capability share.Capability) error {
+	switch resourceType {
+	case "file":
+		existingFile, err := s.fileService.GetFile(resourceID)
+		if err != nil {
+			return err
+		}
+		return s.AuthorizeFile(ctx, userID, existingFile, capability)
+	case "folder":
+		existingFolder, err := s.folderService.GetFolder(resourceID)
+		if err != nil {
+			return err
+		}
+		return s.AuthorizeFolder(ctx, userID, existingFolder, capability)
+	default:
+		return ErrInvalidResourceType
+	}
+}
+
+// AuthorizeFile checks that a user can perform an action on a file
+func (s *Service) AuthorizeFile(ctx context.Context, userID string, existingFile *file.File, capability share.Capability) error {
+	role, err := s.FileRole(ctx, existingFile, userID)
+	if err != nil {
+		return err
+	}
+	return checkCapability(role, capability, file.ErrFileNotFound)
+}
+
+// AuthorizeFolder checks that a user can perform an action on a folder
+func (s *Service) AuthorizeFolder(ctx context.Context, userID string, existingFolder *folder.Folder, capability share.Capability) error {
+	role, err := s.FolderRole(ctx, existingFolder, userID)
+	if err != nil {
+		return err
+	}
+	return checkCapability(role, capability, folder.ErrFolderNotFound)
+}
+
+// AuthorizeUpload checks that a user can add files and folders to a folder and returns the owner of the folder
+// An empty folderID is the root folder of the user, missing folders and folders the user has no role on are invalid
+func (s *Service) AuthorizeUpload(ctx context.Context, userID, folderID string) (string, error) {
+	if folderID == "" {
+		return userID, nil
+	}
+
+	destination, err := s.folderService.GetFolder(folderID)
+	if err == folder.ErrFolderNotFound {
+		return "", file.ErrInvalidFolder
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = s.AuthorizeFolder(ctx, userID, destination, share.CapUpload)
+	if err == folder.ErrFolderNotFound {
+		return "", file.ErrInvalidFolder
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return destination.UserID, nil
+}
+
+// AuthorizeDestination checks that a user can move or copy items of an owner into a folder
+// Items stay with their owner, so the folder must belong to the same owner
+func (s *Service) AuthorizeDestination(ctx context.Context, userID, ownerID, folderID string) error {
+	// Only the owner can place items at the root of their storage
+	if folderID == "" && userID != ownerID {
+		return ErrPermissionDenied
+	}
+
+	destinationOwner, err := s.AuthorizeUpload(ctx, userID, folderID)
+	if err != nil {
+		return err
+	}
+	if folderID != "" && destinationOwner != ownerID {
+		return file.ErrInvalidFolder
+	}
+
+	return nil
+}
+
+// checkCapability checks that a role allows a capability
+// Users without any role get the not found error of the resource
+func checkCapability(role share.SharePermission, capability share.Capability, notFound error) error {
+	if role == "" {
+		return notFound
+	}
+	if !role.Has(capability) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// parseUserAndOwner parses the IDs of a user and of the owner of a resource
+func parseUserAndOwner(userID, ownerID string) (uuid.UUID, uuid.UUID, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
 	}
 
-	return hasAccess, nil
+	ownerUUID, err := uuid.Parse(ownerID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return userUUID, ownerUUID, nil
+}
+
+// inheritedPermission returns the highest role shared with a user on a folder or its ancestors
+// Only shares created by the owner of the folders count
+func (s *Service) inheritedPermission(ctx context.Context, userID, ownerID uuid.UUID, folderID string) (share.SharePermission, error) {
+	// Root-level files are not inside any shareable folder
+	if folderID == "" {
+		return "", nil
+	}
+
+	ancestors, err := s.folderService.Ancestors(folderID)
+	if err != nil {
+		return "", err
+	}
+
+	var permission share.SharePermission
+	for _, ancestor := range ancestors {
+		ancestorUUID, err := uuid.Parse(ancestor.ID)
+		if err != nil {
+			return "", err
+		}
+
+		shared, err := s.shareService.GetUserPermission(ctx, userID, ownerID, ancestorUUID, "folder")
+		if err != nil {
+			return "", err
+		}
+
+		permission = share.Highest(permission, shared)
+		if permission == share.CoOwner {
+			break
+		}
+	}
+
+	return permission, nil
 }
 
-// GetFileByShareToken gets a file using a share token
-func (s *Service) GetFileByShareToken(ctx context.Context, token string, password string) (*file.File, error) {
+// GetFileByShareToken gets a file using a share token, with the share it was reached through
+// Each call counts as a download against the share's download limit
+func (s *Service) GetFileByShareToken(ctx context.Context, token string, password string, visitor share.Visitor) (*file.File, *share.Share, error) {
 	// Get share by token
-	shareObj, err := s.shareService.GetResourceByToken(ctx, token, password)
+	shareObj, err := s.shareService.GetResourceByToken(ctx, token, password, visitor, share.ActionDownload)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check if resource is a file
 	if shareObj.ResourceType != "file" {
-		return nil, ErrInvalidResourceType
+		return nil, nil, ErrInvalidResourceType
 	}
 
 	// Get file
 	fileID := shareObj.ResourceID.String()
 	file, err := s.fileService.GetFile(fileID)
 	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.shareService.ConsumeDownload(ctx, shareObj, visitor); err != nil {
+		return nil, nil, err
+	}
+
+	return file, shareObj, nil
+}
+
+// GetFolderByShareToken gets a folder using a share token
+func (s *Service) GetFolderByShareToken(ctx context.Context, token string, password string, visitor share.Visitor) (*folder.Folder, error) {
+	_, sharedFolder, err := s.getSharedFolder(ctx, token, password, visitor, share.ActionView)
+	return sharedFolder, err
+}
+
+// DownloadFolderByShareToken gets a folder shared by link to download it as a whole
+// Each call counts as a download against the share's download limit
+func (s *Service) DownloadFolderByShareToken(ctx context.Context, token, password string, visitor share.Visitor) (*folder.Folder, error) {
+	shareObj, sharedFolder, err := s.getSharedFolder(ctx, token, password, visitor, share.ActionDownload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.shareService.ConsumeDownload(ctx, shareObj, visitor); err != nil {
+		return nil, err
+	}
+
+	return sharedFolder, nil
+}
+
+// getSharedFolder gets a folder share and its folder using a share token
+func (s *Service) getSharedFolder(ctx context.Context, token, password string, visitor share.Visitor, action share.AccessAction) (*share.Share, *folder.Folder, error) {
+	// Get share by token
+	shareObj, err := s.shareService.GetResourceByToken(ctx, token, password, visitor, action)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check if resource is a folder
+	if shareObj.ResourceType != "folder" {
+		return nil, nil, ErrInvalidResourceType
+	}
+
+	// Upload-only shares never reveal the folder contents
+	if shareObj.UploadOnly {
+		return nil, nil, share.ErrUploadOnly
+	}
+
+	// Get folder
+	folderID := shareObj.ResourceID.String()
+	sharedFolder, err := s.folderService.GetFolder(folderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return shareObj, sharedFolder, nil
+}
+
+// GetSharedFolderContents lists a folder inside a folder shared by link
+// An empty folderID lists the shared folder itself
+func (s *Service) GetSharedFolderContents(ctx context.Context, token, password string, visitor share.Visitor, folderID string) (*folder.Folder, []folder.Folder, []*file.File, error) {
+	sharedFolder, err := s.GetFolderByShareToken(ctx, token, password, visitor)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	listedFolder := sharedFolder
+	if folderID != "" && folderID != sharedFolder.ID {
+		within, err := s.isInSharedFolder(sharedFolder, folderID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !within {
+			return nil, nil, nil, ErrResourceNotInShare
+		}
+
+		listedFolder, err = s.folderService.GetFolder(folderID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	folders, files, err := s.folderService.GetFolderContents(listedFolder.ID, sharedFolder.UserID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return listedFolder, folders, files, nil
+}
+
+// GetSharedFolderFile gets a file inside a folder shared by link
+// Each call counts as a download against the share's download limit
+func (s *Service) GetSharedFolderFile(ctx context.Context, token, password string, visitor share.Visitor, fileID string) (*file.File, *share.Share, error) {
+	shareObj, sharedFolder, err := s.getSharedFolder(ctx, token, password, visitor, share.ActionDownload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sharedFile, err := s.fileService.GetFile(fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if sharedFile.UserID != sharedFolder.UserID {
+		return nil, nil, ErrResourceNotInShare
+	}
+
+	within, err := s.isInSharedFolder(sharedFolder, sharedFile.FolderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !within {
+		return nil, nil, ErrResourceNotInShare
+	}
+
+	if err := s.shareService.ConsumeDownload(ctx, shareObj, visitor); err != nil {
+		return nil, nil, err
+	}
+
+	return sharedFile, shareObj, nil
+}
+
+// isInSharedFolder checks if a folder is the shared folder or one of its descendants
+func (s *Service) isInSharedFolder(sharedFolder *folder.Folder, folderID string) (bool, error) {
+	// Root-level files and folders are never part of a folder share
+	if folderID == "" {
+		return false, nil
+	}
+
+	within, err := s.folderService.IsWithin(folderID, sharedFolder.ID)
+	if err == folder.ErrFolderNotFound {
+		return false, nil
+	}
+	return within, err
+}
+
+// UploadToUserShare uploads a file into a folder shared with the user, directly or through a group, with a role that can upload
+// An empty folderID uploads into the shared folder itself
+func (s *Service) UploadToUserShare(ctx context.Context, shareID uuid.UUID, userID, folderID, filename string, size int64, contentType string, content io.Reader) (*file.File, error) {
+	shareObj, err := s.shareService.GetShareByID(ctx, shareID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the recipients of a user or group share can upload through it
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	isRecipient, err := s.shareService.IsRecipient(shareObj, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !isRecipient {
+		return nil, share.ErrShareNotFound
+	}
+
+	if shareObj.IsRevoked {
+		return nil, share.ErrShareRevoked
+	}
+	if shareObj.IsExpired() {
+		return nil, share.ErrShareExpired
+	}
+
+	return s.uploadToShare(ctx, shareObj, userID, folderID, filename, size, contentType, content)
+}
+
+// UploadToLinkShare uploads a file into a folder shared by a link with a role that can upload
+// An empty folderID uploads into the shared folder itself
+func (s *Service) UploadToLinkShare(ctx context.Context, token, password string, visitor share.Visitor, folderID, filename string, size int64, contentType string, content io.Reader) (*file.File, error) {
+	shareObj, err := s.shareService.GetResourceByToken(ctx, token, password, visitor, share.ActionUpload)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.uploadToShare(ctx, shareObj, "", folderID, filename, size, contentType, content)
+}
+
+// uploadToShare stores a file uploaded through a share in the owner's folder
+// The file is charged to the owner's quota and counted against the share's limits
+func (s *Service) uploadToShare(ctx context.Context, shareObj *share.Share, uploaderID, folderID, filename string, size int64, contentType string, content io.Reader) (*file.File, error) {
+	if shareObj.ResourceType != "folder" {
+		return nil, ErrInvalidResourceType
+	}
+	if !shareObj.AllowsUpload() {
+		return nil, share.ErrReadOnlyShare
+	}
+	if shareObj.MaxFileSize > 0 && size > shareObj.MaxFileSize {
+		return nil, share.ErrUploadTooLarge
+	}
+
+	sharedFolder, err := s.folderService.GetFolder(shareObj.ResourceID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// Only folders shared by their owner accept uploads charged to that owner
+	if sharedFolder.UserID != shareObj.OwnerID.String() {
+		return nil, share.ErrUnauthorizedAccess
+	}
+
+	targetFolderID := sharedFolder.ID
+	if folderID != "" && folderID != sharedFolder.ID {
+		within, err := s.isInSharedFolder(sharedFolder, folderID)
+		if err != nil {
+			return nil, err
+		}
+		if !within {
+			return nil, ErrResourceNotInShare
+		}
+		targetFolderID = folderID
+	}
+
+	if err := s.shareService.ReserveUpload(ctx, shareObj.ID); err != nil {
+		return nil, err
+	}
+
+	uploadedFile, err := s.fileService.UploadNewFile(filename, size, contentType, content, sharedFolder.UserID, targetFolderID, uploaderID)
+	if err != nil {
+		if releaseErr := s.shareService.ReleaseUpload(ctx, shareObj.ID); releaseErr != nil {
+			log.Printf("Error releasing upload of share %s: %v", shareObj.ID, releaseErr)
+		}
 		return nil, err
 	}
 
-	return file, nil
+	return uploadedFile, nil
 }
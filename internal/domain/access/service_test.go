@@ -0,0 +1,259 @@
+package access
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/file/filetest"
+	"easy-storage/internal/domain/folder"
+	"easy-storage/internal/domain/folder/foldertest"
+	"easy-storage/internal/domain/share"
+	"easy-storage/internal/domain/share/sharetest"
+	"easy-storage/internal/domain/user/usertest"
+
+	"github.com/google/uuid"
+)
+
+// fixture wires the access service to in-memory repositories and storage
+type fixture struct {
+	access     *Service
+	files      *filetest.Service
+	folders    *folder.Service
+	folderRepo *foldertest.Repository
+	shares     *share.Service
+	owner      string
+	other      string
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	f := &fixture{
+		folderRepo: foldertest.NewRepository(),
+		owner:      uuid.New().String(),
+		other:      uuid.New().String(),
+	}
+
+	users := usertest.NewRepository()
+	users.AddUser(t, f.owner, 1<<20)
+	users.AddUser(t, f.other, 1<<20)
+
+	f.files = filetest.NewService(users, f.folderRepo)
+	f.folders = folder.NewService(f.folderRepo, f.files.Service)
+	f.shares = share.NewService(sharetest.NewRepository(), &sharetest.EventRepository{}, nil, users, nil, share.PasswordLimits{})
+	f.access = NewService(f.files.Service, f.folders, f.shares)
+	return f
+}
+
+func (f *fixture) createFolder(t *testing.T, name, parentID, userID string) *folder.Folder {
+	t.Helper()
+
+	created, err := f.folders.CreateFolder(name, parentID, userID)
+	if err != nil {
+		t.Fatalf("CreateFolder %s: %v", name, err)
+	}
+	return created
+}
+
+func (f *fixture) uploadFile(t *testing.T, name, folderID, userID string) *file.File {
+	t.Helper()
+
+	uploaded, err := f.files.UploadFile(name, 5, "text/plain", strings.NewReader("hello"), userID, folderID)
+	if err != nil {
+		t.Fatalf("UploadFile %s: %v", name, err)
+	}
+	return uploaded
+}
+
+// shareByLink shares a folder of the owner by link and returns the token
+func (f *fixture) shareByLink(t *testing.T, shared *folder.Folder) string {
+	t.Helper()
+
+	created, err := f.shares.CreateLinkShare(context.Background(), uuid.MustParse(f.owner), uuid.MustParse(shared.ID), "folder", share.Viewer)
+	if err != nil {
+		t.Fatalf("CreateLinkShare: %v", err)
+	}
+	return created.Token
+}
+
+// sharedTree creates projects/drafts/old shared by link, with a sibling folder next to projects
+type sharedTree struct {
+	projects, drafts, old, sibling *folder.Folder
+	token                          string
+}
+
+func (f *fixture) sharedTree(t *testing.T) *sharedTree {
+	t.Helper()
+
+	tree := &sharedTree{
+		projects: f.createFolder(t, "projects", "", f.owner),
+		sibling:  f.createFolder(t, "personal", "", f.owner),
+	}
+	tree.drafts = f.createFolder(t, "drafts", tree.projects.ID, f.owner)
+	tree.old = f.createFolder(t, "old", tree.drafts.ID, f.owner)
+	tree.token = f.shareByLink(t, tree.projects)
+	return tree
+}
+
+func TestGetSharedFolderContentsDescendant(t *testing.T) {
+	f := newFixture(t)
+	tree := f.sharedTree(t)
+	f.uploadFile(t, "notes.txt", tree.old.ID, f.owner)
+
+	listed, _, files, err := f.access.GetSharedFolderContents(context.Background(), tree.token, "", share.Visitor{}, tree.old.ID)
+	if err != nil {
+		t.Fatalf("GetSharedFolderContents: %v", err)
+	}
+	if listed.ID != tree.old.ID || len(files) != 1 {
+		t.Errorf("listed folder %s with %d files, want %s with 1 file", listed.ID, len(files), tree.old.ID)
+	}
+}
+
+func TestGetSharedFolderContentsOutsideShare(t *testing.T) {
+	f := newFixture(t)
+	tree := f.sharedTree(t)
+	otherFolder := f.createFolder(t, "theirs", "", f.other)
+
+	tests := []struct {
+		name     string
+		folderID string
+	}{
+		{name: "sibling folder", folderID: tree.sibling.ID},
+		{name: "folder of another owner", folderID: otherFolder.ID},
+		{name: "unknown folder", folderID: uuid.New().String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := f.access.GetSharedFolderContents(context.Background(), tree.token, "", share.Visitor{}, tt.folderID)
+			if err != ErrResourceNotInShare {
+				t.Errorf("GetSharedFolderContents error = %v, want ErrResourceNotInShare", err)
+			}
+		})
+	}
+}
+
+func TestGetSharedFolderContentsTrashedFolder(t *testing.T) {
+	f := newFixture(t)
+	tree := f.sharedTree(t)
+
+	if err := f.folders.DeleteFolder(tree.drafts.ID, f.owner); err != nil {
+		t.Fatalf("DeleteFolder: %v", err)
+	}
+
+	for _, trashed := range []*folder.Folder{tree.drafts, tree.old} {
+		_, _, _, err := f.access.GetSharedFolderContents(context.Background(), tree.token, "", share.Visitor{}, trashed.ID)
+		if err != ErrResourceNotInShare {
+			t.Errorf("listing trashed folder %s error = %v, want ErrResourceNotInShare", trashed.Name, err)
+		}
+	}
+}
+
+func TestGetSharedFolderContentsCycle(t *testing.T) {
+	f := newFixture(t)
+	tree := f.sharedTree(t)
+
+	// A corrupted hierarchy where two folders outside the share contain each other
+	first := f.createFolder(t, "first", "", f.owner)
+	second := f.createFolder(t, "second", first.ID, f.owner)
+	first.ParentID = second.ID
+	if err := f.folderRepo.Save(first); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	_, _, _, err := f.access.GetSharedFolderContents(context.Background(), tree.token, "", share.Visitor{}, first.ID)
+	if err != folder.ErrFolderCycle {
+		t.Errorf("GetSharedFolderContents error = %v, want ErrFolderCycle", err)
+	}
+}
+
+func TestGetSharedFolderFile(t *testing.T) {
+	f := newFixture(t)
+	tree := f.sharedTree(t)
+	deep := f.uploadFile(t, "deep.txt", tree.old.ID, f.owner)
+
+	got, _, err := f.access.GetSharedFolderFile(context.Background(), tree.token, "", share.Visitor{}, deep.ID)
+	if err != nil {
+		t.Fatalf("GetSharedFolderFile: %v", err)
+	}
+	if got.ID != deep.ID {
+		t.Errorf("got file %s, want %s", got.ID, deep.ID)
+	}
+}
+
+func TestGetSharedFolderFileOutsideShare(t *testing.T) {
+	f := newFixture(t)
+	tree := f.sharedTree(t)
+
+	// A file of another owner claiming to sit in the shared folder
+	foreign := &file.File{Name: "foreign.txt", Size: 5, UserID: f.other, FolderID: tree.projects.ID}
+	if err := f.files.Files.Save(foreign); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		fileID string
+	}{
+		{name: "file in a sibling folder", fileID: f.uploadFile(t, "sibling.txt", tree.sibling.ID, f.owner).ID},
+		{name: "root-level file", fileID: f.uploadFile(t, "root.txt", "", f.owner).ID},
+		{name: "file of another owner", fileID: foreign.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := f.access.GetSharedFolderFile(context.Background(), tree.token, "", share.Visitor{}, tt.fileID)
+			if err != ErrResourceNotInShare {
+				t.Errorf("GetSharedFolderFile error = %v, want ErrResourceNotInShare", err)
+			}
+		})
+	}
+}
+
+func TestGetSharedFolderFileTrashed(t *testing.T) {
+	f := newFixture(t)
+	tree := f.sharedTree(t)
+	trashed := f.uploadFile(t, "old.txt", tree.old.ID, f.owner)
+
+	if err := f.folders.DeleteFolder(tree.drafts.ID, f.owner); err != nil {
+		t.Fatalf("DeleteFolder: %v", err)
+	}
+
+	if _, _, err := f.access.GetSharedFolderFile(context.Background(), tree.token, "", share.Visitor{}, trashed.ID); err != file.ErrFileNotFound {
+		t.Errorf("GetSharedFolderFile error = %v, want ErrFileNotFound", err)
+	}
+}
+
+func TestIsInSharedFolder(t *testing.T) {
+	f := newFixture(t)
+	tree := f.sharedTree(t)
+	trashedChild := f.createFolder(t, "archive", tree.projects.ID, f.owner)
+	if err := f.folders.DeleteFolder(trashedChild.ID, f.owner); err != nil {
+		t.Fatalf("DeleteFolder: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		folderID string
+		want     bool
+	}{
+		{name: "shared folder", folderID: tree.projects.ID, want: true},
+		{name: "child", folderID: tree.drafts.ID, want: true},
+		{name: "grandchild", folderID: tree.old.ID, want: true},
+		{name: "sibling folder", folderID: tree.sibling.ID, want: false},
+		{name: "root level", folderID: "", want: false},
+		{name: "trashed child", folderID: trashedChild.ID, want: false},
+		{name: "unknown folder", folderID: uuid.New().String(), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			within, err := f.access.isInSharedFolder(tree.projects, tt.folderID)
+			if err != nil {
+				t.Fatalf("isInSharedFolder: %v", err)
+			}
+			if within != tt.want {
+				t.Errorf("isInSharedFolder = %v, want %v", within, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// sessionCache remembers for a short time whether sessions are active,
+// so authenticating a request does not hit the database every time
+// It keeps its state in memory, sessions revoked through another API instance are
+// only seen once the cached state expires
+type sessionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]sessionCacheEntry
+}
+
+// sessionCacheEntry is the cached state of a session
+type sessionCacheEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// newSessionCache creates a cache keeping session states for ttl, 0 disables the cache
+func newSessionCache(ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		ttl:     ttl,
+		entries: make(map[string]sessionCacheEntry),
+	}
+}
+
+// get returns the cached state of a session, reporting false when it is unknown or stale
+func (c *sessionCache) get(sessionID string) (active bool, ok bool) {
+	if c.ttl <= 0 {
+		return false, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[sessionID]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return false, false
+	}
+	return entry.active, true
+}
+
+// set caches the state of a session
+func (c *sessionCache) set(sessionID string, active bool) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[sessionID] = sessionCacheEntry{
+		active:    active,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+// prune forgets the states that are stale
+func (c *sessionCache) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for sessionID, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, sessionID)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// RefreshToken is a refresh token handed to a client, only its hash is stored
+// Each refresh uses up the token and issues the next one of the same session
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	SessionID string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time // When the token was exchanged for the next one of its session
+	RevokedAt *time.Time // When its session was revoked
+}
+
+// NewRefreshToken creates a refresh token of a session from the token sent to the client
+func NewRefreshToken(userID, sessionID, token string, expiresAt time.Time) *RefreshToken {
+	return &RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: HashToken(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+// HashToken hashes a token for storage
+// Tokens are long random values, a fast hash is enough to keep them unusable if the database leaks
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsExpired checks if the token can no longer be used
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsUsed checks if the token was already exchanged
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// IsRevoked checks if the session of the token was revoked
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
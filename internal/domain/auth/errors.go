@@ -0,0 +1,47 @@
+package auth
+
+import "errors"
+
+var (
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or revoked
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrRefreshTokenReused is returned when a refresh token is used twice, its session is revoked
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+
+	// ErrSessionNotFound is returned when a session does not exist, is not active or belongs to another user
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrInvalidPersonalToken is returned when a personal access token is unknown or expired
+	ErrInvalidPersonalToken = errors.New("invalid personal access token")
+
+	// ErrPersonalTokenNotFound is returned when a personal access token does not exist or belongs to another user
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+
+	// ErrInvalidTokenName is returned when a personal access token is created without a valid name
+	ErrInvalidTokenName = errors.New("invalid token name")
+
+	// ErrInvalidScope is returned when a personal access token is created without scopes or with an unknown one
+	ErrInvalidScope = errors.New("invalid token scope")
+
+	// ErrInvalidExpiration is returned when a personal access token is created with an expiration date in the past
+	ErrInvalidExpiration = errors.New("expiration date must be in the future")
+
+	// ErrMFANotEnabled is returned when a user without a confirmed authenticator is asked for a code
+	ErrMFANotEnabled = errors.New("two-factor authentication is not enabled")
+
+	// ErrMFAAlreadyEnabled is returned when a user with a confirmed authenticator enrolls again
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+	// ErrMFANotEnrolled is returned when a user confirms an authenticator they did not enroll
+	ErrMFANotEnrolled = errors.New("no authenticator to confirm")
+
+	// ErrInvalidMFACode is returned when a TOTP or recovery code is wrong or was already used
+	ErrInvalidMFACode = errors.New("invalid two-factor code")
+
+	// ErrInvalidMFAToken is returned when the token of a pending two-factor sign in is invalid or expired
+	ErrInvalidMFAToken = errors.New("invalid two-factor sign in token")
+
+	// ErrTooManyMFAAttempts is returned when a user is locked out after too many wrong codes
+	ErrTooManyMFAAttempts = errors.New("too many failed two-factor attempts")
+)
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+)
+
+// recoveryCodeCount is how many recovery codes a user gets at once
+const recoveryCodeCount = 10
+
+// recoveryCodeEncoding encodes recovery codes with letters and digits easy to type
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// TOTPEnrollment is the TOTP authenticator of a user
+// It only protects sign ins once confirmed with a first code
+type TOTPEnrollment struct {
+	UserID       string
+	Secret       string // Base32 encoded, shared with the authenticator app
+	LastUsedStep int64  // Time step of the last accepted code, codes can't be used twice
+	ConfirmedAt  *time.Time
+	CreatedAt    time.Time
+}
+
+// NewTOTPEnrollment creates an unconfirmed TOTP enrollment with a new secret
+func NewTOTPEnrollment(userID string) (*TOTPEnrollment, error) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsConfirmed checks if the enrollment protects sign ins
+func (e *TOTPEnrollment) IsConfirmed() bool {
+	return e.ConfirmedAt != nil
+}
+
+// RecoveryCode is a one-time code signing a user in when their authenticator is lost
+// Only its hash is stored, the codes are shown once when they are generated
+type RecoveryCode struct {
+	ID       string
+	UserID   string
+	CodeHash string
+	UsedAt   *time.Time
+}
+
+// newRecoveryCodes generates a set of recovery codes and returns them with the codes to hand to the user
+func newRecoveryCodes(userID string) ([]*RecoveryCode, []string, error) {
+	recoveryCodes := make([]*RecoveryCode, recoveryCodeCount)
+	codes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		random := make([]byte, 6)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, err
+		}
+
+		encoded := recoveryCodeEncoding.EncodeToString(random)
+		codes[i] = encoded[:5] + "-" + encoded[5:]
+		recoveryCodes[i] = &RecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(codes[i]),
+		}
+	}
+
+	return recoveryCodes, codes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring how the user typed the dash and case
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return HashToken(normalized)
+}
+
+// MFAStatus describes the two-factor authentication of a user
+type MFAStatus struct {
+	Enabled           bool
+	RecoveryCodesLeft int64
+}
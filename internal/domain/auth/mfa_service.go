@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/user"
+)
+
+// Failed two-factor codes allowed per user, and how long a user is locked out once they are used up
+const (
+	maxMFAAttempts = 5
+	mfaLockout     = 15 * time.Minute
+)
+
+// MFAService handles the two-factor authentication of users with TOTP authenticators and recovery codes
+type MFAService struct {
+	repo     MFARepository
+	issuer   string // Name authenticator apps show next to the account
+	attempts *common.AttemptLimiter
+}
+
+// NewMFAService creates a new two-factor authentication service
+func NewMFAService(repo MFARepository, issuer string) *MFAService {
+	return &MFAService{
+		repo:     repo,
+		issuer:   issuer,
+		attempts: common.NewAttemptLimiter(maxMFAAttempts, mfaLockout),
+	}
+}
+
+// Status describes the two-factor authentication of a user
+func (s *MFAService) Status(userID string) (*MFAStatus, error) {
+	enabled, err := s.IsEnabled(userID)
+	if err != nil || !enabled {
+		return &MFAStatus{}, err
+	}
+
+	left, err := s.repo.CountRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAStatus{Enabled: true, RecoveryCodesLeft: left}, nil
+}
+
+// IsEnabled checks if signing a user in requires a second factor
+func (s *MFAService) IsEnabled(userID string) (bool, error) {
+	enrollment, err := s.repo.FindTOTP(userID)
+	if err == ErrMFANotEnabled {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return enrollment.IsConfirmed(), nil
+}
+
+// EnrollTOTP starts enrolling a TOTP authenticator and returns its provisioning URI
+// Sign ins are not protected until the enrollment is confirmed with a first code,
+// enrolling again before that replaces the secret
+func (s *MFAService) EnrollTOTP(u *user.User) (*TOTPEnrollment, string, error) {
+	enabled, err := s.IsEnabled(u.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if enabled {
+		return nil, "", ErrMFAAlreadyEnabled
+	}
+
+	enrollment, err := NewTOTPEnrollment(u.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.repo.SaveTOTP(enrollment); err != nil {
+		return nil, "", err
+	}
+
+	return enrollment, provisioningURI(s.issuer, u.Email, enrollment.Secret), nil
+}
+
+// ConfirmTOTP enables two-factor authentication with the first code of the enrolled authenticator
+// and returns the recovery codes of the user, they are only returned here
+func (s *MFAService) ConfirmTOTP(userID, code string) ([]string, error) {
+	if s.attempts.Locked(userID) {
+		return nil, ErrTooManyMFAAttempts
+	}
+
+	enrollment, err := s.repo.FindTOTP(userID)
+	if err == ErrMFANotEnabled {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.IsConfirmed() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := matchTOTP(enrollment.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		s.attempts.Fail(userID)
+		return nil, ErrInvalidMFACode
+	}
+	s.attempts.Reset(userID)
+
+	if err := s.repo.ConfirmTOTP(userID, step, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(userID)
+}
+
+// VerifyCode checks a TOTP or recovery code of a user, each code is accepted once
+// Users are locked out for a while after too many wrong codes
+func (s *MFAService) VerifyCode(userID, code string) error {
+	if s.attempts.Locked(userID) {
+		return ErrTooManyMFAAttempts
+	}
+
+	enrollment, err := s.repo.FindTOTP(userID)
+	if err != nil {
+		return err
+	}
+	if !enrollment.IsConfirmed() {
+		return ErrMFANotEnabled
+	}
+
+	var ok bool
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		ok, err = s.useTOTPCode(enrollment, code)
+	} else {
+		ok, err = s.repo.UseRecoveryCode(userID, hashRecoveryCode(code), time.Now())
+	}
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		s.attempts.Fail(userID)
+		return ErrInvalidMFACode
+	}
+	s.attempts.Reset(userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking one of their codes
+func (s *MFAService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	if err := s.VerifyCode(userID, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(userID)
+}
+
+// DisableTOTP turns two-factor authentication off after checking one of the user's codes
+func (s *MFAService) DisableTOTP(userID, code string) error {
+	if err := s.VerifyCode(userID, code); err != nil {
+		return err
+	}
+	return s.repo.DeleteTOTP(userID)
+}
+
+// PruneAttempts forgets failed two-factor attempts whose lockout is over
+func (s *MFAService) PruneAttempts() error {
+	s.attempts.Prune()
+	return nil
+}
+
+// useTOTPCode checks a TOTP code, refusing codes of a step already used
+func (s *MFAService) useTOTPCode(enrollment *TOTPEnrollment, code string) (bool, error) {
+	step, ok := matchTOTP(enrollment.Secret, code, time.Now())
+	if !ok || step <= enrollment.LastUsedStep {
+		return false, nil
+	}
+	return s.repo.UseTOTPStep(enrollment.UserID, step)
+}
+
+// replaceRecoveryCodes generates new recovery codes for a user, invalidating the previous ones
+func (s *MFAService) replaceRecoveryCodes(userID string) ([]string, error) {
+	recoveryCodes, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(userID, recoveryCodes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
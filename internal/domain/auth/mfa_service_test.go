@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-storage/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+// memoryMFARepository implements MFARepository in memory
+type memoryMFARepository struct {
+	mu          sync.Mutex
+	enrollments map[string]TOTPEnrollment
+	codes       map[string][]RecoveryCode
+}
+
+func newMemoryMFARepository() *memoryMFARepository {
+	return &memoryMFARepository{
+		enrollments: make(map[string]TOTPEnrollment),
+		codes:       make(map[string][]RecoveryCode),
+	}
+}
+
+func (r *memoryMFARepository) SaveTOTP(enrollment *TOTPEnrollment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.enrollments[enrollment.UserID] = *enrollment
+	return nil
+}
+
+func (r *memoryMFARepository) FindTOTP(userID string) (*TOTPEnrollment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists {
+		return nil, ErrMFANotEnabled
+	}
+	return &enrollment, nil
+}
+
+func (r *memoryMFARepository) ConfirmTOTP(userID string, step int64, confirmedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists {
+		return ErrMFANotEnabled
+	}
+	enrollment.LastUsedStep = step
+	enrollment.ConfirmedAt = &confirmedAt
+	r.enrollments[userID] = enrollment
+	return nil
+}
+
+func (r *memoryMFARepository) UseTOTPStep(userID string, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists || step <= enrollment.LastUsedStep {
+		return false, nil
+	}
+	enrollment.LastUsedStep = step
+	r.enrollments[userID] = enrollment
+	return true, nil
+}
+
+func (r *memoryMFARepository) DeleteTOTP(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.enrollments, userID)
+	delete(r.codes, userID)
+	return nil
+}
+
+func (r *memoryMFARepository) ReplaceRecoveryCodes(userID string, codes []*RecoveryCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := make([]RecoveryCode, len(codes))
+	for i, code := range codes {
+		stored[i] = *code
+		stored[i].ID = uuid.New().String()
+	}
+	r.codes[userID] = stored
+	return nil
+}
+
+func (r *memoryMFARepository) UseRecoveryCode(userID, codeHash string, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, code := range r.codes[userID] {
+		if code.CodeHash == codeHash && code.UsedAt == nil {
+			r.codes[userID][i].UsedAt = &usedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryMFARepository) CountRecoveryCodes(userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, code := range r.codes[userID] {
+		if code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// codeAt returns the code of an enrolled authenticator a number of steps from now
+func codeAt(t *testing.T, repo *memoryMFARepository, userID string, offset int64) string {
+	t.Helper()
+
+	enrollment, err := repo.FindTOTP(userID)
+	if err != nil {
+		t.Fatalf("FindTOTP: %v", err)
+	}
+	key, err := totpEncoding.DecodeString(enrollment.Secret)
+	if err != nil {
+		t.Fatalf("decoding secret: %v", err)
+	}
+	return totpCode(key, totpStep(time.Now())+offset)
+}
+
+// enableMFA enrolls and confirms an authenticator for a user and returns their recovery codes
+// The confirmation uses the code of the previous step, leaving the current one unused
+func enableMFA(t *testing.T, service *MFAService, repo *memoryMFARepository, u *user.User) []string {
+	t.Helper()
+
+	if _, _, err := service.EnrollTOTP(u); err != nil {
+		t.Fatalf("EnrollTOTP: %v", err)
+	}
+	codes, err := service.ConfirmTOTP(u.ID, codeAt(t, repo, u.ID, -1))
+	if err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+	return codes
+}
+
+func TestEnrollTOTPRequiresConfirmation(t *testing.T) {
+	repo := newMemoryMFARepository()
+	service := NewMFAService(repo, "Easy Storage")
+	u := &user.User{ID: "user-1", Email: "user@example.com"}
+
+	_, uri, err := service.EnrollTOTP(u)
+	if err != nil {
+		t.Fatalf("EnrollTOTP: %v", err)
+	}
+	if !strings.HasPrefix(uri, "otpauth://totp/") {
+		t.Errorf("provisioning URI = %q", uri)
+	}
+	if enabled, _ := service.IsEnabled(u.ID); enabled {
+		t.Fatal("an unconfirmed authenticator protects sign ins")
+	}
+
+	if _, err := service.ConfirmTOTP(u.ID, "000000"); err != ErrInvalidMFACode {
+		t.Errorf("ConfirmTOTP with a wrong code error = %v, want ErrInvalidMFACode", err)
+	}
+
+	codes, err := service.ConfirmTOTP(u.ID, codeAt(t, repo, u.ID, 0))
+	if err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("got %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	status, _ := service.Status(u.ID)
+	if !status.Enabled || status.RecoveryCodesLeft != recoveryCodeCount {
+		t.Errorf("Status = %+v, want enabled with %d recovery codes", status, recoveryCodeCount)
+	}
+	if _, _, err := service.EnrollTOTP(u); err != ErrMFAAlreadyEnabled {
+		t.Errorf("enrolling again error = %v, want ErrMFAAlreadyEnabled", err)
+	}
+}
+
+func TestVerifyCodeRejectsReplayedTOTP(t *testing.T) {
+	repo := newMemoryMFARepository()
+	service := NewMFAService(repo, "Easy Storage")
+	u := &user.User{ID: "user-1"}
+	enableMFA(t, service, repo, u)
+
+	// The code used to confirm the authenticator can't sign in
+	if err := service.VerifyCode(u.ID, codeAt(t, repo, u.ID, -1)); err != ErrInvalidMFACode {
+		t.Errorf("confirmation code error = %v, want ErrInvalidMFACode", err)
+	}
+
+	code := codeAt(t, repo, u.ID, 0)
+	if err := service.VerifyCode(u.ID, " "+code+" "); err != nil {
+		t.Fatalf("VerifyCode: %v", err)
+	}
+	if err := service.VerifyCode(u.ID, code); err != ErrInvalidMFACode {
+		t.Errorf("replayed code error = %v, want ErrInvalidMFACode", err)
+	}
+
+	// A later step is still accepted, within the allowed skew
+	if err := service.VerifyCode(u.ID, codeAt(t, repo, u.ID, 1)); err != nil {
+		t.Errorf("code of the next step: %v", err)
+	}
+}
+
+func TestVerifyCodeRecoveryCodes(t *testing.T) {
+	repo := newMemoryMFARepository()
+	service := NewMFAService(repo, "Easy Storage")
+	u := &user.User{ID: "user-1"}
+	codes := enableMFA(t, service, repo, u)
+
+	// Recovery codes are typed without caring for case or the dash
+	typed := strings.ToUpper(strings.Replace(codes[0], "-", "", 1))
+	if err := service.VerifyCode(u.ID, typed); err != nil {
+		t.Fatalf("VerifyCode with a recovery code: %v", err)
+	}
+	if err := service.VerifyCode(u.ID, codes[0]); err != ErrInvalidMFACode {
+		t.Errorf("reused recovery code error = %v, want ErrInvalidMFACode", err)
+	}
+
+	status, _ := service.Status(u.ID)
+	if status.RecoveryCodesLeft != recoveryCodeCount-1 {
+		t.Errorf("%d recovery codes left, want %d", status.RecoveryCodesLeft, recoveryCodeCount-1)
+	}
+
+	// Regenerating the codes invalidates the previous ones
+	newCodes, err := service.RegenerateRecoveryCodes(u.ID, codes[1])
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes: %v", err)
+	}
+	if err := service.VerifyCode(u.ID, codes[2]); err != ErrInvalidMFACode {
+		t.Errorf("old recovery code error = %v, want ErrInvalidMFACode", err)
+	}
+	if err := service.VerifyCode(u.ID, newCodes[0]); err != nil {
+		t.Errorf("new recovery code: %v", err)
+	}
+}
+
+func TestVerifyCodeLocksOutAfterFailedAttempts(t *testing.T) {
+	repo := newMemoryMFARepository()
+	service := NewMFAService(repo, "Easy Storage")
+	u := &user.User{ID: "user-1"}
+	codes := enableMFA(t, service, repo, u)
+
+	for i := 0; i < maxMFAAttempts; i++ {
+		if err := service.VerifyCode(u.ID, "aaaaa-aaaaa"); err != ErrInvalidMFACode {
+			t.Fatalf("attempt %d error = %v, want ErrInvalidMFACode", i+1, err)
+		}
+	}
+
+	if err := service.VerifyCode(u.ID, codes[0]); err != ErrTooManyMFAAttempts {
+		t.Errorf("error after %d failures = %v, want ErrTooManyMFAAttempts", maxMFAAttempts, err)
+	}
+	if left, _ := repo.CountRecoveryCodes(u.ID); left != recoveryCodeCount {
+		t.Error("a recovery code was used up while locked out")
+	}
+}
+
+func TestDisableTOTP(t *testing.T) {
+	repo := newMemoryMFARepository()
+	service := NewMFAService(repo, "Easy Storage")
+	u := &user.User{ID: "user-1"}
+	codes := enableMFA(t, service, repo, u)
+
+	if err := service.DisableTOTP(u.ID, codes[0]); err != nil {
+		t.Fatalf("DisableTOTP: %v", err)
+	}
+	if enabled, _ := service.IsEnabled(u.ID); enabled {
+		t.Error("two-factor authentication is still enabled")
+	}
+	if err := service.VerifyCode(u.ID, codes[1]); err != ErrMFANotEnabled {
+		t.Errorf("VerifyCode error = %v, want ErrMFANotEnabled", err)
+	}
+}
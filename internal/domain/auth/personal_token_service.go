@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"easy-storage/internal/domain/common"
+)
+
+// lastUsedPrecision is how stale the last use of a personal access token may be,
+// so scripts making many requests do not write it on every one
+const lastUsedPrecision = time.Minute
+
+// PersonalTokenService handles the personal access tokens of users
+type PersonalTokenService struct {
+	repo PersonalTokenRepository
+}
+
+// NewPersonalTokenService creates a new personal access token service
+func NewPersonalTokenService(repo PersonalTokenRepository) *PersonalTokenService {
+	return &PersonalTokenService{
+		repo: repo,
+	}
+}
+
+// CreateToken creates a personal access token for a user
+// The token is only returned here, the user has to copy it
+func (s *PersonalTokenService) CreateToken(userID, name string, scopes []string, expiresAt *time.Time) (*PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if !common.IsValidName(name) {
+		return nil, "", ErrInvalidTokenName
+	}
+
+	parsedScopes, err := ParseScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidExpiration
+	}
+
+	personalToken, token, err := NewPersonalAccessToken(userID, name, parsedScopes, expiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.repo.Save(personalToken); err != nil {
+		return nil, "", err
+	}
+
+	return personalToken, token, nil
+}
+
+// ListTokens lists the personal access tokens of a user, expired ones included
+func (s *PersonalTokenService) ListTokens(userID string) ([]*PersonalAccessToken, error) {
+	return s.repo.FindByUser(userID)
+}
+
+// RevokeToken deletes a personal access token of a user
+func (s *PersonalTokenService) RevokeToken(userID, tokenID string) error {
+	personalToken, err := s.repo.FindByID(tokenID)
+	if err != nil {
+		return err
+	}
+	if personalToken.UserID != userID {
+		return ErrPersonalTokenNotFound
+	}
+
+	return s.repo.Delete(personalToken.ID)
+}
+
+// Authenticate finds the personal access token a request was made with and records its use
+func (s *PersonalTokenService) Authenticate(token string) (*PersonalAccessToken, error) {
+	personalToken, err := s.repo.FindByHash(HashToken(token))
+	if err != nil {
+		if err == ErrPersonalTokenNotFound {
+			return nil, ErrInvalidPersonalToken
+		}
+		return nil, err
+	}
+
+	if personalToken.IsExpired() {
+		return nil, ErrInvalidPersonalToken
+	}
+
+	now := time.Now()
+	if personalToken.LastUsedAt == nil || now.Sub(*personalToken.LastUsedAt) > lastUsedPrecision {
+		if err := s.repo.UpdateLastUsed(personalToken.ID, now); err != nil {
+			return nil, err
+		}
+		personalToken.LastUsedAt = &now
+	}
+
+	return personalToken, nil
+}
@@ -0,0 +1,78 @@
+package auth
+
+import "time"
+
+// Repository defines the interface for refresh token data access
+type Repository interface {
+	Save(token *RefreshToken) error
+	// FindByHash finds a token by its hash, it fails with ErrInvalidRefreshToken when it does not exist
+	FindByHash(tokenHash string) (*RefreshToken, error)
+
+	// MarkUsed records the use of a token that is neither used nor revoked
+	// It reports false when the token was used or revoked meanwhile
+	MarkUsed(id string, usedAt time.Time) (bool, error)
+
+	// RevokeSession revokes every token of a session
+	RevokeSession(sessionID string) error
+	// RevokeUser revokes every token of a user
+	RevokeUser(userID string) error
+
+	// DeleteExpired deletes the tokens that expired before a date
+	DeleteExpired(before time.Time) error
+}
+
+// SessionRepository defines the interface for session data access
+type SessionRepository interface {
+	Save(session *Session) error
+	// FindByID finds a session, it fails with ErrSessionNotFound when it does not exist
+	FindByID(id string) (*Session, error)
+	// FindActiveByUser lists the sessions of a user that are neither revoked nor expired, most recently used first
+	FindActiveByUser(userID string) ([]*Session, error)
+
+	// Touch records that tokens were issued for a session from a client
+	Touch(id string, client Client, usedAt, expiresAt time.Time) error
+
+	// Revoke revokes a session
+	Revoke(id string) error
+	// RevokeUser revokes every session of a user
+	RevokeUser(userID string) error
+
+	// DeleteExpired deletes the sessions that expired before a date
+	DeleteExpired(before time.Time) error
+}
+
+// PersonalTokenRepository defines the interface for personal access token data access
+type PersonalTokenRepository interface {
+	Save(token *PersonalAccessToken) error
+	// FindByID finds a token, it fails with ErrPersonalTokenNotFound when it does not exist
+	FindByID(id string) (*PersonalAccessToken, error)
+	// FindByHash finds a token by its hash, it fails with ErrPersonalTokenNotFound when it does not exist
+	FindByHash(tokenHash string) (*PersonalAccessToken, error)
+	// FindByUser lists the tokens of a user, newest first
+	FindByUser(userID string) ([]*PersonalAccessToken, error)
+
+	UpdateLastUsed(id string, usedAt time.Time) error
+	Delete(id string) error
+}
+
+// MFARepository defines the interface for two-factor authentication data access
+type MFARepository interface {
+	// SaveTOTP stores the TOTP enrollment of a user, replacing their previous one
+	SaveTOTP(enrollment *TOTPEnrollment) error
+	// FindTOTP finds the TOTP enrollment of a user, it fails with ErrMFANotEnabled when there is none
+	FindTOTP(userID string) (*TOTPEnrollment, error)
+	// ConfirmTOTP confirms the TOTP enrollment of a user with the step of its first code
+	ConfirmTOTP(userID string, step int64, confirmedAt time.Time) error
+	// UseTOTPStep records the step of an accepted code, it reports false when a code of that step
+	// or a later one was already used
+	UseTOTPStep(userID string, step int64) (bool, error)
+	// DeleteTOTP deletes the TOTP enrollment and the recovery codes of a user
+	DeleteTOTP(userID string) error
+
+	// ReplaceRecoveryCodes replaces every recovery code of a user
+	ReplaceRecoveryCodes(userID string, codes []*RecoveryCode) error
+	// UseRecoveryCode marks an unused recovery code as used, it reports false when there is none
+	UseRecoveryCode(userID, codeHash string, usedAt time.Time) (bool, error)
+	// CountRecoveryCodes counts the unused recovery codes of a user
+	CountRecoveryCodes(userID string) (int64, error)
+}
@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"log"
+	"time"
+
+	"easy-storage/internal/domain/user"
+)
+
+// TokenIssuer signs the tokens handed to clients
+type TokenIssuer interface {
+	// GenerateToken returns a new access token bound to a session
+	GenerateToken(user *user.User, sessionID string) (string, error)
+	// GenerateRefreshToken returns a new refresh token with its expiration date
+	GenerateRefreshToken(user *user.User) (string, time.Time, error)
+	// GenerateMFAToken returns a short-lived token of a sign in waiting for a second factor
+	GenerateMFAToken(user *user.User) (string, error)
+	// ParseMFAToken returns the user ID of a token of a sign in waiting for a second factor
+	ParseMFAToken(token string) (string, error)
+}
+
+// Tokens are the access and refresh tokens handed to a client
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Service handles the sessions and tokens of signed in users
+type Service struct {
+	repo        Repository
+	sessions    SessionRepository
+	userService *user.Service
+	issuer      TokenIssuer
+	mfa         *MFAService
+	cache       *sessionCache
+}
+
+// NewService creates a new auth service
+// The state of sessions is cached for sessionCacheTTL when authenticating requests, 0 disables the cache
+func NewService(
+	repo Repository,
+	sessions SessionRepository,
+	userService *user.Service,
+	issuer TokenIssuer,
+	mfa *MFAService,
+	sessionCacheTTL time.Duration,
+) *Service {
+	return &Service{
+		repo:        repo,
+		sessions:    sessions,
+		userService: userService,
+		issuer:      issuer,
+		mfa:         mfa,
+		cache:       newSessionCache(sessionCacheTTL),
+	}
+}
+
+// StartSignIn signs in a user whose password was just checked
+// Users with two-factor authentication get a short-lived MFA token instead of tokens,
+// to exchange with CompleteMFASignIn
+func (s *Service) StartSignIn(u *user.User, client Client) (*Tokens, string, error) {
+	enabled, err := s.mfa.IsEnabled(u.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if enabled {
+		mfaToken, err := s.issuer.GenerateMFAToken(u)
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, mfaToken, nil
+	}
+
+	tokens, err := s.SignIn(u, client)
+	return tokens, "", err
+}
+
+// CompleteMFASignIn signs in the user of an MFA token with a TOTP or recovery code
+func (s *Service) CompleteMFASignIn(mfaToken, code string, client Client) (*Tokens, *user.User, error) {
+	userID, err := s.issuer.ParseMFAToken(mfaToken)
+	if err != nil {
+		return nil, nil, ErrInvalidMFAToken
+	}
+
+	if err := s.mfa.VerifyCode(userID, code); err != nil {
+		return nil, nil, err
+	}
+
+	u, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		if err == user.ErrUserNotFound {
+			return nil, nil, ErrInvalidMFAToken
+		}
+		return nil, nil, err
+	}
+
+	tokens, err := s.SignIn(u, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, u, nil
+}
+
+// SignIn starts a new session for a user who passed every factor they need and issues its tokens
+func (s *Service) SignIn(u *user.User, client Client) (*Tokens, error) {
+	session := NewSession(u.ID, client)
+
+	tokens, refreshToken, err := s.issue(u, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.ExpiresAt = refreshToken.ExpiresAt
+	if err := s.sessions.Save(session); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(refreshToken); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Refresh exchanges a refresh token for new tokens of the same session
+// Using a token twice revokes its whole session, as one of the copies was stolen
+func (s *Service) Refresh(token string, client Client) (*Tokens, *user.User, error) {
+	stored, err := s.repo.FindByHash(HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if stored.IsRevoked() || stored.IsExpired() {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if stored.IsUsed() {
+		return nil, nil, s.revokeReused(stored)
+	}
+
+	session, err := s.sessions.FindByID(stored.SessionID)
+	if err != nil {
+		if err == ErrSessionNotFound {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+	if !session.IsActive() {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	// Two refreshes racing with the same token count as a reuse too
+	used, err := s.repo.MarkUsed(stored.ID, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !used {
+		return nil, nil, s.revokeReused(stored)
+	}
+
+	u, err := s.userService.GetUserByID(stored.UserID)
+	if err != nil {
+		if err == user.ErrUserNotFound {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+
+	tokens, refreshToken, err := s.issue(u, session.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.sessions.Touch(session.ID, client, time.Now(), refreshToken.ExpiresAt); err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.Save(refreshToken); err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, u, nil
+}
+
+// Logout revokes the session of a refresh token, signing its client out
+// Unknown tokens are ignored, so logging out twice succeeds
+func (s *Service) Logout(token string) error {
+	stored, err := s.repo.FindByHash(HashToken(token))
+	if err == ErrInvalidRefreshToken {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.revokeSession(stored.SessionID)
+}
+
+// LogoutAll revokes every session of a user, signing all their clients out
+func (s *Service) LogoutAll(userID string) error {
+	sessions, err := s.sessions.FindActiveByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessions.RevokeUser(userID); err != nil {
+		return err
+	}
+	if err := s.repo.RevokeUser(userID); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		s.cache.set(session.ID, false)
+	}
+	return nil
+}
+
+// ListSessions lists the active sessions of a user
+func (s *Service) ListSessions(userID string) ([]*Session, error) {
+	return s.sessions.FindActiveByUser(userID)
+}
+
+// RevokeSession signs out one of the sessions of a user
+func (s *Service) RevokeSession(userID, sessionID string) error {
+	session, err := s.sessions.FindByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || !session.IsActive() {
+		return ErrSessionNotFound
+	}
+
+	return s.revokeSession(session.ID)
+}
+
+// IsSessionActive checks if the session of an access token can still be used
+// The answer is cached for a short time, revocations made on this instance are seen at once
+func (s *Service) IsSessionActive(sessionID string) (bool, error) {
+	if active, ok := s.cache.get(sessionID); ok {
+		return active, nil
+	}
+
+	session, err := s.sessions.FindByID(sessionID)
+	if err != nil && err != ErrSessionNotFound {
+		return false, err
+	}
+
+	active := err == nil && session.IsActive()
+	s.cache.set(sessionID, active)
+	return active, nil
+}
+
+// PurgeExpired deletes the sessions and refresh tokens that have expired
+func (s *Service) PurgeExpired() error {
+	now := time.Now()
+	if err := s.repo.DeleteExpired(now); err != nil {
+		return err
+	}
+	return s.sessions.DeleteExpired(now)
+}
+
+// PruneSessionCache forgets the cached session states that are stale
+func (s *Service) PruneSessionCache() error {
+	s.cache.prune()
+	return nil
+}
+
+// issue signs new tokens of a session for a user, the caller stores the refresh token
+func (s *Service) issue(u *user.User, sessionID string) (*Tokens, *RefreshToken, error) {
+	accessToken, err := s.issuer.GenerateToken(u, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, expiresAt, err := s.issuer.GenerateRefreshToken(u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens := &Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+	return tokens, NewRefreshToken(u.ID, sessionID, refreshToken, expiresAt), nil
+}
+
+// revokeSession revokes a session and its refresh tokens
+func (s *Service) revokeSession(sessionID string) error {
+	if err := s.sessions.Revoke(sessionID); err != nil {
+		return err
+	}
+	if err := s.repo.RevokeSession(sessionID); err != nil {
+		return err
+	}
+
+	s.cache.set(sessionID, false)
+	return nil
+}
+
+// revokeReused revokes the session of a token that was used twice
+func (s *Service) revokeReused(stored *RefreshToken) error {
+	log.Printf("Refresh token reuse detected for user %s, revoking session %s", stored.UserID, stored.SessionID)
+	if err := s.revokeSession(stored.SessionID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
@@ -0,0 +1,485 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/domain/user/usertest"
+
+	"github.com/google/uuid"
+)
+
+// memoryTokenRepository implements Repository in memory
+type memoryTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]RefreshToken
+}
+
+func newMemoryTokenRepository() *memoryTokenRepository {
+	return &memoryTokenRepository{tokens: make(map[string]RefreshToken)}
+}
+
+func (r *memoryTokenRepository) Save(token *RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryTokenRepository) FindByHash(tokenHash string) (*RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, ErrInvalidRefreshToken
+}
+
+func (r *memoryTokenRepository) MarkUsed(id string, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.tokens[id]
+	if !exists || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &usedAt
+	r.tokens[id] = token
+	return true, nil
+}
+
+func (r *memoryTokenRepository) RevokeSession(sessionID string) error {
+	return r.revoke(func(token RefreshToken) bool { return token.SessionID == sessionID })
+}
+
+func (r *memoryTokenRepository) RevokeUser(userID string) error {
+	return r.revoke(func(token RefreshToken) bool { return token.UserID == userID })
+}
+
+func (r *memoryTokenRepository) DeleteExpired(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+func (r *memoryTokenRepository) revoke(match func(token RefreshToken) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+// memorySessionRepository implements SessionRepository in memory
+type memorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+func newMemorySessionRepository() *memorySessionRepository {
+	return &memorySessionRepository{sessions: make(map[string]Session)}
+}
+
+func (r *memorySessionRepository) Save(session *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *memorySessionRepository) FindByID(id string) (*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[id]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *memorySessionRepository) FindActiveByUser(userID string) ([]*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sessions []*Session
+	for _, stored := range r.sessions {
+		if stored.UserID == userID && stored.IsActive() {
+			session := stored
+			sessions = append(sessions, &session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+func (r *memorySessionRepository) Touch(id string, client Client, usedAt, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[id]
+	if !exists {
+		return ErrSessionNotFound
+	}
+	session.IP = client.IP
+	session.UserAgent = client.UserAgent
+	session.LastUsedAt = usedAt
+	session.ExpiresAt = expiresAt
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) Revoke(id string) error {
+	return r.revoke(func(session Session) bool { return session.ID == id })
+}
+
+func (r *memorySessionRepository) RevokeUser(userID string) error {
+	return r.revoke(func(session Session) bool { return session.UserID == userID })
+}
+
+func (r *memorySessionRepository) DeleteExpired(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (r *memorySessionRepository) revoke(match func(session Session) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range r.sessions {
+		if match(session) && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			r.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+// fakeIssuer issues numbered tokens instead of signed ones
+type fakeIssuer struct {
+	mu    sync.Mutex
+	count int
+}
+
+var errInvalidToken = errors.New("invalid token")
+
+func (i *fakeIssuer) next(kind string) string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.count++
+	return fmt.Sprintf("%s-%d", kind, i.count)
+}
+
+func (i *fakeIssuer) GenerateToken(u *user.User, sessionID string) (string, error) {
+	return i.next("access"), nil
+}
+
+func (i *fakeIssuer) GenerateRefreshToken(u *user.User) (string, time.Time, error) {
+	return i.next("refresh"), time.Now().Add(time.Hour), nil
+}
+
+func (i *fakeIssuer) GenerateMFAToken(u *user.User) (string, error) {
+	return "mfa:" + u.ID, nil
+}
+
+func (i *fakeIssuer) ParseMFAToken(token string) (string, error) {
+	userID, ok := strings.CutPrefix(token, "mfa:")
+	if !ok {
+		return "", errInvalidToken
+	}
+	return userID, nil
+}
+
+// authFixture wires an auth service to in-memory repositories
+type authFixture struct {
+	service  *Service
+	tokens   *memoryTokenRepository
+	sessions *memorySessionRepository
+	mfa      *MFAService
+	mfaRepo  *memoryMFARepository
+	user     *user.User
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+
+	users := usertest.NewRepository()
+	u := &user.User{ID: "user-1", Email: "user@example.com"}
+	if err := users.Save(u); err != nil {
+		t.Fatalf("saving user: %v", err)
+	}
+
+	f := &authFixture{
+		tokens:   newMemoryTokenRepository(),
+		sessions: newMemorySessionRepository(),
+		mfaRepo:  newMemoryMFARepository(),
+		user:     u,
+	}
+	f.mfa = NewMFAService(f.mfaRepo, "Easy Storage")
+	f.service = NewService(f.tokens, f.sessions, user.NewService(users), &fakeIssuer{}, f.mfa, time.Minute)
+	return f
+}
+
+func (f *authFixture) signIn(t *testing.T) *Tokens {
+	t.Helper()
+
+	tokens, err := f.service.SignIn(f.user, Client{IP: "192.0.2.1", UserAgent: "test"})
+	if err != nil {
+		t.Fatalf("SignIn: %v", err)
+	}
+	return tokens
+}
+
+// sessionOf returns the session a refresh token belongs to
+func (f *authFixture) sessionOf(t *testing.T, refreshToken string) string {
+	t.Helper()
+
+	stored, err := f.tokens.FindByHash(HashToken(refreshToken))
+	if err != nil {
+		t.Fatalf("finding refresh token: %v", err)
+	}
+	return stored.SessionID
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	f := newAuthFixture(t)
+	first := f.signIn(t)
+
+	second, u, err := f.service.Refresh(first.RefreshToken, Client{IP: "192.0.2.2"})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if u.ID != f.user.ID {
+		t.Errorf("Refresh returned user %s, want %s", u.ID, f.user.ID)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Error("Refresh did not issue new tokens")
+	}
+
+	// The new token belongs to the same session, which records the new client IP
+	sessionID := f.sessionOf(t, first.RefreshToken)
+	if f.sessionOf(t, second.RefreshToken) != sessionID {
+		t.Error("the refreshed token belongs to another session")
+	}
+	session, _ := f.sessions.FindByID(sessionID)
+	if session.IP != "192.0.2.2" {
+		t.Errorf("session IP = %q, want the IP of the refresh", session.IP)
+	}
+
+	if _, _, err := f.service.Refresh(second.RefreshToken, Client{}); err != nil {
+		t.Errorf("refreshing with the new token: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	f := newAuthFixture(t)
+	stolen := f.signIn(t)
+	sessionID := f.sessionOf(t, stolen.RefreshToken)
+
+	// The legitimate client refreshes, then the stolen copy is replayed
+	current, _, err := f.service.Refresh(stolen.RefreshToken, Client{})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if active, _ := f.service.IsSessionActive(sessionID); !active {
+		t.Fatal("session is not active after a refresh")
+	}
+
+	if _, _, err := f.service.Refresh(stolen.RefreshToken, Client{}); err != ErrRefreshTokenReused {
+		t.Fatalf("replayed Refresh error = %v, want ErrRefreshTokenReused", err)
+	}
+
+	// Both copies are cut off, access tokens of the session stop working at once
+	if _, _, err := f.service.Refresh(current.RefreshToken, Client{}); err != ErrInvalidRefreshToken {
+		t.Errorf("Refresh with the latest token error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if active, _ := f.service.IsSessionActive(sessionID); active {
+		t.Error("session is still active after a reuse")
+	}
+}
+
+func TestRefreshReuseOnlyRevokesItsSession(t *testing.T) {
+	f := newAuthFixture(t)
+	laptop := f.signIn(t)
+	phone := f.signIn(t)
+
+	if _, _, err := f.service.Refresh(laptop.RefreshToken, Client{}); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if _, _, err := f.service.Refresh(laptop.RefreshToken, Client{}); err != ErrRefreshTokenReused {
+		t.Fatalf("replayed Refresh error = %v, want ErrRefreshTokenReused", err)
+	}
+
+	if _, _, err := f.service.Refresh(phone.RefreshToken, Client{}); err != nil {
+		t.Errorf("the other session was signed out: %v", err)
+	}
+}
+
+func TestRefreshConcurrentUseCountsAsReuse(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens := f.signIn(t)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = f.service.Refresh(tokens.RefreshToken, Client{})
+		}(i)
+	}
+	wg.Wait()
+
+	// Requests arriving after the session was revoked see a revoked token
+	succeeded, reused := 0, 0
+	for _, err := range errs {
+		switch err {
+		case nil:
+			succeeded++
+		case ErrRefreshTokenReused:
+			reused++
+		case ErrInvalidRefreshToken:
+		default:
+			t.Errorf("Refresh error = %v, want success, a reuse or an invalid token", err)
+		}
+	}
+	if succeeded != 1 || reused == 0 {
+		t.Errorf("%d refreshes succeeded and %d were detected as reuses, want 1 success and a reuse", succeeded, reused)
+	}
+}
+
+func TestRefreshInvalidTokens(t *testing.T) {
+	f := newAuthFixture(t)
+
+	if _, _, err := f.service.Refresh("unknown", Client{}); err != ErrInvalidRefreshToken {
+		t.Errorf("unknown token error = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	tokens := f.signIn(t)
+	if err := f.service.Logout(tokens.RefreshToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, _, err := f.service.Refresh(tokens.RefreshToken, Client{}); err != ErrInvalidRefreshToken {
+		t.Errorf("signed out token error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if err := f.service.Logout(tokens.RefreshToken); err != nil {
+		t.Errorf("logging out twice: %v", err)
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	f := newAuthFixture(t)
+	first := f.signIn(t)
+	second := f.signIn(t)
+
+	if sessions, _ := f.service.ListSessions(f.user.ID); len(sessions) != 2 {
+		t.Fatalf("ListSessions returned %d sessions, want 2", len(sessions))
+	}
+
+	if err := f.service.LogoutAll(f.user.ID); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+
+	for _, tokens := range []*Tokens{first, second} {
+		if _, _, err := f.service.Refresh(tokens.RefreshToken, Client{}); err != ErrInvalidRefreshToken {
+			t.Errorf("Refresh error = %v, want ErrInvalidRefreshToken", err)
+		}
+		if active, _ := f.service.IsSessionActive(f.sessionOf(t, tokens.RefreshToken)); active {
+			t.Error("a session is still active after LogoutAll")
+		}
+	}
+}
+
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens := f.signIn(t)
+	sessionID := f.sessionOf(t, tokens.RefreshToken)
+
+	if err := f.service.RevokeSession("someone-else", sessionID); err != ErrSessionNotFound {
+		t.Errorf("RevokeSession error = %v, want ErrSessionNotFound", err)
+	}
+	if err := f.service.RevokeSession(f.user.ID, sessionID); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if err := f.service.RevokeSession(f.user.ID, sessionID); err != ErrSessionNotFound {
+		t.Errorf("revoking twice error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestStartSignInWithoutMFA(t *testing.T) {
+	f := newAuthFixture(t)
+
+	tokens, mfaToken, err := f.service.StartSignIn(f.user, Client{})
+	if err != nil {
+		t.Fatalf("StartSignIn: %v", err)
+	}
+	if tokens == nil || mfaToken != "" {
+		t.Error("a user without two-factor authentication did not get tokens")
+	}
+}
+
+func TestCompleteMFASignIn(t *testing.T) {
+	f := newAuthFixture(t)
+	codes := enableMFA(t, f.mfa, f.mfaRepo, f.user)
+
+	tokens, mfaToken, err := f.service.StartSignIn(f.user, Client{})
+	if err != nil {
+		t.Fatalf("StartSignIn: %v", err)
+	}
+	if tokens != nil || mfaToken == "" {
+		t.Fatal("a user with two-factor authentication got tokens before their second factor")
+	}
+
+	if _, _, err := f.service.CompleteMFASignIn("forged", codes[0], Client{}); err != ErrInvalidMFAToken {
+		t.Errorf("forged MFA token error = %v, want ErrInvalidMFAToken", err)
+	}
+	if _, _, err := f.service.CompleteMFASignIn(mfaToken, "000000", Client{}); err != ErrInvalidMFACode {
+		t.Errorf("wrong code error = %v, want ErrInvalidMFACode", err)
+	}
+
+	tokens, u, err := f.service.CompleteMFASignIn(mfaToken, codeAt(t, f.mfaRepo, f.user.ID, 0), Client{})
+	if err != nil {
+		t.Fatalf("CompleteMFASignIn: %v", err)
+	}
+	if u.ID != f.user.ID || tokens.RefreshToken == "" {
+		t.Error("CompleteMFASignIn did not sign the user in")
+	}
+	if _, _, err := f.service.Refresh(tokens.RefreshToken, Client{}); err != nil {
+		t.Errorf("refreshing the tokens of an MFA sign in: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Client identifies the device a user signs in from
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Session is a sign in of a user on a device
+// It lasts as long as its refresh tokens are exchanged before they expire, or until it is revoked
+type Session struct {
+	ID         string
+	UserID     string
+	UserAgent  string
+	IP         string // IP of the last sign in or refresh
+	CreatedAt  time.Time
+	LastUsedAt time.Time  // When tokens were last issued for the session
+	ExpiresAt  time.Time  // When its latest refresh token expires
+	RevokedAt  *time.Time // When the user signed the session out
+}
+
+// NewSession creates a new session of a user
+func NewSession(userID string, client Client) *Session {
+	now := time.Now()
+	return &Session{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+}
+
+// IsActive checks if the session is neither revoked nor expired
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
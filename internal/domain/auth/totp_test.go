@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors
+const rfc6238Secret = "12345678901234567890"
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes, 6-digit codes are their last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		step := totpStep(time.Unix(tt.unix, 0))
+		if got := totpCode([]byte(rfc6238Secret), step); got != tt.want {
+			t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestMatchTOTPAcceptsClockSkew(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte(rfc6238Secret))
+	now := time.Unix(1111111109, 0)
+	step := totpStep(now)
+
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		code := totpCode([]byte(rfc6238Secret), step+offset)
+		matched, ok := matchTOTP(secret, code, now)
+		if !ok || matched != step+offset {
+			t.Errorf("code of step %+d = step %d, %v, want step %d", offset, matched, ok, step+offset)
+		}
+	}
+
+	for _, offset := range []int64{-totpSkew - 1, totpSkew + 1} {
+		code := totpCode([]byte(rfc6238Secret), step+offset)
+		if _, ok := matchTOTP(secret, code, now); ok {
+			t.Errorf("code of step %+d was accepted outside the allowed skew", offset)
+		}
+	}
+}
+
+func TestMatchTOTPRejectsMalformedInput(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte(rfc6238Secret))
+	now := time.Unix(59, 0)
+
+	for _, code := range []string{"", "28708", "2870822", "abcdef"} {
+		if _, ok := matchTOTP(secret, code, now); ok {
+			t.Errorf("malformed code %q was accepted", code)
+		}
+	}
+	if _, ok := matchTOTP("not base32!", "287082", now); ok {
+		t.Error("a code was accepted with an invalid secret")
+	}
+}
+
+func TestIsTOTPCode(t *testing.T) {
+	tests := map[string]bool{
+		"123456":      true,
+		"12345":       false,
+		"12345a":      false,
+		"abcde-fghij": false,
+	}
+	for code, want := range tests {
+		if got := isTOTPCode(code); got != want {
+			t.Errorf("isTOTPCode(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := provisioningURI("Easy Storage", "user@example.com", "JBSWY3DPEHPK3PXP")
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", uri, err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/Easy Storage:user@example.com" {
+		t.Errorf("URI %q does not name the TOTP account", uri)
+	}
+
+	query := parsed.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Easy Storage" ||
+		query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("URI %q has unexpected parameters", uri)
+	}
+}
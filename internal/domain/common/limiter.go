@@ -0,0 +1,89 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// AttemptLimiter locks keys out after too many failed attempts
+// It keeps its state in memory, so limits apply per API instance
+type AttemptLimiter struct {
+	mu          sync.Mutex
+	maxAttempts int
+	lockout     time.Duration
+	attempts    map[string]*attemptRecord
+}
+
+// attemptRecord tracks the failed attempts of a key
+type attemptRecord struct {
+	failures    int
+	windowEnd   time.Time // Failures older than the window are forgotten
+	lockedUntil time.Time
+}
+
+// NewAttemptLimiter creates a limiter allowing maxAttempts failures per lockout period
+func NewAttemptLimiter(maxAttempts int, lockout time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		maxAttempts: maxAttempts,
+		lockout:     lockout,
+		attempts:    make(map[string]*attemptRecord),
+	}
+}
+
+// Locked reports whether a key is locked out
+func (l *AttemptLimiter) Locked(key string) bool {
+	if l.maxAttempts <= 0 {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record, exists := l.attempts[key]
+	return exists && time.Now().Before(record.lockedUntil)
+}
+
+// Fail records a failed attempt and locks the key out once it reaches the limit
+func (l *AttemptLimiter) Fail(key string) {
+	if l.maxAttempts <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	record, exists := l.attempts[key]
+	if !exists || now.After(record.windowEnd) {
+		record = &attemptRecord{windowEnd: now.Add(l.lockout)}
+		l.attempts[key] = record
+	}
+
+	record.failures++
+	if record.failures >= l.maxAttempts {
+		record.lockedUntil = now.Add(l.lockout)
+		record.windowEnd = record.lockedUntil
+		record.failures = 0
+	}
+}
+
+// Reset forgets the failed attempts of a key
+func (l *AttemptLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, key)
+}
+
+// Prune forgets keys whose window and lockout are over
+func (l *AttemptLimiter) Prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for key, record := range l.attempts {
+		if now.After(record.windowEnd) && now.After(record.lockedUntil) {
+			delete(l.attempts, key)
+		}
+	}
+}
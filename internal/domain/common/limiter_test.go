@@ -0,0 +1,68 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttemptLimiterLocksAfterMaxAttempts(t *testing.T) {
+	l := NewAttemptLimiter(3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		l.Fail("key")
+		if l.Locked("key") {
+			t.Fatalf("locked after %d failures, want 3", i+1)
+		}
+	}
+
+	l.Fail("key")
+	if !l.Locked("key") {
+		t.Fatal("not locked after 3 failures")
+	}
+	if l.Locked("other") {
+		t.Error("failures of one key locked another")
+	}
+}
+
+func TestAttemptLimiterReset(t *testing.T) {
+	l := NewAttemptLimiter(3, time.Minute)
+
+	l.Fail("key")
+	l.Fail("key")
+	l.Reset("key")
+	l.Fail("key")
+
+	if l.Locked("key") {
+		t.Error("failures before Reset still counted")
+	}
+}
+
+func TestAttemptLimiterLockoutEnds(t *testing.T) {
+	l := NewAttemptLimiter(1, 20*time.Millisecond)
+
+	l.Fail("key")
+	if !l.Locked("key") {
+		t.Fatal("not locked after the only allowed failure")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if l.Locked("key") {
+		t.Error("still locked after the lockout period")
+	}
+
+	l.Prune()
+	if len(l.attempts) != 0 {
+		t.Errorf("Prune kept %d finished records", len(l.attempts))
+	}
+}
+
+func TestAttemptLimiterDisabled(t *testing.T) {
+	l := NewAttemptLimiter(0, time.Minute)
+
+	for i := 0; i < 10; i++ {
+		l.Fail("key")
+	}
+	if l.Locked("key") {
+		t.Error("a limiter without a maximum locked a key")
+	}
+}
@@ -0,0 +1,24 @@
+package common
+
+import "io"
+
+// CompletedPart identifies a part that was uploaded as part of a multipart upload
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// MultipartStorage defines the storage operations needed to upload an object in parts
+type MultipartStorage interface {
+	// CreateMultipartUpload starts a multipart upload and returns the object path and upload ID
+	CreateMultipartUpload(filename string, contentType string) (string, string, error)
+
+	// UploadPart uploads a single part of size bytes read from body
+	UploadPart(path string, uploadID string, partNumber int32, body io.Reader, size int64) (CompletedPart, error)
+
+	// CompleteMultipartUpload assembles the uploaded parts into the final object
+	CompleteMultipartUpload(path string, uploadID string, parts []CompletedPart) error
+
+	// AbortMultipartUpload discards a multipart upload and all of its parts
+	AbortMultipartUpload(path string, uploadID string) error
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// maxNameAttempts bounds the search for a free name
+const maxNameAttempts = 1000
+
+// UniqueName returns name, or name with a " (n)" suffix before its extension, for which exists reports false
+// e.g. "report.pdf" becomes "report (1).pdf" when "report.pdf" is taken
+func UniqueName(name string, exists func(candidate string) (bool, error)) (string, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	candidate := name
+	for i := 1; i <= maxNameAttempts; i++ {
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+
+	return "", fmt.Errorf("no free name found for %q", name)
+}
+
+// maxNameLength is the longest file or folder name accepted
+const maxNameLength = 255
+
+// IsValidName checks that a file or folder name is not empty and cannot be mistaken for a path
+func IsValidName(name string) bool {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" || trimmed == "." || trimmed == ".." || len(name) > maxNameLength {
+		return false
+	}
+	return !strings.ContainsAny(name, "/\\\x00")
+}
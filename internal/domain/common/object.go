@@ -0,0 +1,12 @@
+package common
+
+import "errors"
+
+// ErrObjectNotFound is returned when an object does not exist in storage
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes an object stored in storage
+type ObjectInfo struct {
+	Size        int64
+	ContentType string // Empty if the storage backend does not record it
+}
@@ -0,0 +1,89 @@
+package file
+
+import (
+	"log"
+
+	"easy-storage/internal/domain/common"
+)
+
+// CopyFile copies the current content of a file into a folder of its owner
+// The copy is charged to the owner's quota and gets a " (n)" suffix if its name is taken
+// An empty folderID copies the file to the root folder, an empty name keeps the file's name
+func (s *Service) CopyFile(file *File, folderID, name string) (*File, error) {
+	if name == "" {
+		name = file.Name
+	}
+	if !common.IsValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	// Validate destination folder ownership
+	if err := s.ValidateFolder(folderID, file.UserID); err != nil {
+		return nil, err
+	}
+
+	// Check if the owner has enough storage quota
+	if err := s.ReserveStorage(file.UserID, file.Size); err != nil {
+		return nil, err
+	}
+
+	copied, err := s.CopyReservedFile(file, folderID, name)
+	if err != nil {
+		s.ReleaseStorage(file.UserID, file.Size)
+		return nil, err
+	}
+
+	return copied, nil
+}
+
+// CopyReservedFile copies a file whose size was already charged to the owner's quota
+// The folder is not validated, releasing the quota on failure is left to the caller
+func (s *Service) CopyReservedFile(file *File, folderID, name string) (*File, error) {
+	// A copy never becomes a version of another file
+	name, err := common.UniqueName(name, func(candidate string) (bool, error) {
+		return s.nameTaken(file.UserID, folderID, candidate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the content on the storage side
+	path, err := s.storage.Copy(file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := NewFile(name, file.Size, file.ContentType, path, file.UserID, folderID)
+	if err := s.repo.Save(copied); err != nil {
+		_ = s.storage.Delete(path)
+		return nil, err
+	}
+
+	// Record the first version of the copy
+	if err := s.versionRepo.Save(NewFileVersion(copied.ID, copied.Version, copied.Size, copied.ContentType, path, file.UserID)); err != nil {
+		_ = s.repo.Purge(copied.ID)
+		_ = s.storage.Delete(path)
+		return nil, err
+	}
+
+	return copied, nil
+}
+
+// ReserveStorage charges bytes to a user's storage quota ahead of an operation
+func (s *Service) ReserveStorage(userID string, size int64) error {
+	if s.userStorage == nil {
+		return nil
+	}
+	return s.userStorage.AddStorage(userID, size)
+}
+
+// ReleaseStorage gives bytes reserved with ReserveStorage back to a user
+func (s *Service) ReleaseStorage(userID string, size int64) {
+	if s.userStorage == nil || size <= 0 {
+		return
+	}
+
+	if err := s.userStorage.RemoveStorage(userID, size); err != nil {
+		log.Printf("Error releasing reserved storage for user %s: %v", userID, err)
+	}
+}
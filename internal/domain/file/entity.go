@@ -13,8 +13,10 @@ type File struct {
 	Path        string
 	UserID      string
 	FolderID    string
+	Version     int // Number of the current version
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	TrashedAt   time.Time // Set while the file is in the trash
 }
 
 // NewFile creates a new file entity
@@ -27,6 +29,7 @@ func NewFile(name string, size int64, contentType, path, userID, folderID string
 		Path:        path,
 		UserID:      userID,
 		FolderID:    folderID,
+		Version:     1,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -0,0 +1,224 @@
+// Package filetest provides in-memory file repositories for tests
+package filetest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"easy-storage/internal/domain/file"
+
+	"github.com/google/uuid"
+)
+
+// fileRow is a stored file and the folder whose deletion trashed it
+type fileRow struct {
+	file        file.File
+	trashedWith string
+}
+
+// Repository implements file.Repository in memory
+// Files are stored by value, so callers only see changes they save
+type Repository struct {
+	mu      sync.Mutex
+	files   map[string]*fileRow
+	SaveErr error // Returned by Save when set
+}
+
+// NewRepository creates an empty in-memory file repository
+func NewRepository() *Repository {
+	return &Repository{files: make(map[string]*fileRow)}
+}
+
+// Save stores a file, assigning an ID to new files
+func (r *Repository) Save(f *file.File) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.SaveErr != nil {
+		return r.SaveErr
+	}
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+
+	row, exists := r.files[f.ID]
+	if !exists {
+		row = &fileRow{}
+		r.files[f.ID] = row
+	}
+	row.file = *f
+	return nil
+}
+
+// FindByID finds a file that is not in the trash
+func (r *Repository) FindByID(id string) (*file.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, exists := r.files[id]
+	if !exists || !row.file.TrashedAt.IsZero() {
+		return nil, file.ErrFileNotFound
+	}
+	f := row.file
+	return &f, nil
+}
+
+// FindByUserID lists the files of a user outside the trash, sorting is ignored
+func (r *Repository) FindByUserID(userID string, limit, offset int, sortBy, sortDir string) ([]*file.File, error) {
+	files := r.filter(func(row *fileRow) bool {
+		return row.file.TrashedAt.IsZero() && row.file.UserID == userID
+	})
+
+	if offset >= len(files) {
+		return []*file.File{}, nil
+	}
+	files = files[offset:]
+	if limit > 0 && limit < len(files) {
+		files = files[:limit]
+	}
+	return files, nil
+}
+
+// FindByUserIDAndFolder lists the files of a user in a folder outside the trash
+func (r *Repository) FindByUserIDAndFolder(userID string, folderID string) ([]*file.File, error) {
+	return r.filter(func(row *fileRow) bool {
+		return row.file.TrashedAt.IsZero() && row.file.UserID == userID && row.file.FolderID == folderID
+	}), nil
+}
+
+// FindByName finds the file with the given name in a folder outside the trash
+func (r *Repository) FindByName(userID, folderID, name string) (*file.File, error) {
+	files := r.filter(func(row *fileRow) bool {
+		return row.file.TrashedAt.IsZero() && row.file.UserID == userID &&
+			row.file.FolderID == folderID && row.file.Name == name
+	})
+	if len(files) == 0 {
+		return nil, file.ErrFileNotFound
+	}
+	return files[0], nil
+}
+
+// Delete soft deletes a file the way GORM does, without linking it to a folder
+func (r *Repository) Delete(id string) error {
+	return r.Trash(id, "")
+}
+
+// DeleteByFolder soft deletes all files of a folder
+func (r *Repository) DeleteByFolder(folderID string) error {
+	return r.TrashByFolder(folderID, "")
+}
+
+// Trash moves a file to the trash
+func (r *Repository) Trash(id, trashedWith string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, exists := r.files[id]
+	if !exists || !row.file.TrashedAt.IsZero() {
+		return file.ErrFileNotFound
+	}
+	row.file.TrashedAt = time.Now()
+	row.trashedWith = trashedWith
+	return nil
+}
+
+// TrashByFolder moves all files of a folder to the trash
+func (r *Repository) TrashByFolder(folderID, trashedWith string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, row := range r.files {
+		if row.file.TrashedAt.IsZero() && row.file.FolderID == folderID {
+			row.file.TrashedAt = now
+			row.trashedWith = trashedWith
+		}
+	}
+	return nil
+}
+
+// FindTrashed finds the files a user moved to the trash themselves, most recent first
+func (r *Repository) FindTrashed(userID string) ([]*file.File, error) {
+	files := r.filter(func(row *fileRow) bool {
+		return !row.file.TrashedAt.IsZero() && row.trashedWith == "" && row.file.UserID == userID
+	})
+	sort.SliceStable(files, func(i, j int) bool { return files[i].TrashedAt.After(files[j].TrashedAt) })
+	return files, nil
+}
+
+// FindTrashedBefore finds files moved to the trash themselves before the given time
+func (r *Repository) FindTrashedBefore(before time.Time) ([]*file.File, error) {
+	return r.filter(func(row *fileRow) bool {
+		return !row.file.TrashedAt.IsZero() && row.trashedWith == "" && row.file.TrashedAt.Before(before)
+	}), nil
+}
+
+// FindTrashedWith finds the files trashed along with a folder
+func (r *Repository) FindTrashedWith(folderID string) ([]*file.File, error) {
+	return r.filter(func(row *fileRow) bool {
+		return !row.file.TrashedAt.IsZero() && row.trashedWith == folderID
+	}), nil
+}
+
+// FindTrashedByID finds a file in the trash
+func (r *Repository) FindTrashedByID(id string) (*file.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, exists := r.files[id]
+	if !exists || row.file.TrashedAt.IsZero() {
+		return nil, file.ErrFileNotFound
+	}
+	f := row.file
+	return &f, nil
+}
+
+// Restore takes a file out of the trash into the given folder under the given name
+func (r *Repository) Restore(id, folderID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, exists := r.files[id]
+	if !exists || row.file.TrashedAt.IsZero() {
+		return file.ErrFileNotFound
+	}
+	row.file.TrashedAt = time.Time{}
+	row.file.FolderID = folderID
+	row.file.Name = name
+	row.trashedWith = ""
+	return nil
+}
+
+// Purge permanently deletes a file
+func (r *Repository) Purge(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.files, id)
+	return nil
+}
+
+// Len returns the number of stored files, including those in the trash
+func (r *Repository) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.files)
+}
+
+// filter returns copies of the files matching a predicate, oldest first
+func (r *Repository) filter(match func(row *fileRow) bool) []*file.File {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	files := []*file.File{}
+	for _, row := range r.files {
+		if match(row) {
+			f := row.file
+			files = append(files, &f)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].CreatedAt.Before(files[j].CreatedAt) })
+	return files
+}
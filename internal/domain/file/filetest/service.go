@@ -0,0 +1,28 @@
+package filetest
+
+import (
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/infrastructure/storage/memory"
+)
+
+// Service is a file service running on in-memory repositories and storage
+type Service struct {
+	*file.Service
+	Files    *Repository
+	Versions *VersionRepository
+	Storage  *memory.MemoryProvider
+}
+
+// NewService wires a file service to in-memory repositories and storage
+// Quotas are charged to users, folders validates the destination folder of uploads
+func NewService(users user.Repository, folders common.FolderValidator) *Service {
+	s := &Service{
+		Files:   NewRepository(),
+		Storage: memory.NewMemoryProvider(),
+	}
+	s.Versions = NewVersionRepository(s.Files)
+	s.Service = file.NewService(s.Files, s.Versions, folders, s.Storage, user.NewStorageService(users), file.VersionRetention{})
+	return s
+}
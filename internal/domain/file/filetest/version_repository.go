@@ -0,0 +1,119 @@
+package filetest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"easy-storage/internal/domain/file"
+
+	"github.com/google/uuid"
+)
+
+// VersionRepository implements file.VersionRepository in memory
+type VersionRepository struct {
+	mu       sync.Mutex
+	files    *Repository
+	versions map[string]file.FileVersion
+	SaveErr  error // Returned by Save when set
+}
+
+// NewVersionRepository creates an empty in-memory version repository
+// files is consulted for the current version of each file, like the join of the GORM repository
+func NewVersionRepository(files *Repository) *VersionRepository {
+	return &VersionRepository{
+		files:    files,
+		versions: make(map[string]file.FileVersion),
+	}
+}
+
+// Save stores a version, assigning an ID to new versions
+func (r *VersionRepository) Save(version *file.FileVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.SaveErr != nil {
+		return r.SaveErr
+	}
+	if version.ID == "" {
+		version.ID = uuid.New().String()
+	}
+	r.versions[version.ID] = *version
+	return nil
+}
+
+// FindByFileID returns the versions of a file, newest first
+func (r *VersionRepository) FindByFileID(fileID string) ([]*file.FileVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := []*file.FileVersion{}
+	for _, version := range r.versions {
+		if version.FileID == fileID {
+			v := version
+			versions = append(versions, &v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions, nil
+}
+
+// FindByFileIDAndVersion finds a version of a file by its number
+func (r *VersionRepository) FindByFileIDAndVersion(fileID string, number int) (*file.FileVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, version := range r.versions {
+		if version.FileID == fileID && version.Version == number {
+			v := version
+			return &v, nil
+		}
+	}
+	return nil, file.ErrVersionNotFound
+}
+
+// FindFileIDsWithVersionsBefore returns files having a non-current version created before the given time
+func (r *VersionRepository) FindFileIDsWithVersionsBefore(before time.Time) ([]string, error) {
+	r.mu.Lock()
+	candidates := []file.FileVersion{}
+	for _, version := range r.versions {
+		if version.CreatedAt.Before(before) {
+			candidates = append(candidates, version)
+		}
+	}
+	r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	fileIDs := []string{}
+	for _, version := range candidates {
+		f, err := r.files.FindByID(version.FileID)
+		if err != nil || f.Version == version.Version || seen[f.ID] {
+			continue
+		}
+		seen[f.ID] = true
+		fileIDs = append(fileIDs, f.ID)
+	}
+	return fileIDs, nil
+}
+
+// Delete removes a version
+func (r *VersionRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.versions, id)
+	return nil
+}
+
+// DeleteByFileID removes every version of a file
+func (r *VersionRepository) DeleteByFileID(fileID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, version := range r.versions {
+		if version.FileID == fileID {
+			delete(r.versions, id)
+		}
+	}
+	return nil
+}
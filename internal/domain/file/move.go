@@ -0,0 +1,44 @@
+package file
+
+import (
+	"time"
+
+	"easy-storage/internal/domain/common"
+)
+
+// RenameFile renames a file within its folder
+func (s *Service) RenameFile(file *File, name string) (*File, error) {
+	return s.MoveFile(file, file.FolderID, name)
+}
+
+// MoveFile moves a file to another folder of its owner under the given name
+// An empty folderID moves the file to the root folder
+func (s *Service) MoveFile(file *File, folderID, name string) (*File, error) {
+	if !common.IsValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	// Validate destination folder ownership
+	if err := s.ValidateFolder(folderID, file.UserID); err != nil {
+		return nil, err
+	}
+
+	// Names are unique within a folder, uploads with the same name become versions
+	existing, err := s.repo.FindByName(file.UserID, folderID, name)
+	if err != nil && err != ErrFileNotFound {
+		return nil, err
+	}
+	if err == nil && existing.ID != file.ID {
+		return nil, ErrNameConflict
+	}
+
+	file.Name = name
+	file.FolderID = folderID
+	file.UpdatedAt = time.Now()
+
+	if err := s.repo.Save(file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
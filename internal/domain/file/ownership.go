@@ -0,0 +1,11 @@
+package file
+
+import "easy-storage/internal/domain/common"
+
+// FreeName returns name, or name with a " (n)" suffix, so it is not taken in a folder of a user
+// An empty folderID is the root folder
+func (s *Service) FreeName(userID, folderID, name string) (string, error) {
+	return common.UniqueName(name, func(candidate string) (bool, error) {
+		return s.nameTaken(userID, folderID, candidate)
+	})
+}
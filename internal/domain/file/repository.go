@@ -2,6 +2,7 @@ package file
 
 import (
 	"errors"
+	"time"
 )
 
 // ErrFileNotFound is returned when a file cannot be found
@@ -10,12 +11,54 @@ var ErrFileNotFound = errors.New("file not found")
 // ErrInvalidFolder is returned when a folder doesn't exist or doesn't belong to the user
 var ErrInvalidFolder = errors.New("invalid folder")
 
+// ErrInvalidName is returned when a file name is empty or contains path separators
+var ErrInvalidName = errors.New("invalid file name")
+
+// ErrNameConflict is returned when the destination folder already contains a file with the same name
+var ErrNameConflict = errors.New("a file with this name already exists in the folder")
+
+// ErrVersionNotFound is returned when a file version cannot be found
+var ErrVersionNotFound = errors.New("file version not found")
+
+// ErrCurrentVersion is returned when attempting to delete the current version of a file
+var ErrCurrentVersion = errors.New("cannot delete the current version")
+
 // Repository defines the interface for file data access
 type Repository interface {
 	Save(file *File) error
 	FindByID(id string) (*File, error)
 	FindByUserID(userID string, limit, offset int, sortBy, sortDir string) ([]*File, error)
 	FindByUserIDAndFolder(userID string, folderID string) ([]*File, error)
+	// FindByName finds the file with the given name in a folder, an empty folderID is the root folder
+	FindByName(userID, folderID, name string) (*File, error)
 	Delete(id string) error
 	DeleteByFolder(folderID string) error
+
+	// Trash moves a file to the trash, trashedWith is the ID of the folder whose deletion trashed it
+	Trash(id, trashedWith string) error
+	// TrashByFolder moves all files of a folder to the trash
+	TrashByFolder(folderID, trashedWith string) error
+	// FindTrashed finds the files a user moved to the trash themselves
+	FindTrashed(userID string) ([]*File, error)
+	// FindTrashedBefore finds files moved to the trash themselves before the given time
+	FindTrashedBefore(before time.Time) ([]*File, error)
+	// FindTrashedWith finds the files trashed along with a folder
+	FindTrashedWith(folderID string) ([]*File, error)
+	FindTrashedByID(id string) (*File, error)
+	// Restore takes a file out of the trash into the given folder under the given name
+	Restore(id, folderID, name string) error
+	// Purge permanently deletes a file
+	Purge(id string) error
+}
+
+// VersionRepository defines the interface for file version data access
+type VersionRepository interface {
+	Save(version *FileVersion) error
+	// FindByFileID returns the versions of a file, newest first
+	FindByFileID(fileID string) ([]*FileVersion, error)
+	FindByFileIDAndVersion(fileID string, version int) (*FileVersion, error)
+	// FindFileIDsWithVersionsBefore returns files having a non-current version created before the given time
+	FindFileIDsWithVersionsBefore(before time.Time) ([]string, error)
+	Delete(id string) error
+	DeleteByFileID(fileID string) error
 }
@@ -5,31 +5,37 @@ import (
 	"easy-storage/internal/domain/user"
 	"io"
 	"log"
+	"time"
 )
 
 // StorageProvider defines the interface for file storage operations
 type StorageProvider interface {
-	Upload(filename string, contentType string, file io.Reader) (string, error)
+	Upload(filename string, contentType string, size int64, file io.Reader) (string, error)
 	Download(path string) (io.ReadCloser, error)
 	Delete(path string) error
 	GetSignedURL(path string, expiryTime int64) (string, error)
+	Copy(path string) (string, error)
 }
 
 // Service provides file operations
 type Service struct {
 	repo            Repository
+	versionRepo     VersionRepository
 	folderValidator common.FolderValidator
 	storage         StorageProvider
 	userStorage     *user.StorageService
+	retention       VersionRetention
 }
 
 // NewService creates a new file service
-func NewService(repo Repository, folderValidator common.FolderValidator, storage StorageProvider, userStorage *user.StorageService) *Service {
+func NewService(repo Repository, versionRepo VersionRepository, folderValidator common.FolderValidator, storage StorageProvider, userStorage *user.StorageService, retention VersionRetention) *Service {
 	return &Service{
 		repo:            repo,
+		versionRepo:     versionRepo,
 		folderValidator: folderValidator,
 		storage:         storage,
 		userStorage:     userStorage,
+		retention:       retention,
 	}
 }
 
@@ -58,7 +64,7 @@ func (s *Service) UploadFile(filename string, size int64, contentType string, fi
 	}
 
 	// Upload file to storage
-	path, err := s.storage.Upload(filename, contentType, fileContent)
+	path, err := s.storage.Upload(filename, contentType, size, fileContent)
 	if err != nil {
 		// Rollback storage usage increment if upload fails
 		if s.userStorage != nil {
@@ -67,11 +73,9 @@ func (s *Service) UploadFile(filename string, size int64, contentType string, fi
 		return nil, err
 	}
 
-	// Create file entity
-	file := NewFile(filename, size, contentType, path, userID, folderID)
-
-	// Save file metadata to repository
-	if err := s.repo.Save(file); err != nil {
+	// Save file metadata, adding a version if the file already exists
+	file, err := s.storeFile(filename, size, contentType, path, userID, folderID)
+	if err != nil {
 		// Try to clean up the stored file if metadata save fails
 		_ = s.storage.Delete(path)
 		// Rollback storage usage increment if save fails
@@ -84,6 +88,114 @@ func (s *Service) UploadFile(filename string, size int64, contentType string, fi
 	return file, nil
 }
 
+// UploadNewFile uploads a file on behalf of another user into a folder of the owner
+// Unlike UploadFile, it never adds a version to an existing file, the name gets a " (n)" suffix instead
+// The bytes are charged to the owner's quota, the folder is not validated
+// An empty uploaderID, for anonymous uploads, records the owner as the uploader
+func (s *Service) UploadNewFile(filename string, size int64, contentType string, fileContent io.Reader, ownerID, folderID, uploaderID string) (*File, error) {
+	if !common.IsValidName(filename) {
+		return nil, ErrInvalidName
+	}
+	if uploaderID == "" {
+		uploaderID = ownerID
+	}
+
+	name, err := common.UniqueName(filename, func(candidate string) (bool, error) {
+		return s.nameTaken(ownerID, folderID, candidate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if the owner has enough storage quota
+	if err := s.ReserveStorage(ownerID, size); err != nil {
+		return nil, err
+	}
+
+	// Upload file to storage
+	path, err := s.storage.Upload(name, contentType, size, fileContent)
+	if err != nil {
+		s.ReleaseStorage(ownerID, size)
+		return nil, err
+	}
+
+	file := NewFile(name, size, contentType, path, ownerID, folderID)
+	if err := s.repo.Save(file); err != nil {
+		_ = s.storage.Delete(path)
+		s.ReleaseStorage(ownerID, size)
+		return nil, err
+	}
+
+	// Record the first version with the uploader
+	if err := s.versionRepo.Save(NewFileVersion(file.ID, file.Version, size, contentType, path, uploaderID)); err != nil {
+		_ = s.repo.Purge(file.ID)
+		_ = s.storage.Delete(path)
+		s.ReleaseStorage(ownerID, size)
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// RegisterUploadedFile saves metadata for an object that was already written to storage
+// It charges the user's quota and removes the object if the file cannot be registered
+func (s *Service) RegisterUploadedFile(filename string, size int64, contentType, path, userID, folderID string) (*File, error) {
+	// Charge the user's storage quota
+	if s.userStorage != nil {
+		if err := s.userStorage.AddStorage(userID, size); err != nil {
+			_ = s.storage.Delete(path)
+			return nil, err
+		}
+	}
+
+	file, err := s.RegisterReservedFile(filename, size, contentType, path, userID, folderID)
+	if err != nil {
+		if s.userStorage != nil {
+			_ = s.userStorage.RemoveStorage(userID, size)
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// RegisterReservedFile saves metadata for an object whose size was already charged to the user's quota
+// It removes the object if the file cannot be registered, releasing the quota is left to the caller
+func (s *Service) RegisterReservedFile(filename string, size int64, contentType, path, userID, folderID string) (*File, error) {
+	// The folder may have been deleted since the quota was reserved
+	if err := s.ValidateFolder(folderID, userID); err != nil {
+		_ = s.storage.Delete(path)
+		return nil, err
+	}
+
+	// Save file metadata, adding a version if the file already exists
+	file, err := s.storeFile(filename, size, contentType, path, userID, folderID)
+	if err != nil {
+		_ = s.storage.Delete(path)
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ValidateFolder checks that a folder exists and belongs to the user
+// An empty folderID refers to the root folder and is always valid
+func (s *Service) ValidateFolder(folderID, userID string) error {
+	if folderID == "" {
+		return nil
+	}
+
+	belongs, err := s.folderValidator.BelongsToUser(folderID, userID)
+	if err != nil {
+		return err
+	}
+	if !belongs {
+		return ErrInvalidFolder
+	}
+
+	return nil
+}
+
 // GetFile retrieves a file by ID
 func (s *Service) GetFile(id string) (*File, error) {
 	return s.repo.FindByID(id)
@@ -94,29 +206,15 @@ func (s *Service) GetFileContent(file *File) (io.ReadCloser, error) {
 	return s.storage.Download(file.Path)
 }
 
-// DeleteFile deletes a file
+// DeleteFile moves a file to the trash
+// Its content and quota are kept until the file is purged from the trash
 func (s *Service) DeleteFile(id string) error {
-	// Get file first to get the path
-	file, err := s.repo.FindByID(id)
-	if err != nil {
+	// Make sure the file exists and is not already in the trash
+	if _, err := s.repo.FindByID(id); err != nil {
 		return err
 	}
 
-	// Delete from repository
-	if err := s.repo.Delete(id); err != nil {
-		return err
-	}
-
-	// Update user storage statistics
-	if s.userStorage != nil {
-		if err := s.userStorage.RemoveStorage(file.UserID, file.Size); err != nil {
-			log.Printf("Error updating storage statistics: %v", err)
-			// Continue with deletion even if stats update fails
-		}
-	}
-
-	// Delete from storage
-	return s.storage.Delete(file.Path)
+	return s.repo.Trash(id, "")
 }
 
 // ListUserFiles lists files for a user
@@ -148,37 +246,108 @@ func (s *Service) ListFilesInFolder(userID string, folderID string) ([]*File, er
 	return s.repo.FindByUserIDAndFolder(userID, folderID)
 }
 
-// DeleteByFolder deletes all files in a folder
-func (s *Service) DeleteByFolder(userID, folderID string) error {
-	// Get all files in the folder
-	files, err := s.repo.FindByUserIDAndFolder(userID, folderID)
+// TrashByFolder moves all files in a folder to the trash along with the folder being deleted
+func (s *Service) TrashByFolder(folderID, trashedWith string) error {
+	return s.repo.TrashByFolder(folderID, trashedWith)
+}
+
+// storeFile saves an object already written to storage as a new file
+// If the folder already contains a file with the same name, the object becomes its new version
+func (s *Service) storeFile(filename string, size int64, contentType, path, userID, folderID string) (*File, error) {
+	existing, err := s.repo.FindByName(userID, folderID, filename)
+	if err == nil {
+		return existing, s.addVersion(existing, size, contentType, path, userID)
+	}
+	if err != ErrFileNotFound {
+		return nil, err
+	}
+
+	// Create file entity
+	file := NewFile(filename, size, contentType, path, userID, folderID)
+
+	// Save file metadata to repository
+	if err := s.repo.Save(file); err != nil {
+		return nil, err
+	}
+
+	// Record the first version
+	if err := s.versionRepo.Save(NewFileVersion(file.ID, file.Version, size, contentType, path, userID)); err != nil {
+		_ = s.repo.Purge(file.ID)
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// addVersion records an object as the newest version of a file and makes it current
+func (s *Service) addVersion(file *File, size int64, contentType, path, uploaderID string) error {
+	versions, err := s.versionRepo.FindByFileID(file.ID)
 	if err != nil {
 		return err
 	}
 
-	// Delete each file individually to ensure proper storage cleanup
-	for _, file := range files {
-		// Delete from storage
-		if err := s.storage.Delete(file.Path); err != nil {
-			// Log error but continue with other deletions
-			// We don't want to stop the process if one file fails to delete
-			// from storage, but we should log it for investigation
-			log.Printf("Error deleting file from storage: %v", err)
-		}
+	// Versions are numbered after the newest one, which is not the current one after a restore
+	number := file.Version + 1
+	if len(versions) > 0 && versions[0].Version >= number {
+		number = versions[0].Version + 1
+	}
+
+	version := NewFileVersion(file.ID, number, size, contentType, path, uploaderID)
+	if err := s.versionRepo.Save(version); err != nil {
+		return err
+	}
+
+	if err := s.setCurrentVersion(file, version); err != nil {
+		_ = s.versionRepo.Delete(version.ID)
+		return err
+	}
+
+	// Apply retention, failures only delay the pruning of old versions
+	if _, err := s.PruneVersions(file.ID, s.retention); err != nil {
+		log.Printf("Error pruning versions of file %s: %v", file.ID, err)
+	}
+
+	return nil
+}
+
+// setCurrentVersion points a file at the content of one of its versions
+func (s *Service) setCurrentVersion(file *File, version *FileVersion) error {
+	file.Version = version.Version
+	file.Size = version.Size
+	file.ContentType = version.ContentType
+	file.Path = version.Path
+	file.UpdatedAt = time.Now()
 
-		// Delete from repository
-		if err := s.repo.Delete(file.ID); err != nil {
-			return err
+	return s.repo.Save(file)
+}
+
+// deleteContent deletes every version of a file from storage and releases its quota
+func (s *Service) deleteContent(file *File) error {
+	versions, err := s.versionRepo.FindByFileID(file.ID)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	var total int64
+	for _, version := range versions {
+		if err := s.storage.Delete(version.Path); err != nil && firstErr == nil {
+			firstErr = err
 		}
+		total += version.Size
+	}
 
-		// Update user storage statistics
-		if s.userStorage != nil {
-			if err := s.userStorage.RemoveStorage(userID, file.Size); err != nil {
-				log.Printf("Error updating storage statistics: %v", err)
-				// Continue with deletion even if stats update fails
-			}
+	if err := s.versionRepo.DeleteByFileID(file.ID); err != nil {
+		log.Printf("Error deleting versions of file %s: %v", file.ID, err)
+	}
+
+	// Update user storage statistics
+	if s.userStorage != nil {
+		if err := s.userStorage.RemoveStorage(file.UserID, total); err != nil {
+			log.Printf("Error updating storage statistics: %v", err)
+			// Continue with deletion even if stats update fails
 		}
 	}
 
-	return nil
+	return firstErr
 }
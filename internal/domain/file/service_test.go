@@ -0,0 +1,270 @@
+package file_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/file/filetest"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/domain/user/usertest"
+	"easy-storage/internal/infrastructure/storage/memory"
+)
+
+var errSaveFailed = errors.New("save failed")
+
+// folderOwners implements common.FolderValidator from folder IDs to their owners
+type folderOwners map[string]string
+
+func (f folderOwners) BelongsToUser(folderID string, userID string) (bool, error) {
+	return f[folderID] == userID, nil
+}
+
+// fixture wires a file service to in-memory repositories and storage
+type fixture struct {
+	service  *file.Service
+	files    *filetest.Repository
+	versions *filetest.VersionRepository
+	users    *usertest.Repository
+	storage  *memory.MemoryProvider
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	users := usertest.NewRepository()
+	users.AddUser(t, "owner", 100)
+
+	files := filetest.NewService(users, folderOwners{"folder": "owner"})
+	return &fixture{
+		service:  files.Service,
+		files:    files.Files,
+		versions: files.Versions,
+		users:    users,
+		storage:  files.Storage,
+	}
+}
+
+func (f *fixture) storageUsed(t *testing.T) int64 {
+	t.Helper()
+
+	return f.users.StorageUsed(t, "owner")
+}
+
+func TestUploadFile(t *testing.T) {
+	f := newFixture(t)
+
+	uploaded, err := f.service.UploadFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "folder")
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	if used := f.storageUsed(t); used != 5 {
+		t.Errorf("storage used = %d, want 5", used)
+	}
+	if !f.storage.Exists(uploaded.Path) {
+		t.Error("content was not stored")
+	}
+	versions, _ := f.versions.FindByFileID(uploaded.ID)
+	if len(versions) != 1 || versions[0].Path != uploaded.Path {
+		t.Errorf("versions = %+v, want a single version at the file's path", versions)
+	}
+}
+
+func TestUploadFileSameNameAddsVersion(t *testing.T) {
+	f := newFixture(t)
+
+	first, err := f.service.UploadFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "")
+	if err != nil {
+		t.Fatalf("first UploadFile: %v", err)
+	}
+	second, err := f.service.UploadFile("notes.txt", 6, "text/plain", strings.NewReader("hello!"), "owner", "")
+	if err != nil {
+		t.Fatalf("second UploadFile: %v", err)
+	}
+
+	if second.ID != first.ID || second.Version != 2 {
+		t.Errorf("second upload = file %s version %d, want file %s version 2", second.ID, second.Version, first.ID)
+	}
+	if f.files.Len() != 1 {
+		t.Errorf("stored %d files, want 1", f.files.Len())
+	}
+	if used := f.storageUsed(t); used != 11 {
+		t.Errorf("storage used = %d, want both versions charged", used)
+	}
+}
+
+func TestUploadFileInvalidFolder(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.service.UploadFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "someone-elses")
+	if err != file.ErrInvalidFolder {
+		t.Fatalf("UploadFile error = %v, want ErrInvalidFolder", err)
+	}
+	if f.storage.Count() != 0 || f.storageUsed(t) != 0 {
+		t.Error("a rejected upload stored content or charged quota")
+	}
+}
+
+func TestUploadFileQuotaExceeded(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.service.UploadFile("big.bin", 101, "application/octet-stream", strings.NewReader("x"), "owner", "")
+	if err != user.ErrStorageQuotaExceeded {
+		t.Fatalf("UploadFile error = %v, want ErrStorageQuotaExceeded", err)
+	}
+	if f.storage.Count() != 0 || f.files.Len() != 0 {
+		t.Error("an upload over quota stored content or metadata")
+	}
+}
+
+func TestUploadFileStorageFailureReleasesQuota(t *testing.T) {
+	f := newFixture(t)
+	f.storage.FailNthUpload(1)
+
+	_, err := f.service.UploadFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "")
+	if err != memory.ErrInjectedFailure {
+		t.Fatalf("UploadFile error = %v, want ErrInjectedFailure", err)
+	}
+	if used := f.storageUsed(t); used != 0 {
+		t.Errorf("storage used = %d, want the reservation released", used)
+	}
+	if f.files.Len() != 0 {
+		t.Error("metadata was saved for a failed upload")
+	}
+}
+
+func TestUploadFileSaveFailureDeletesContent(t *testing.T) {
+	f := newFixture(t)
+	f.files.SaveErr = errSaveFailed
+
+	_, err := f.service.UploadFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "")
+	if err != errSaveFailed {
+		t.Fatalf("UploadFile error = %v, want the save error", err)
+	}
+	if f.storage.Count() != 0 {
+		t.Error("content of an unsaved file was left in storage")
+	}
+	if used := f.storageUsed(t); used != 0 {
+		t.Errorf("storage used = %d, want the reservation released", used)
+	}
+}
+
+func TestUploadFileVersionFailurePurgesFile(t *testing.T) {
+	f := newFixture(t)
+	f.versions.SaveErr = errSaveFailed
+
+	_, err := f.service.UploadFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "")
+	if err != errSaveFailed {
+		t.Fatalf("UploadFile error = %v, want the save error", err)
+	}
+
+	// The row is purged, a soft-deleted row would show up in the trash
+	if f.files.Len() != 0 {
+		t.Error("the file row outlived its failed version")
+	}
+	if trashed, _ := f.files.FindTrashed("owner"); len(trashed) != 0 {
+		t.Errorf("%d files reached the trash", len(trashed))
+	}
+	if f.storage.Count() != 0 || f.storageUsed(t) != 0 {
+		t.Error("the failed upload left content or charged quota")
+	}
+}
+
+func TestUploadNewFileRenamesInsteadOfVersioning(t *testing.T) {
+	f := newFixture(t)
+
+	first, err := f.service.UploadNewFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "", "guest")
+	if err != nil {
+		t.Fatalf("first UploadNewFile: %v", err)
+	}
+	second, err := f.service.UploadNewFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "", "")
+	if err != nil {
+		t.Fatalf("second UploadNewFile: %v", err)
+	}
+
+	if second.ID == first.ID || second.Name != "notes (1).txt" {
+		t.Errorf("second upload = %q, want a new file named %q", second.Name, "notes (1).txt")
+	}
+	versions, _ := f.versions.FindByFileID(first.ID)
+	if len(versions) != 1 || versions[0].UserID != "guest" {
+		t.Errorf("versions = %+v, want one version uploaded by guest", versions)
+	}
+}
+
+func TestUploadNewFileRollbacks(t *testing.T) {
+	t.Run("storage failure", func(t *testing.T) {
+		f := newFixture(t)
+		f.storage.FailNthUpload(1)
+
+		if _, err := f.service.UploadNewFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "", ""); err != memory.ErrInjectedFailure {
+			t.Fatalf("UploadNewFile error = %v, want ErrInjectedFailure", err)
+		}
+		if f.storageUsed(t) != 0 || f.files.Len() != 0 {
+			t.Error("the failed upload left metadata or charged quota")
+		}
+	})
+
+	t.Run("version failure", func(t *testing.T) {
+		f := newFixture(t)
+		f.versions.SaveErr = errSaveFailed
+
+		if _, err := f.service.UploadNewFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "", ""); err != errSaveFailed {
+			t.Fatalf("UploadNewFile error = %v, want the save error", err)
+		}
+		if f.files.Len() != 0 || f.storage.Count() != 0 || f.storageUsed(t) != 0 {
+			t.Error("the failed upload left a row, content or charged quota")
+		}
+	})
+}
+
+func TestCopyFileVersionFailurePurgesCopy(t *testing.T) {
+	f := newFixture(t)
+	original, err := f.service.UploadFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "")
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	f.versions.SaveErr = errSaveFailed
+	if _, err := f.service.CopyFile(original, "", ""); err != errSaveFailed {
+		t.Fatalf("CopyFile error = %v, want the save error", err)
+	}
+
+	if f.files.Len() != 1 || f.storage.Count() != 1 {
+		t.Errorf("stored %d files and %d objects, want only the original", f.files.Len(), f.storage.Count())
+	}
+	if used := f.storageUsed(t); used != 5 {
+		t.Errorf("storage used = %d, want only the original charged", used)
+	}
+}
+
+func TestDeleteFileKeepsContentForSlowDownloads(t *testing.T) {
+	f := newFixture(t)
+	uploaded, err := f.service.UploadFile("notes.txt", 5, "text/plain", strings.NewReader("hello"), "owner", "")
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	f.storage.SetReadDelay(10 * time.Millisecond)
+	content, err := f.service.GetFileContent(uploaded)
+	if err != nil {
+		t.Fatalf("GetFileContent: %v", err)
+	}
+	defer content.Close()
+
+	// Trashing the file mid-download keeps its content until it is purged
+	if err := f.service.DeleteFile(uploaded.ID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("download = %q, %v, want the full content", data, err)
+	}
+	if !f.storage.Exists(uploaded.Path) || f.storageUsed(t) != 5 {
+		t.Error("trashing the file removed its content or released its quota")
+	}
+}
@@ -0,0 +1,105 @@
+package file
+
+import (
+	"time"
+
+	"easy-storage/internal/domain/common"
+)
+
+// ListTrashedFiles lists the files a user moved to the trash
+// Files trashed along with a folder are listed through that folder
+func (s *Service) ListTrashedFiles(userID string) ([]*File, error) {
+	return s.repo.FindTrashed(userID)
+}
+
+// ListTrashedFilesBefore lists the files moved to the trash before the given time
+func (s *Service) ListTrashedFilesBefore(before time.Time) ([]*File, error) {
+	return s.repo.FindTrashedBefore(before)
+}
+
+// GetTrashedFile retrieves a file in the trash by ID
+func (s *Service) GetTrashedFile(id string) (*File, error) {
+	return s.repo.FindTrashedByID(id)
+}
+
+// RestoreFile takes a file out of the trash
+// The file goes back to its folder, or to the root folder if that folder is gone,
+// and is renamed if its name has been taken in the meantime
+func (s *Service) RestoreFile(file *File) (*File, error) {
+	folderID := file.FolderID
+	if folderID != "" {
+		belongs, err := s.folderValidator.BelongsToUser(folderID, file.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !belongs {
+			folderID = ""
+		}
+	}
+
+	name, err := common.UniqueName(file.Name, func(candidate string) (bool, error) {
+		return s.nameTaken(file.UserID, folderID, candidate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(file.ID, folderID, name); err != nil {
+		return nil, err
+	}
+
+	file.FolderID = folderID
+	file.Name = name
+	file.TrashedAt = time.Time{}
+	return file, nil
+}
+
+// RestoreTrashedWith takes the files trashed along with a folder out of the trash
+func (s *Service) RestoreTrashedWith(folderID string) error {
+	files, err := s.repo.FindTrashedWith(folderID)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := s.repo.Restore(file.ID, file.FolderID, file.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeFile permanently deletes a file in the trash with all of its versions
+func (s *Service) PurgeFile(file *File) error {
+	if err := s.repo.Purge(file.ID); err != nil {
+		return err
+	}
+
+	return s.deleteContent(file)
+}
+
+// PurgeTrashedWith permanently deletes the files trashed along with a folder
+func (s *Service) PurgeTrashedWith(folderID string) error {
+	files, err := s.repo.FindTrashedWith(folderID)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := s.PurgeFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nameTaken checks if a folder already contains a file with the given name
+func (s *Service) nameTaken(userID, folderID, name string) (bool, error) {
+	_, err := s.repo.FindByName(userID, folderID, name)
+	if err == ErrFileNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
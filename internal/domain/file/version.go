@@ -0,0 +1,50 @@
+package file
+
+import (
+	"time"
+)
+
+// FileVersion represents one stored revision of a file's content
+type FileVersion struct {
+	ID          string
+	FileID      string
+	Version     int
+	Size        int64
+	ContentType string
+	Path        string
+	UserID      string // User who uploaded this version
+	CreatedAt   time.Time
+}
+
+// NewFileVersion creates a new file version entity
+func NewFileVersion(fileID string, version int, size int64, contentType, path, userID string) *FileVersion {
+	return &FileVersion{
+		FileID:      fileID,
+		Version:     version,
+		Size:        size,
+		ContentType: contentType,
+		Path:        path,
+		UserID:      userID,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// VersionRetention configures how many old versions of a file are kept
+// A version is pruned when it is not among the newest KeepLast versions or is older than KeepDays
+// Zero disables the corresponding rule, the current version is never pruned
+type VersionRetention struct {
+	KeepLast int
+	KeepDays int
+}
+
+// isExpired checks if a non-current version falls outside the retention rules
+// position is the version's 0-based rank among the file's versions, newest first
+func (r VersionRetention) isExpired(version *FileVersion, position int) bool {
+	if r.KeepLast > 0 && position >= r.KeepLast {
+		return true
+	}
+	if r.KeepDays > 0 && version.CreatedAt.Before(time.Now().AddDate(0, 0, -r.KeepDays)) {
+		return true
+	}
+	return false
+}
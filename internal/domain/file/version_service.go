@@ -0,0 +1,153 @@
+package file
+
+import (
+	"io"
+	"log"
+	"time"
+)
+
+// UploadVersion uploads new content for an existing file
+// The bytes are charged to the file owner's quota, whoever uploads them
+func (s *Service) UploadVersion(file *File, size int64, contentType string, fileContent io.Reader, uploaderID string) (*File, error) {
+	// Check if the owner has enough storage quota
+	if s.userStorage != nil {
+		if err := s.userStorage.AddStorage(file.UserID, size); err != nil {
+			return nil, err
+		}
+	}
+
+	// Upload file to storage
+	path, err := s.storage.Upload(file.Name, contentType, size, fileContent)
+	if err != nil {
+		if s.userStorage != nil {
+			_ = s.userStorage.RemoveStorage(file.UserID, size)
+		}
+		return nil, err
+	}
+
+	if err := s.addVersion(file, size, contentType, path, uploaderID); err != nil {
+		_ = s.storage.Delete(path)
+		if s.userStorage != nil {
+			_ = s.userStorage.RemoveStorage(file.UserID, size)
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ListVersions lists the versions of a file, newest first
+func (s *Service) ListVersions(fileID string) ([]*FileVersion, error) {
+	return s.versionRepo.FindByFileID(fileID)
+}
+
+// GetVersion retrieves a specific version of a file
+func (s *Service) GetVersion(fileID string, version int) (*FileVersion, error) {
+	return s.versionRepo.FindByFileIDAndVersion(fileID, version)
+}
+
+// GetVersionSignedURL returns a signed URL for a file version
+func (s *Service) GetVersionSignedURL(version *FileVersion, expiryTime int64) (string, error) {
+	return s.storage.GetSignedURL(version.Path, expiryTime)
+}
+
+// RestoreVersion makes an older version the current content of a file
+// Newer versions are kept, so a restore can itself be undone
+func (s *Service) RestoreVersion(file *File, version int) (*File, error) {
+	restored, err := s.versionRepo.FindByFileIDAndVersion(file.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setCurrentVersion(file, restored); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// DeleteVersion deletes a version that is not the current one
+func (s *Service) DeleteVersion(file *File, version int) error {
+	if version == file.Version {
+		return ErrCurrentVersion
+	}
+
+	deleted, err := s.versionRepo.FindByFileIDAndVersion(file.ID, version)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteVersion(file, deleted)
+}
+
+// PruneVersions deletes the versions of a file outside the retention rules
+// It returns the number of deleted versions
+func (s *Service) PruneVersions(fileID string, retention VersionRetention) (int, error) {
+	if retention.KeepLast <= 0 && retention.KeepDays <= 0 {
+		return 0, nil
+	}
+
+	file, err := s.repo.FindByID(fileID)
+	if err != nil {
+		return 0, err
+	}
+
+	versions, err := s.versionRepo.FindByFileID(fileID)
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for position, version := range versions {
+		if version.Version == file.Version || !retention.isExpired(version, position) {
+			continue
+		}
+
+		if err := s.deleteVersion(file, version); err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+
+	return pruned, nil
+}
+
+// PruneExpiredVersions applies the configured retention to files with versions older than KeepDays
+func (s *Service) PruneExpiredVersions() error {
+	if s.retention.KeepDays <= 0 {
+		return nil
+	}
+
+	fileIDs, err := s.versionRepo.FindFileIDsWithVersionsBefore(time.Now().AddDate(0, 0, -s.retention.KeepDays))
+	if err != nil {
+		return err
+	}
+
+	for _, fileID := range fileIDs {
+		if _, err := s.PruneVersions(fileID, s.retention); err != nil {
+			log.Printf("Error pruning versions of file %s: %v", fileID, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteVersion removes a version record and its object, and releases its quota
+func (s *Service) deleteVersion(file *File, version *FileVersion) error {
+	if err := s.versionRepo.Delete(version.ID); err != nil {
+		return err
+	}
+
+	if err := s.storage.Delete(version.Path); err != nil {
+		log.Printf("Error deleting version %d of file %s from storage: %v", version.Version, file.ID, err)
+	}
+
+	// Update user storage statistics
+	if s.userStorage != nil {
+		if err := s.userStorage.RemoveStorage(file.UserID, version.Size); err != nil {
+			log.Printf("Error updating storage statistics: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package folder
+
+import (
+	"path"
+
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/file"
+)
+
+// ArchiveEntry is a file or a folder placed in an archive
+type ArchiveEntry struct {
+	Path string     // Slash separated path inside the archive, folders end with a slash
+	File *file.File // Nil for folders
+}
+
+// ListArchiveEntries lists a folder and its subtree as archive entries rooted at the folder's name
+// Folders are listed before their contents, so empty folders are kept in the archive
+func (s *Service) ListArchiveEntries(folder *Folder) ([]ArchiveEntry, error) {
+	subfolders, err := s.getAllSubfolders(folder.UserID, folder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	folderPaths := make(map[string]string)
+	var entries []ArchiveEntry
+
+	for _, current := range append([]Folder{*folder}, subfolders...) {
+		// Subfolders are listed after their parent, whose path is already known
+		parentPath := folderPaths[current.ParentID]
+		if current.ID == folder.ID {
+			parentPath = ""
+		}
+
+		folderPath, err := uniqueArchivePath(used, path.Join(parentPath, current.Name))
+		if err != nil {
+			return nil, err
+		}
+		folderPaths[current.ID] = folderPath
+		entries = append(entries, ArchiveEntry{Path: folderPath + "/"})
+
+		files, err := s.fileService.ListFilesInFolder(folder.UserID, current.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			filePath, err := uniqueArchivePath(used, path.Join(folderPath, f.Name))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, ArchiveEntry{Path: filePath, File: f})
+		}
+	}
+
+	return entries, nil
+}
+
+// FileArchiveEntries places a selection of files at the root of an archive
+func FileArchiveEntries(files []*file.File) ([]ArchiveEntry, error) {
+	used := make(map[string]bool)
+	entries := make([]ArchiveEntry, 0, len(files))
+
+	for _, f := range files {
+		filePath, err := uniqueArchivePath(used, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, ArchiveEntry{Path: filePath, File: f})
+	}
+
+	return entries, nil
+}
+
+// uniqueArchivePath reserves a path that is not used yet in the archive
+// Folders created before names had to be unique may share a name with a sibling
+func uniqueArchivePath(used map[string]bool, candidate string) (string, error) {
+	unique, err := common.UniqueName(candidate, func(name string) (bool, error) {
+		return used[name], nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	used[unique] = true
+	return unique, nil
+}
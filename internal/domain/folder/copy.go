@@ -0,0 +1,108 @@
+package folder
+
+import (
+	"log"
+
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/file"
+)
+
+// CopyFolder copies a folder with all its files and subfolders under a parent folder of its owner
+// The size of every copied file is charged to the owner's quota before anything is copied
+// An empty parentID copies the folder to the root folder, an empty name keeps the folder's name
+func (s *Service) CopyFolder(folder *Folder, parentID, name string) (*Folder, error) {
+	if name == "" {
+		name = folder.Name
+	}
+	if !common.IsValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	// Validate destination folder ownership
+	if parentID != "" {
+		belongs, err := s.repo.BelongsToUser(parentID, folder.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !belongs {
+			return nil, ErrInvalidParent
+		}
+	}
+
+	// Snapshot the tree first, so copying a folder into its own subtree terminates
+	subfolders, err := s.getAllSubfolders(folder.UserID, folder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]*file.File)
+	var total int64
+	for _, source := range append([]Folder{*folder}, subfolders...) {
+		folderFiles, err := s.fileService.ListFilesInFolder(folder.UserID, source.ID)
+		if err != nil {
+			return nil, err
+		}
+		files[source.ID] = folderFiles
+		for _, f := range folderFiles {
+			total += f.Size
+		}
+	}
+
+	// Check if the owner has enough storage quota for the whole tree
+	if err := s.fileService.ReserveStorage(folder.UserID, total); err != nil {
+		return nil, err
+	}
+
+	name, err = common.UniqueName(name, func(candidate string) (bool, error) {
+		return s.nameTaken(folder.UserID, parentID, candidate)
+	})
+	if err != nil {
+		s.fileService.ReleaseStorage(folder.UserID, total)
+		return nil, err
+	}
+
+	root := NewFolder(name, parentID, folder.UserID)
+	if err := s.repo.Save(root); err != nil {
+		s.fileService.ReleaseStorage(folder.UserID, total)
+		return nil, err
+	}
+
+	// Subfolders are listed before their own subfolders, so parents are always copied first
+	copiedIDs := map[string]string{folder.ID: root.ID}
+	for _, subfolder := range subfolders {
+		copied := NewFolder(subfolder.Name, copiedIDs[subfolder.ParentID], folder.UserID)
+		if err := s.repo.Save(copied); err != nil {
+			s.discardCopy(root, total)
+			return nil, err
+		}
+		copiedIDs[subfolder.ID] = copied.ID
+	}
+
+	var copiedSize int64
+	for sourceID, folderFiles := range files {
+		for _, f := range folderFiles {
+			if _, err := s.fileService.CopyReservedFile(f, copiedIDs[sourceID], f.Name); err != nil {
+				s.discardCopy(root, total-copiedSize)
+				return nil, err
+			}
+			copiedSize += f.Size
+		}
+	}
+
+	return root, nil
+}
+
+// discardCopy removes a partially copied folder tree and releases the quota reserved for files not copied yet
+// Purging the copied files releases their own quota
+func (s *Service) discardCopy(root *Folder, uncopiedSize int64) {
+	s.fileService.ReleaseStorage(root.UserID, uncopiedSize)
+
+	if err := s.DeleteFolder(root.ID, root.UserID); err != nil {
+		log.Printf("Error discarding partial copy %s: %v", root.ID, err)
+		return
+	}
+
+	if err := s.PurgeFolder(root); err != nil {
+		log.Printf("Error purging partial copy %s: %v", root.ID, err)
+	}
+}
@@ -12,6 +12,7 @@ type Folder struct {
 	UserID    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	TrashedAt time.Time // Set while the folder is in the trash
 }
 
 // NewFolder creates a new folder entity
@@ -0,0 +1,234 @@
+// Package foldertest provides an in-memory folder repository for tests
+package foldertest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"easy-storage/internal/domain/folder"
+
+	"github.com/google/uuid"
+)
+
+// folderRow is a stored folder and the folder whose deletion trashed it
+type folderRow struct {
+	folder      folder.Folder
+	trashedWith string
+}
+
+// Repository implements folder.Repository in memory
+// It also implements common.FolderValidator, like the GORM repository
+type Repository struct {
+	mu      sync.Mutex
+	folders map[string]*folderRow
+}
+
+// NewRepository creates an empty in-memory folder repository
+func NewRepository() *Repository {
+	return &Repository{folders: make(map[string]*folderRow)}
+}
+
+// Save stores a folder, assigning an ID to new folders
+func (r *Repository) Save(f *folder.Folder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+
+	row, exists := r.folders[f.ID]
+	if !exists {
+		row = &folderRow{}
+		r.folders[f.ID] = row
+	}
+	row.folder = *f
+	return nil
+}
+
+// FindByID finds a folder that is not in the trash
+func (r *Repository) FindByID(id string) (*folder.Folder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, exists := r.folders[id]
+	if !exists || !row.folder.TrashedAt.IsZero() {
+		return nil, folder.ErrFolderNotFound
+	}
+	f := row.folder
+	return &f, nil
+}
+
+// FindByUserID lists the folders of a user outside the trash
+func (r *Repository) FindByUserID(userID string) ([]*folder.Folder, error) {
+	return r.filter(func(row *folderRow) bool {
+		return row.folder.TrashedAt.IsZero() && row.folder.UserID == userID
+	}), nil
+}
+
+// Delete soft deletes a folder the way GORM does, without linking it to a folder
+func (r *Repository) Delete(id string) error {
+	return r.Trash(id, "")
+}
+
+// BelongsToUser checks if a folder outside the trash belongs to a user
+func (r *Repository) BelongsToUser(folderID string, userID string) (bool, error) {
+	f, err := r.FindByID(folderID)
+	if err == folder.ErrFolderNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return f.UserID == userID, nil
+}
+
+// FindByUserAndParent lists the folders of a user in a parent folder outside the trash
+func (r *Repository) FindByUserAndParent(userID string, parentID string) ([]folder.Folder, error) {
+	return values(r.filter(func(row *folderRow) bool {
+		return row.folder.TrashedAt.IsZero() && row.folder.UserID == userID && row.folder.ParentID == parentID
+	})), nil
+}
+
+// FindByUserAndParentPaginated lists a page of the folders of a user in a parent folder
+func (r *Repository) FindByUserAndParentPaginated(userID string, parentID string, page, pageSize int) ([]folder.Folder, int64, error) {
+	folders, _ := r.FindByUserAndParent(userID, parentID)
+	return paginate(folders, page, pageSize), int64(len(folders)), nil
+}
+
+// FindAllByUserPaginated lists a page of the folders of a user
+func (r *Repository) FindAllByUserPaginated(userID string, page, pageSize int) ([]folder.Folder, int64, error) {
+	folders, _ := r.FindByUserID(userID)
+	return paginate(values(folders), page, pageSize), int64(len(folders)), nil
+}
+
+// FindByName finds the folder with the given name in a parent folder outside the trash
+func (r *Repository) FindByName(userID, parentID, name string) (*folder.Folder, error) {
+	folders := r.filter(func(row *folderRow) bool {
+		return row.folder.TrashedAt.IsZero() && row.folder.UserID == userID &&
+			row.folder.ParentID == parentID && row.folder.Name == name
+	})
+	if len(folders) == 0 {
+		return nil, folder.ErrFolderNotFound
+	}
+	return folders[0], nil
+}
+
+// Trash moves a folder to the trash
+func (r *Repository) Trash(id, trashedWith string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, exists := r.folders[id]
+	if !exists || !row.folder.TrashedAt.IsZero() {
+		return folder.ErrFolderNotFound
+	}
+	row.folder.TrashedAt = time.Now()
+	row.trashedWith = trashedWith
+	return nil
+}
+
+// FindTrashed finds the folders a user moved to the trash themselves, most recent first
+func (r *Repository) FindTrashed(userID string) ([]*folder.Folder, error) {
+	folders := r.filter(func(row *folderRow) bool {
+		return !row.folder.TrashedAt.IsZero() && row.trashedWith == "" && row.folder.UserID == userID
+	})
+	sort.SliceStable(folders, func(i, j int) bool { return folders[i].TrashedAt.After(folders[j].TrashedAt) })
+	return folders, nil
+}
+
+// FindTrashedBefore finds folders moved to the trash themselves before the given time
+func (r *Repository) FindTrashedBefore(before time.Time) ([]*folder.Folder, error) {
+	return r.filter(func(row *folderRow) bool {
+		return !row.folder.TrashedAt.IsZero() && row.trashedWith == "" && row.folder.TrashedAt.Before(before)
+	}), nil
+}
+
+// FindTrashedWith finds the subfolders trashed along with a folder
+func (r *Repository) FindTrashedWith(folderID string) ([]*folder.Folder, error) {
+	return r.filter(func(row *folderRow) bool {
+		return !row.folder.TrashedAt.IsZero() && row.trashedWith == folderID
+	}), nil
+}
+
+// FindTrashedByID finds a folder in the trash
+func (r *Repository) FindTrashedByID(id string) (*folder.Folder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, exists := r.folders[id]
+	if !exists || row.folder.TrashedAt.IsZero() {
+		return nil, folder.ErrFolderNotFound
+	}
+	f := row.folder
+	return &f, nil
+}
+
+// Restore takes a folder out of the trash into the given parent under the given name
+func (r *Repository) Restore(id, parentID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, exists := r.folders[id]
+	if !exists || row.folder.TrashedAt.IsZero() {
+		return folder.ErrFolderNotFound
+	}
+	row.folder.TrashedAt = time.Time{}
+	row.folder.ParentID = parentID
+	row.folder.Name = name
+	row.trashedWith = ""
+	return nil
+}
+
+// Purge permanently deletes a folder
+func (r *Repository) Purge(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.folders, id)
+	return nil
+}
+
+// Len returns the number of stored folders, including those in the trash
+func (r *Repository) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.folders)
+}
+
+// filter returns copies of the folders matching a predicate, oldest first
+func (r *Repository) filter(match func(row *folderRow) bool) []*folder.Folder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	folders := []*folder.Folder{}
+	for _, row := range r.folders {
+		if match(row) {
+			f := row.folder
+			folders = append(folders, &f)
+		}
+	}
+	sort.SliceStable(folders, func(i, j int) bool { return folders[i].CreatedAt.Before(folders[j].CreatedAt) })
+	return folders
+}
+
+// values dereferences a list of folders
+func values(folders []*folder.Folder) []folder.Folder {
+	result := make([]folder.Folder, len(folders))
+	for i, f := range folders {
+		result[i] = *f
+	}
+	return result
+}
+
+// paginate returns a 1-based page of folders
+func paginate(folders []folder.Folder, page, pageSize int) []folder.Folder {
+	start := (page - 1) * pageSize
+	if start >= len(folders) {
+		return []folder.Folder{}
+	}
+	end := min(start+pageSize, len(folders))
+	return folders[start:end]
+}
@@ -0,0 +1,83 @@
+package folder
+
+import (
+	"time"
+
+	"easy-storage/internal/domain/common"
+)
+
+// RenameFolder renames a folder within its parent
+func (s *Service) RenameFolder(folder *Folder, name string) (*Folder, error) {
+	return s.MoveFolder(folder, folder.ParentID, name)
+}
+
+// MoveFolder moves a folder with its contents under another parent folder of its owner
+// An empty parentID moves the folder to the root folder
+func (s *Service) MoveFolder(folder *Folder, parentID, name string) (*Folder, error) {
+	if !common.IsValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	if parentID != "" {
+		// Validate destination folder ownership
+		belongs, err := s.repo.BelongsToUser(parentID, folder.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !belongs {
+			return nil, ErrInvalidParent
+		}
+
+		// A folder cannot become its own descendant
+		isDescendant, err := s.isSelfOrDescendant(parentID, folder.ID)
+		if err != nil {
+			return nil, err
+		}
+		if isDescendant {
+			return nil, ErrFolderCycle
+		}
+	}
+
+	existing, err := s.repo.FindByName(folder.UserID, parentID, name)
+	if err != nil && err != ErrFolderNotFound {
+		return nil, err
+	}
+	if err == nil && existing.ID != folder.ID {
+		return nil, ErrNameConflict
+	}
+
+	folder.Name = name
+	folder.ParentID = parentID
+	folder.UpdatedAt = time.Now()
+
+	if err := s.repo.Save(folder); err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// isSelfOrDescendant walks up from folderID and reports whether it reaches ancestorID
+func (s *Service) isSelfOrDescendant(folderID, ancestorID string) (bool, error) {
+	visited := make(map[string]bool)
+
+	for folderID != "" {
+		if folderID == ancestorID {
+			return true, nil
+		}
+
+		// Guard against corrupted hierarchies that already contain a cycle
+		if visited[folderID] {
+			return false, ErrFolderCycle
+		}
+		visited[folderID] = true
+
+		current, err := s.repo.FindByID(folderID)
+		if err != nil {
+			return false, err
+		}
+		folderID = current.ParentID
+	}
+
+	return false, nil
+}
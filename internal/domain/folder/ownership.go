@@ -0,0 +1,11 @@
+package folder
+
+import "easy-storage/internal/domain/common"
+
+// FreeName returns name, or name with a " (n)" suffix, so it is not taken in a parent folder of a user
+// An empty parentID is the root folder
+func (s *Service) FreeName(userID, parentID, name string) (string, error) {
+	return common.UniqueName(name, func(candidate string) (bool, error) {
+		return s.nameTaken(userID, parentID, candidate)
+	})
+}
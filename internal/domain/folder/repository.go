@@ -2,11 +2,15 @@ package folder
 
 import (
 	"errors"
+	"time"
 )
 
 var (
 	ErrFolderNotFound = errors.New("folder not found")
 	ErrInvalidParent  = errors.New("invalid parent folder")
+	ErrInvalidName    = errors.New("invalid folder name")
+	ErrNameConflict   = errors.New("a folder with this name already exists in the parent folder")
+	ErrFolderCycle    = errors.New("cannot move a folder into itself or one of its subfolders")
 )
 
 // Repository defines the interface for folder data access
@@ -20,4 +24,20 @@ type Repository interface {
 	FindByUserAndParentPaginated(userID string, parentID string, page, pageSize int) ([]Folder, int64, error)
 	// FindAllByUserPaginated returns all folders for a user with pagination
 	FindAllByUserPaginated(userID string, page, pageSize int) ([]Folder, int64, error)
+	// FindByName finds the folder with the given name in a parent folder, an empty parentID is the root folder
+	FindByName(userID, parentID, name string) (*Folder, error)
+
+	// Trash moves a folder to the trash, trashedWith is the ID of the folder whose deletion trashed it
+	Trash(id, trashedWith string) error
+	// FindTrashed finds the folders a user moved to the trash themselves
+	FindTrashed(userID string) ([]*Folder, error)
+	// FindTrashedBefore finds folders moved to the trash themselves before the given time
+	FindTrashedBefore(before time.Time) ([]*Folder, error)
+	// FindTrashedWith finds the subfolders trashed along with a folder
+	FindTrashedWith(folderID string) ([]*Folder, error)
+	FindTrashedByID(id string) (*Folder, error)
+	// Restore takes a folder out of the trash into the given parent under the given name
+	Restore(id, parentID, name string) error
+	// Purge permanently deletes a folder
+	Purge(id string) error
 }
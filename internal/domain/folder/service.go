@@ -115,7 +115,7 @@ func (s *Service) GetFolderContents(folderID string, userID string) ([]Folder, [
 	return folders, files, nil
 }
 
-// DeleteFolder deletes a folder and all its contents (files and subfolders)
+// DeleteFolder moves a folder and all its contents (files and subfolders) to the trash
 func (s *Service) DeleteFolder(folderID, userID string) error {
 	// Check if folder exists and belongs to the user
 	belongs, err := s.BelongsToUser(folderID, userID)
@@ -132,27 +132,59 @@ func (s *Service) DeleteFolder(folderID, userID string) error {
 		return err
 	}
 
-	// Delete all files in the folder and subfolders
-	for _, subfolder := range append(subfolders, folderID) {
-		if err := s.fileService.DeleteByFolder(userID, subfolder); err != nil {
+	// Trash all files in the folder and subfolders along with the folder
+	if err := s.fileService.TrashByFolder(folderID, folderID); err != nil {
+		return err
+	}
+	for _, subfolder := range subfolders {
+		if err := s.fileService.TrashByFolder(subfolder.ID, folderID); err != nil {
 			return err
 		}
 	}
 
-	// Delete all subfolders
+	// Trash all subfolders along with the folder
 	for _, subfolder := range subfolders {
-		if err := s.repo.Delete(subfolder); err != nil {
+		if err := s.repo.Trash(subfolder.ID, folderID); err != nil {
 			return err
 		}
 	}
 
-	// Delete the main folder
-	return s.repo.Delete(folderID)
+	// Trash the main folder
+	return s.repo.Trash(folderID, "")
+}
+
+// IsWithin reports whether a folder is the given ancestor folder or one of its descendants
+func (s *Service) IsWithin(folderID, ancestorID string) (bool, error) {
+	return s.isSelfOrDescendant(folderID, ancestorID)
+}
+
+// Ancestors lists a folder and the folders containing it, from the folder up to a root-level folder
+func (s *Service) Ancestors(folderID string) ([]*Folder, error) {
+	var ancestors []*Folder
+	visited := make(map[string]bool)
+
+	for folderID != "" {
+		// Guard against corrupted hierarchies that already contain a cycle
+		if visited[folderID] {
+			return nil, ErrFolderCycle
+		}
+		visited[folderID] = true
+
+		current, err := s.repo.FindByID(folderID)
+		if err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, current)
+		folderID = current.ParentID
+	}
+
+	return ancestors, nil
 }
 
-// getAllSubfolders recursively gets all subfolder IDs for a given folder
-func (s *Service) getAllSubfolders(userID, folderID string) ([]string, error) {
-	var result []string
+// getAllSubfolders recursively gets all subfolders of a given folder
+// Each folder is listed before its own subfolders
+func (s *Service) getAllSubfolders(userID, folderID string) ([]Folder, error) {
+	var result []Folder
 
 	// Get direct children
 	folders, err := s.repo.FindByUserAndParent(userID, folderID)
@@ -162,8 +194,8 @@ func (s *Service) getAllSubfolders(userID, folderID string) ([]string, error) {
 
 	// For each child folder
 	for _, folder := range folders {
-		// Add the folder ID to the result
-		result = append(result, folder.ID)
+		// Add the folder to the result
+		result = append(result, folder)
 
 		// Get all subfolders recursively
 		subfolders, err := s.getAllSubfolders(userID, folder.ID)
@@ -0,0 +1,98 @@
+package folder
+
+import (
+	"time"
+
+	"easy-storage/internal/domain/common"
+)
+
+// ListTrashedFolders lists the folders a user moved to the trash
+// Subfolders trashed along with a folder are restored and purged with it
+func (s *Service) ListTrashedFolders(userID string) ([]*Folder, error) {
+	return s.repo.FindTrashed(userID)
+}
+
+// ListTrashedFoldersBefore lists the folders moved to the trash before the given time
+func (s *Service) ListTrashedFoldersBefore(before time.Time) ([]*Folder, error) {
+	return s.repo.FindTrashedBefore(before)
+}
+
+// GetTrashedFolder retrieves a folder in the trash by ID
+func (s *Service) GetTrashedFolder(id string) (*Folder, error) {
+	return s.repo.FindTrashedByID(id)
+}
+
+// RestoreFolder takes a folder and the subtree trashed with it out of the trash
+// The folder goes back to its parent, or to the root folder if the parent is gone,
+// and is renamed if its name has been taken in the meantime
+func (s *Service) RestoreFolder(folder *Folder) (*Folder, error) {
+	parentID := folder.ParentID
+	if parentID != "" {
+		belongs, err := s.repo.BelongsToUser(parentID, folder.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !belongs {
+			parentID = ""
+		}
+	}
+
+	name, err := common.UniqueName(folder.Name, func(candidate string) (bool, error) {
+		return s.nameTaken(folder.UserID, parentID, candidate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(folder.ID, parentID, name); err != nil {
+		return nil, err
+	}
+
+	// Restore the subtree as it was when the folder was trashed
+	subfolders, err := s.repo.FindTrashedWith(folder.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, subfolder := range subfolders {
+		if err := s.repo.Restore(subfolder.ID, subfolder.ParentID, subfolder.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.fileService.RestoreTrashedWith(folder.ID); err != nil {
+		return nil, err
+	}
+
+	folder.ParentID = parentID
+	folder.Name = name
+	folder.TrashedAt = time.Time{}
+	return folder, nil
+}
+
+// PurgeFolder permanently deletes a folder in the trash and the subtree trashed with it
+func (s *Service) PurgeFolder(folder *Folder) error {
+	if err := s.fileService.PurgeTrashedWith(folder.ID); err != nil {
+		return err
+	}
+
+	subfolders, err := s.repo.FindTrashedWith(folder.ID)
+	if err != nil {
+		return err
+	}
+	for _, subfolder := range subfolders {
+		if err := s.repo.Purge(subfolder.ID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Purge(folder.ID)
+}
+
+// nameTaken checks if a parent folder already contains a folder with the given name
+func (s *Service) nameTaken(userID, parentID, name string) (bool, error) {
+	_, err := s.repo.FindByName(userID, parentID, name)
+	if err == ErrFolderNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
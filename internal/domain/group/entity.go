@@ -0,0 +1,64 @@
+package group
+
+import (
+	"time"
+)
+
+// Role defines what a member can do in a group
+type Role string
+
+const (
+	// RoleOwner members manage the group and its members
+	RoleOwner Role = "owner"
+	// RoleMember members get access to what is shared with the group
+	RoleMember Role = "member"
+)
+
+// IsValid checks if the role is a known role
+func (r Role) IsValid() bool {
+	return r == RoleOwner || r == RoleMember
+}
+
+// Group represents a team of users that resources can be shared with
+type Group struct {
+	ID        string
+	Name      string
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Member represents the membership of a user in a group
+type Member struct {
+	GroupID  string
+	UserID   string
+	Role     Role
+	JoinedAt time.Time
+}
+
+// NewGroup creates a new group entity
+func NewGroup(name, createdBy string) *Group {
+	now := time.Now()
+	return &Group{
+		Name:      name,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewMember creates a new membership of a user in a group
+func NewMember(groupID, userID string, role Role) *Member {
+	return &Member{
+		GroupID:  groupID,
+		UserID:   userID,
+		Role:     role,
+		JoinedAt: time.Now(),
+	}
+}
+
+// Rename changes the name of the group
+func (g *Group) Rename(name string) {
+	g.Name = name
+	g.UpdatedAt = time.Now()
+}
@@ -0,0 +1,13 @@
+package group
+
+import "errors"
+
+var (
+	ErrGroupNotFound  = errors.New("group not found")
+	ErrInvalidName    = errors.New("invalid group name")
+	ErrInvalidRole    = errors.New("invalid group role")
+	ErrAlreadyMember  = errors.New("user is already a member of the group")
+	ErrNotGroupOwner  = errors.New("only group owners can manage the group")
+	ErrLastOwner      = errors.New("a group must keep at least one owner")
+	ErrMemberNotFound = errors.New("group member not found")
+)
@@ -0,0 +1,23 @@
+package group
+
+// Repository defines the interface for group data access
+type Repository interface {
+	// Create stores a new group along with its first owner
+	Create(group *Group, owner *Member) error
+	FindByID(id string) (*Group, error)
+	// FindByMember finds the groups a user is a member of
+	FindByMember(userID string) ([]*Group, error)
+	Update(group *Group) error
+	// Delete removes a group and all its memberships
+	Delete(id string) error
+
+	AddMember(member *Member) error
+	FindMember(groupID, userID string) (*Member, error)
+	FindMembers(groupID string) ([]*Member, error)
+	UpdateMember(member *Member) error
+	RemoveMember(groupID, userID string) error
+	// CountOwners counts the owners of a group
+	CountOwners(groupID string) (int64, error)
+	// FindGroupIDsByMember finds the IDs of the groups a user is a member of
+	FindGroupIDsByMember(userID string) ([]string, error)
+}
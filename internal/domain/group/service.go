@@ -0,0 +1,212 @@
+package group
+
+import (
+	"strings"
+
+	"easy-storage/internal/domain/common"
+)
+
+// Service provides group-related operations
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new group service
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// CreateGroup creates a group owned by its creator
+func (s *Service) CreateGroup(name, creatorID string) (*Group, error) {
+	name = strings.TrimSpace(name)
+	if !common.IsValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	group := NewGroup(name, creatorID)
+	if err := s.repo.Create(group, NewMember("", creatorID, RoleOwner)); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// GetGroup retrieves a group the user is a member of
+func (s *Service) GetGroup(groupID, userID string) (*Group, error) {
+	if _, err := s.requireMember(groupID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(groupID)
+}
+
+// ListUserGroups lists the groups a user is a member of
+func (s *Service) ListUserGroups(userID string) ([]*Group, error) {
+	return s.repo.FindByMember(userID)
+}
+
+// RenameGroup renames a group, only owners can rename it
+func (s *Service) RenameGroup(groupID, userID, name string) (*Group, error) {
+	name = strings.TrimSpace(name)
+	if !common.IsValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	if err := s.requireOwner(groupID, userID); err != nil {
+		return nil, err
+	}
+
+	group, err := s.repo.FindByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Rename(name)
+	if err := s.repo.Update(group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// DeleteGroup deletes a group and its memberships, only owners can delete it
+// Shares with the group stop granting access as the group has no members anymore
+func (s *Service) DeleteGroup(groupID, userID string) error {
+	if err := s.requireOwner(groupID, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(groupID)
+}
+
+// ListMembers lists the members of a group the user is a member of
+func (s *Service) ListMembers(groupID, userID string) ([]*Member, error) {
+	if _, err := s.requireMember(groupID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindMembers(groupID)
+}
+
+// AddMember adds a user to a group, only owners can add members
+func (s *Service) AddMember(groupID, actorID, userID string, role Role) (*Member, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	if err := s.requireOwner(groupID, actorID); err != nil {
+		return nil, err
+	}
+
+	// Check if the user is already a member
+	if _, err := s.repo.FindMember(groupID, userID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if err != ErrMemberNotFound {
+		return nil, err
+	}
+
+	member := NewMember(groupID, userID, role)
+	if err := s.repo.AddMember(member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// UpdateMemberRole changes the role of a member, only owners can change roles
+func (s *Service) UpdateMemberRole(groupID, actorID, userID string, role Role) (*Member, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	if err := s.requireOwner(groupID, actorID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.FindMember(groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.Role == RoleOwner && role != RoleOwner {
+		if err := s.ensureAnotherOwner(groupID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = role
+	if err := s.repo.UpdateMember(member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// RemoveMember removes a user from a group
+// Owners can remove any member, members can only remove themselves to leave the group
+func (s *Service) RemoveMember(groupID, actorID, userID string) error {
+	if actorID != userID {
+		if err := s.requireOwner(groupID, actorID); err != nil {
+			return err
+		}
+	}
+
+	member, err := s.repo.FindMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == RoleOwner {
+		if err := s.ensureAnotherOwner(groupID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RemoveMember(groupID, userID)
+}
+
+// IsMember checks if a user is a member of a group
+func (s *Service) IsMember(groupID, userID string) (bool, error) {
+	_, err := s.repo.FindMember(groupID, userID)
+	if err == ErrMemberNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GroupIDsOf lists the IDs of the groups a user is a member of
+func (s *Service) GroupIDsOf(userID string) ([]string, error) {
+	return s.repo.FindGroupIDsByMember(userID)
+}
+
+// requireMember gets the membership of a user, reporting groups they are not in as not found
+func (s *Service) requireMember(groupID, userID string) (*Member, error) {
+	member, err := s.repo.FindMember(groupID, userID)
+	if err == ErrMemberNotFound {
+		return nil, ErrGroupNotFound
+	}
+	return member, err
+}
+
+// requireOwner checks that a user owns a group
+func (s *Service) requireOwner(groupID, userID string) error {
+	member, err := s.requireMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role != RoleOwner {
+		return ErrNotGroupOwner
+	}
+	return nil
+}
+
+// ensureAnotherOwner checks that a group keeps an owner when one of its owners goes away
+func (s *Service) ensureAnotherOwner(groupID string) error {
+	owners, err := s.repo.CountOwners(groupID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
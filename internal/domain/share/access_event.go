@@ -0,0 +1,107 @@
+package share
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessAction is what a visitor tried to do through a share
+type AccessAction string
+
+const (
+	// ActionView is viewing a share or listing a shared folder
+	ActionView AccessAction = "view"
+	// ActionDownload is downloading a shared file, a file of a shared folder or a folder archive
+	ActionDownload AccessAction = "download"
+	// ActionUpload is uploading into a shared folder
+	ActionUpload AccessAction = "upload"
+)
+
+// AccessOutcome is the result of an access to a share
+type AccessOutcome string
+
+const (
+	// OutcomeSuccess means the access was granted
+	OutcomeSuccess AccessOutcome = "success"
+	// OutcomePasswordRequired means the share asked for its password
+	OutcomePasswordRequired AccessOutcome = "password_required"
+	// OutcomeInvalidPassword means a wrong password was sent
+	OutcomeInvalidPassword AccessOutcome = "invalid_password"
+	// OutcomeLockedOut means the share or the client was locked out after too many wrong passwords
+	OutcomeLockedOut AccessOutcome = "locked_out"
+	// OutcomeRevoked means the share was revoked
+	OutcomeRevoked AccessOutcome = "revoked"
+	// OutcomeExpired means the share had expired
+	OutcomeExpired AccessOutcome = "expired"
+	// OutcomeLimitReached means the share had used all its downloads
+	OutcomeLimitReached AccessOutcome = "limit_reached"
+)
+
+// Visitor identifies who accesses a share
+type Visitor struct {
+	IP        string
+	UserAgent string
+	UserID    *uuid.UUID // Set when the visitor is authenticated
+}
+
+// AccessEvent records a single access to a share
+type AccessEvent struct {
+	ID         uuid.UUID     `json:"id"`
+	ShareID    uuid.UUID     `json:"share_id"`
+	Action     AccessAction  `json:"action"`
+	Outcome    AccessOutcome `json:"outcome"`
+	IP         string        `json:"ip"`
+	UserAgent  string        `json:"user_agent"`
+	UserID     *uuid.UUID    `json:"user_id,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// NewAccessEvent creates a new access event of a share
+func NewAccessEvent(shareID uuid.UUID, visitor Visitor, action AccessAction, outcome AccessOutcome) *AccessEvent {
+	return &AccessEvent{
+		ID:         uuid.New(),
+		ShareID:    shareID,
+		Action:     action,
+		Outcome:    outcome,
+		IP:         visitor.IP,
+		UserAgent:  visitor.UserAgent,
+		UserID:     visitor.UserID,
+		OccurredAt: time.Now(),
+	}
+}
+
+// AccessStats aggregates the access events of a share
+type AccessStats struct {
+	TotalEvents    int64      `json:"total_events"`
+	Views          int64      `json:"views"`                     // Granted views
+	Downloads      int64      `json:"downloads"`                 // Granted downloads
+	Uploads        int64      `json:"uploads"`                   // Granted uploads
+	FailedAttempts int64      `json:"failed_attempts"`           // Accesses that were not granted
+	UniqueVisitors int64      `json:"unique_visitors"`           // Distinct IPs of granted accesses
+	FirstAccessAt  *time.Time `json:"first_access_at,omitempty"` // First granted access
+	LastAccessAt   *time.Time `json:"last_access_at,omitempty"`  // Last granted access
+}
+
+// outcomeOf maps an access error to the outcome recorded for it
+func outcomeOf(err error, password string) (AccessOutcome, bool) {
+	switch err {
+	case nil:
+		return OutcomeSuccess, true
+	case ErrInvalidPassword:
+		if password == "" {
+			return OutcomePasswordRequired, true
+		}
+		return OutcomeInvalidPassword, true
+	case ErrTooManyAttempts:
+		return OutcomeLockedOut, true
+	case ErrShareRevoked:
+		return OutcomeRevoked, true
+	case ErrShareExpired:
+		return OutcomeExpired, true
+	case ErrDownloadLimitReached:
+		return OutcomeLimitReached, true
+	default:
+		return "", false
+	}
+}
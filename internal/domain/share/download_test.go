@@ -0,0 +1,165 @@
+package share_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-storage/internal/domain/share"
+
+	"github.com/google/uuid"
+)
+
+// newLimitedShare creates a link share allowing a number of downloads
+func newLimitedShare(t *testing.T, service *share.Service, maxDownloads int, burnAfterReading bool) *share.Share {
+	t.Helper()
+
+	ctx := context.Background()
+	shareObj, err := service.CreateLinkShare(ctx, uuid.New(), uuid.New(), "file", share.Viewer)
+	if err != nil {
+		t.Fatalf("CreateLinkShare: %v", err)
+	}
+	if err := service.SetShareDownloadLimit(ctx, shareObj.ID, maxDownloads, burnAfterReading); err != nil {
+		t.Fatalf("SetShareDownloadLimit: %v", err)
+	}
+
+	limited, err := service.GetShareByID(ctx, shareObj.ID)
+	if err != nil {
+		t.Fatalf("GetShareByID: %v", err)
+	}
+	return limited
+}
+
+func TestConsumeDownloadConcurrentRequestsStayWithinLimit(t *testing.T) {
+	service, repo, _ := newTestService(share.PasswordLimits{})
+	shareObj := newLimitedShare(t, service, 3, false)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	granted, refused := 0, 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Every request read the share before any download was counted
+			stale := *shareObj
+			err := service.ConsumeDownload(ctx, &stale, share.Visitor{})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch err {
+			case nil:
+				granted++
+			case share.ErrDownloadLimitReached:
+				refused++
+			default:
+				t.Errorf("ConsumeDownload error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if granted != 3 || refused != 17 {
+		t.Errorf("granted %d and refused %d downloads, want 3 and 17", granted, refused)
+	}
+
+	stored, _ := repo.GetByID(ctx, shareObj.ID)
+	if stored.DownloadCount != 3 || !stored.IsRevoked {
+		t.Errorf("stored share has %d downloads, revoked %v, want 3 and revoked", stored.DownloadCount, stored.IsRevoked)
+	}
+}
+
+func TestBurnAfterReading(t *testing.T) {
+	service, _, events := newTestService(share.PasswordLimits{})
+	shareObj := newLimitedShare(t, service, 5, true)
+	ctx := context.Background()
+
+	if shareObj.MaxDownloads != 1 {
+		t.Fatalf("burn after reading allows %d downloads, want 1", shareObj.MaxDownloads)
+	}
+
+	accessed, err := service.GetResourceByToken(ctx, shareObj.Token, "", share.Visitor{}, share.ActionDownload)
+	if err != nil {
+		t.Fatalf("GetResourceByToken: %v", err)
+	}
+	if err := service.ConsumeDownload(ctx, accessed, share.Visitor{}); err != nil {
+		t.Fatalf("ConsumeDownload: %v", err)
+	}
+	if !accessed.IsRevoked || accessed.RemainingDownloads() != 0 {
+		t.Error("the share was not revoked by its only download")
+	}
+
+	if _, err := service.GetResourceByToken(ctx, shareObj.Token, "", share.Visitor{}, share.ActionDownload); err != share.ErrDownloadLimitReached {
+		t.Errorf("second access error = %v, want ErrDownloadLimitReached", err)
+	}
+
+	got := events.Outcomes(shareObj.ID)
+	if len(got) != 2 || got[0] != share.OutcomeSuccess || got[1] != share.OutcomeLimitReached {
+		t.Errorf("recorded outcomes %v, want success then limit_reached", got)
+	}
+}
+
+func TestConsumeDownloadRevokedShare(t *testing.T) {
+	service, _, _ := newTestService(share.PasswordLimits{})
+	ctx := context.Background()
+	shareObj, err := service.CreateLinkShare(ctx, uuid.New(), uuid.New(), "file", share.Viewer)
+	if err != nil {
+		t.Fatalf("CreateLinkShare: %v", err)
+	}
+
+	if err := service.RevokeShare(ctx, shareObj.ID); err != nil {
+		t.Fatalf("RevokeShare: %v", err)
+	}
+	if err := service.ConsumeDownload(ctx, shareObj, share.Visitor{}); err != share.ErrShareRevoked {
+		t.Errorf("ConsumeDownload error = %v, want ErrShareRevoked", err)
+	}
+}
+
+func TestSettingsUpdateKeepsDownloadRevocation(t *testing.T) {
+	service, repo, _ := newTestService(share.PasswordLimits{})
+	shareObj := newLimitedShare(t, service, 1, false)
+	ctx := context.Background()
+
+	if err := service.ConsumeDownload(ctx, shareObj, share.Visitor{}); err != nil {
+		t.Fatalf("ConsumeDownload: %v", err)
+	}
+
+	// Changing a setting afterwards must not bring the used share back
+	if err := service.SetShareExpiration(ctx, shareObj.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("SetShareExpiration: %v", err)
+	}
+
+	stored, _ := repo.GetByID(ctx, shareObj.ID)
+	if !stored.IsRevoked || stored.DownloadCount != 1 {
+		t.Errorf("stored share has %d downloads, revoked %v, want 1 and revoked", stored.DownloadCount, stored.IsRevoked)
+	}
+}
+
+func TestRemainingDownloads(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxDownloads  int
+		downloadCount int
+		wantRemaining int
+		wantLimited   bool
+	}{
+		{name: "unlimited", maxDownloads: 0, downloadCount: 7, wantRemaining: -1, wantLimited: false},
+		{name: "downloads left", maxDownloads: 3, downloadCount: 1, wantRemaining: 2, wantLimited: true},
+		{name: "limit reached", maxDownloads: 3, downloadCount: 3, wantRemaining: 0, wantLimited: true},
+		{name: "over the limit", maxDownloads: 3, downloadCount: 4, wantRemaining: 0, wantLimited: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shareObj := &share.Share{MaxDownloads: tt.maxDownloads, DownloadCount: tt.downloadCount}
+			if got := shareObj.RemainingDownloads(); got != tt.wantRemaining {
+				t.Errorf("RemainingDownloads = %d, want %d", got, tt.wantRemaining)
+			}
+			if got := shareObj.IsDownloadLimited(); got != tt.wantLimited {
+				t.Errorf("IsDownloadLimited = %v, want %v", got, tt.wantLimited)
+			}
+		})
+	}
+}
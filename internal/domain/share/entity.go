@@ -14,16 +14,8 @@ const (
 	LinkShare ShareType = "LINK"
 	// UserShare represents sharing directly with another user
 	UserShare ShareType = "USER"
-)
-
-// SharePermission defines the permission level for a share
-type SharePermission string
-
-const (
-	// ReadOnly permission allows only viewing files
-	ReadOnly SharePermission = "READ"
-	// ReadWrite permission allows viewing and modifying files
-	ReadWrite SharePermission = "WRITE"
+	// GroupShare represents sharing with every member of a group
+	GroupShare ShareType = "GROUP"
 )
 
 // Share represents a sharing entity in the system
@@ -35,14 +27,26 @@ type Share struct {
 	Type         ShareType       `json:"type"`
 	Permission   SharePermission `json:"permission"`
 	RecipientID  *uuid.UUID      `json:"recipient_id,omitempty"` // Only for UserShare
+	GroupID      *uuid.UUID      `json:"group_id,omitempty"`     // Only for GroupShare
 	Token        string          `json:"token,omitempty"`        // For LinkShare
-	Password     *string         `json:"password,omitempty"`     // Optional password protection
+	Password     *string         `json:"-"`                      // Optional password protection, bcrypt hash
 	ExpiresAt    *time.Time      `json:"expires_at,omitempty"`   // Optional expiration
 	CreatedAt    time.Time       `json:"created_at"`
 	UpdatedAt    time.Time       `json:"updated_at"`
 	AccessCount  int             `json:"access_count"` // Track number of accesses
 	LastAccessAt *time.Time      `json:"last_access_at,omitempty"`
 	IsRevoked    bool            `json:"is_revoked"`
+	UploadOnly   bool            `json:"upload_only"`   // Uploaders cannot see the folder contents
+	MaxFiles     int             `json:"max_files"`     // Maximum number of uploads, 0 for unlimited
+	MaxFileSize  int64           `json:"max_file_size"` // Maximum size of an upload in bytes, 0 for unlimited
+	UploadCount  int             `json:"upload_count"`  // Number of files uploaded through the share
+
+	MaxDownloads     int  `json:"max_downloads"`      // Downloads allowed before the share is revoked, 0 for unlimited
+	DownloadCount    int  `json:"download_count"`     // Number of downloads through the share
+	BurnAfterReading bool `json:"burn_after_reading"` // The share is revoked after its first download
+
+	RecipientEmail  string `json:"recipient_email,omitempty"` // Invited email of a UserShare, the recipient is set once the invitation is claimed
+	InviteTokenHash string `json:"-"`                         // Hash of the token emailed to a pending invitation, cleared once claimed
 }
 
 // NewShare creates a new share entity
@@ -67,9 +71,16 @@ func NewShare(
 	}
 }
 
+// SetPermission changes the role the share gives its recipients
+func (s *Share) SetPermission(permission SharePermission) {
+	s.Permission = permission
+	s.UpdatedAt = time.Now()
+}
+
 // SetPassword adds password protection to the share
-func (s *Share) SetPassword(password string) {
-	s.Password = &password
+// The password is stored as the given bcrypt hash
+func (s *Share) SetPassword(passwordHash string) {
+	s.Password = &passwordHash
 	s.UpdatedAt = time.Now()
 }
 
@@ -85,12 +96,81 @@ func (s *Share) SetRecipient(recipientID uuid.UUID) {
 	s.UpdatedAt = time.Now()
 }
 
+// SetRecipientEmail invites an email without account to a user share
+// The share gets its recipient when the invitation sent to the email is claimed
+func (s *Share) SetRecipientEmail(email string) {
+	s.RecipientEmail = email
+	s.UpdatedAt = time.Now()
+}
+
+// SetInviteToken sets the hash of the token the invited email claims the share with
+func (s *Share) SetInviteToken(tokenHash string) {
+	s.InviteTokenHash = tokenHash
+	s.UpdatedAt = time.Now()
+}
+
+// IsPendingInvitation checks if the share waits for its invitation to be claimed
+func (s *Share) IsPendingInvitation() bool {
+	return s.Type == UserShare && s.RecipientID == nil && s.RecipientEmail != ""
+}
+
+// SetGroup sets the recipient group for group sharing
+func (s *Share) SetGroup(groupID uuid.UUID) {
+	s.GroupID = &groupID
+	s.UpdatedAt = time.Now()
+}
+
 // SetToken sets the access token for link sharing
 func (s *Share) SetToken(token string) {
 	s.Token = token
 	s.UpdatedAt = time.Now()
 }
 
+// SetUploadOptions configures uploads into a shared folder
+func (s *Share) SetUploadOptions(uploadOnly bool, maxFiles int, maxFileSize int64) {
+	s.UploadOnly = uploadOnly
+	s.MaxFiles = maxFiles
+	s.MaxFileSize = maxFileSize
+	s.UpdatedAt = time.Now()
+}
+
+// SetDownloadLimit limits the number of downloads through a link share
+// Burn after reading allows a single download
+func (s *Share) SetDownloadLimit(maxDownloads int, burnAfterReading bool) {
+	if burnAfterReading {
+		maxDownloads = 1
+	}
+	s.MaxDownloads = maxDownloads
+	s.BurnAfterReading = burnAfterReading
+	s.UpdatedAt = time.Now()
+}
+
+// IsDownloadLimited checks if the share allows a limited number of downloads
+func (s *Share) IsDownloadLimited() bool {
+	return s.MaxDownloads > 0
+}
+
+// RemainingDownloads returns the number of downloads left, or -1 for unlimited shares
+func (s *Share) RemainingDownloads() int {
+	if s.MaxDownloads == 0 {
+		return -1
+	}
+	if s.DownloadCount >= s.MaxDownloads {
+		return 0
+	}
+	return s.MaxDownloads - s.DownloadCount
+}
+
+// IsDownloadLimitReached checks if all the downloads allowed by the share were used
+func (s *Share) IsDownloadLimitReached() bool {
+	return s.RemainingDownloads() == 0
+}
+
+// AllowsUpload checks if files can be uploaded through this share
+func (s *Share) AllowsUpload() bool {
+	return s.Permission.Has(CapUpload) && s.ResourceType == "folder"
+}
+
 // Revoke revokes access to this share
 func (s *Share) Revoke() {
 	s.IsRevoked = true
@@ -15,9 +15,42 @@ var (
 	// ErrInvalidPassword is returned when an incorrect password is provided
 	ErrInvalidPassword = errors.New("invalid share password")
 
+	// ErrTooManyAttempts is returned when a share or client is locked out after too many wrong passwords
+	ErrTooManyAttempts = errors.New("too many failed password attempts")
+
+	// ErrDownloadLimitReached is returned when all the downloads allowed by a share were used
+	ErrDownloadLimitReached = errors.New("share download limit reached")
+
 	// ErrInvalidShareType is returned when an operation is attempted on the wrong share type
 	ErrInvalidShareType = errors.New("invalid share type for this operation")
 
+	// ErrInvalidGroup is returned when sharing with a group the owner is not a member of
+	ErrInvalidGroup = errors.New("group not found or not a member")
+
+	// ErrInvalidEmail is returned when inviting an invalid email address
+	ErrInvalidEmail = errors.New("invalid email address")
+
+	// ErrInvitationNotFound is returned when an invite token is unknown or its invitation was already claimed
+	ErrInvitationNotFound = errors.New("invitation not found or already claimed")
+
+	// ErrInvalidRecipient is returned when the owner of a resource shares it with themselves
+	ErrInvalidRecipient = errors.New("cannot share with yourself")
+
 	// ErrUnauthorizedAccess is returned when a user attempts to access a share they don't have permission for
 	ErrUnauthorizedAccess = errors.New("unauthorized access to share")
+
+	// ErrInvalidPermission is returned when a share is created with an unknown role
+	ErrInvalidPermission = errors.New("invalid share permission")
+
+	// ErrReadOnlyShare is returned when uploading through a share whose role cannot upload
+	ErrReadOnlyShare = errors.New("share does not allow uploads")
+
+	// ErrUploadOnly is returned when listing or downloading through an upload-only share
+	ErrUploadOnly = errors.New("share only allows uploads")
+
+	// ErrUploadLimitReached is returned when a share has received its maximum number of files
+	ErrUploadLimitReached = errors.New("share upload limit reached")
+
+	// ErrUploadTooLarge is returned when a file exceeds the maximum upload size of a share
+	ErrUploadTooLarge = errors.New("file exceeds the share's maximum upload size")
 )
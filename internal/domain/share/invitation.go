@@ -0,0 +1,155 @@
+package share
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/mail"
+	"strings"
+
+	"easy-storage/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+// Invitation tells the recipient of a share made by email what was shared with them
+type Invitation struct {
+	ShareID      uuid.UUID
+	Email        string
+	InviterName  string
+	InviterEmail string
+	ResourceName string
+	ResourceType string
+	Permission   SharePermission
+	Registered   bool   // False when the recipient has to claim the invitation to open the share
+	Token        string // Claims a pending invitation, only sent to the invited email
+}
+
+// Notifier delivers share invitations
+type Notifier interface {
+	NotifyInvitation(ctx context.Context, invitation Invitation) error
+}
+
+// InviteByEmail shares a resource with the user registered with an email
+// When no user has the email yet, the share stays pending until it is claimed with the invite token
+// emailed to the address, so only someone reading that mailbox gets the share
+// The recipient is notified either way, a failed delivery does not undo the share
+func (s *Service) InviteByEmail(
+	ctx context.Context,
+	ownerID uuid.UUID,
+	resourceID uuid.UUID,
+	resourceType string,
+	resourceName string,
+	email string,
+	permission SharePermission,
+) (*Share, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.users.FindByID(ownerID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	share := NewShare(ownerID, resourceID, resourceType, UserShare, permission)
+
+	var inviteToken string
+	recipient, err := s.users.FindByEmail(email)
+	switch {
+	case err == nil:
+		if recipient.ID == owner.ID {
+			return nil, ErrInvalidRecipient
+		}
+		recipientID, err := uuid.Parse(recipient.ID)
+		if err != nil {
+			return nil, err
+		}
+		share.SetRecipient(recipientID)
+	case errors.Is(err, user.ErrUserNotFound):
+		if strings.EqualFold(email, owner.Email) {
+			return nil, ErrInvalidRecipient
+		}
+		inviteToken, err = generateSecureToken(32)
+		if err != nil {
+			return nil, err
+		}
+		share.SetRecipientEmail(email)
+		share.SetInviteToken(hashInviteToken(inviteToken))
+	default:
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, share); err != nil {
+		return nil, err
+	}
+
+	invitation := Invitation{
+		ShareID:      share.ID,
+		Email:        email,
+		InviterName:  owner.Name,
+		InviterEmail: owner.Email,
+		ResourceName: resourceName,
+		ResourceType: resourceType,
+		Permission:   permission,
+		Registered:   share.RecipientID != nil,
+		Token:        inviteToken,
+	}
+	if err := s.notifier.NotifyInvitation(ctx, invitation); err != nil {
+		log.Printf("Error sending share invitation %s to %s: %v", share.ID, email, err)
+	}
+
+	return share, nil
+}
+
+// ClaimInvitation gives a user the pending invitation of an invite token
+// The token is single use, the owner of the resource cannot claim their own invitation
+func (s *Service) ClaimInvitation(ctx context.Context, token string, userID uuid.UUID) (*Share, error) {
+	tokenHash := hashInviteToken(token)
+
+	share, err := s.repo.GetByInviteToken(ctx, tokenHash)
+	if err == ErrShareNotFound {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !share.IsPendingInvitation() {
+		return nil, ErrInvitationNotFound
+	}
+	if share.OwnerID == userID {
+		return nil, ErrInvalidRecipient
+	}
+
+	claimed, err := s.repo.ClaimInvitation(ctx, share.ID, tokenHash, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrInvitationNotFound
+	}
+
+	share.SetRecipient(userID)
+	share.InviteTokenHash = ""
+	return share, nil
+}
+
+// hashInviteToken hashes an invite token for storage
+// Tokens are long random values, a fast hash is enough to keep them unusable if the database leaks
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail checks that an email is a bare address
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
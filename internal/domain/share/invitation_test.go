@@ -0,0 +1,172 @@
+package share_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"easy-storage/internal/domain/share"
+	"easy-storage/internal/domain/share/sharetest"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/domain/user/usertest"
+
+	"github.com/google/uuid"
+)
+
+// recordingNotifier keeps the invitations it was asked to deliver
+type recordingNotifier struct {
+	mu          sync.Mutex
+	invitations []share.Invitation
+}
+
+func (n *recordingNotifier) NotifyInvitation(ctx context.Context, invitation share.Invitation) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.invitations = append(n.invitations, invitation)
+	return nil
+}
+
+func (n *recordingNotifier) last(t *testing.T) share.Invitation {
+	t.Helper()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.invitations) == 0 {
+		t.Fatal("no invitation was sent")
+	}
+	return n.invitations[len(n.invitations)-1]
+}
+
+func newInvitationService(t *testing.T) (*share.Service, *usertest.Repository, *recordingNotifier, *user.User) {
+	t.Helper()
+
+	users := usertest.NewRepository()
+	owner := user.NewUser("owner@example.com", "hash", "Owner")
+	if err := users.Save(owner); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	notifier := &recordingNotifier{}
+	service := share.NewService(sharetest.NewRepository(), &sharetest.EventRepository{}, nil, users, notifier, share.PasswordLimits{})
+	return service, users, notifier, owner
+}
+
+func newPendingInvitation(t *testing.T, service *share.Service, owner *user.User, email string) *share.Share {
+	t.Helper()
+
+	shareObj, err := service.InviteByEmail(context.Background(), uuid.MustParse(owner.ID), uuid.New(), "file", "report.pdf", email, share.Viewer)
+	if err != nil {
+		t.Fatalf("InviteByEmail: %v", err)
+	}
+	if !shareObj.IsPendingInvitation() {
+		t.Fatal("the invitation of an unknown email is not pending")
+	}
+	return shareObj
+}
+
+func TestInviteByEmailPendingInvitationSendsToken(t *testing.T) {
+	service, _, notifier, owner := newInvitationService(t)
+	shareObj := newPendingInvitation(t, service, owner, "Friend@Example.com")
+
+	invitation := notifier.last(t)
+	if invitation.Token == "" {
+		t.Fatal("the pending invitation was sent without a token")
+	}
+	if shareObj.InviteTokenHash == "" || shareObj.InviteTokenHash == invitation.Token {
+		t.Error("the invite token is not stored hashed")
+	}
+}
+
+func TestInviteByEmailRegisteredRecipientHasNoToken(t *testing.T) {
+	service, users, notifier, owner := newInvitationService(t)
+	if err := users.Save(user.NewUser("friend@example.com", "hash", "Friend")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	shareObj, err := service.InviteByEmail(context.Background(), uuid.MustParse(owner.ID), uuid.New(), "file", "report.pdf", "friend@example.com", share.Viewer)
+	if err != nil {
+		t.Fatalf("InviteByEmail: %v", err)
+	}
+	if shareObj.RecipientID == nil || shareObj.InviteTokenHash != "" {
+		t.Error("a registered recipient got a pending invitation")
+	}
+	if notifier.last(t).Token != "" {
+		t.Error("a registered recipient was sent an invite token")
+	}
+}
+
+func TestClaimInvitation(t *testing.T) {
+	service, _, notifier, owner := newInvitationService(t)
+	shareObj := newPendingInvitation(t, service, owner, "friend@example.com")
+	token := notifier.last(t).Token
+
+	// Whoever registers the invited email does not get the share without the token
+	if _, err := service.ClaimInvitation(context.Background(), "guessed-token", uuid.New()); err != share.ErrInvitationNotFound {
+		t.Fatalf("ClaimInvitation with a wrong token = %v, want ErrInvitationNotFound", err)
+	}
+
+	recipientID := uuid.New()
+	claimed, err := service.ClaimInvitation(context.Background(), token, recipientID)
+	if err != nil {
+		t.Fatalf("ClaimInvitation: %v", err)
+	}
+	if claimed.ID != shareObj.ID || claimed.RecipientID == nil || *claimed.RecipientID != recipientID {
+		t.Fatal("the invitation was not given to the user presenting the token")
+	}
+
+	stored, _ := service.GetShareByID(context.Background(), shareObj.ID)
+	if stored.IsPendingInvitation() || stored.InviteTokenHash != "" {
+		t.Error("the claimed invitation is still pending")
+	}
+
+	// The token works once
+	if _, err := service.ClaimInvitation(context.Background(), token, uuid.New()); err != share.ErrInvitationNotFound {
+		t.Errorf("second ClaimInvitation = %v, want ErrInvitationNotFound", err)
+	}
+}
+
+func TestClaimInvitationByOwner(t *testing.T) {
+	service, _, notifier, owner := newInvitationService(t)
+	newPendingInvitation(t, service, owner, "friend@example.com")
+
+	_, err := service.ClaimInvitation(context.Background(), notifier.last(t).Token, uuid.MustParse(owner.ID))
+	if err != share.ErrInvalidRecipient {
+		t.Errorf("ClaimInvitation by the owner = %v, want ErrInvalidRecipient", err)
+	}
+}
+
+func TestClaimInvitationConcurrentClaimsBindOnce(t *testing.T) {
+	service, _, notifier, owner := newInvitationService(t)
+	newPendingInvitation(t, service, owner, "friend@example.com")
+	token := notifier.last(t).Token
+
+	const claims = 10
+	var wg sync.WaitGroup
+	results := make(chan error, claims)
+	for i := 0; i < claims; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.ClaimInvitation(context.Background(), token, uuid.New())
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		switch err {
+		case nil:
+			succeeded++
+		case share.ErrInvitationNotFound:
+		default:
+			t.Fatalf("ClaimInvitation: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d claims succeeded, want 1", succeeded)
+	}
+}
@@ -0,0 +1,124 @@
+package share
+
+import "strings"
+
+// Capability is an action a share lets its recipients perform on a resource
+type Capability string
+
+const (
+	// CapView allows seeing a resource and listing the contents of a folder
+	CapView Capability = "view"
+	// CapDownload allows reading the content of files and their versions
+	CapDownload Capability = "download"
+	// CapComment allows commenting on files
+	CapComment Capability = "comment"
+	// CapUpload allows adding files, folders and versions
+	CapUpload Capability = "upload"
+	// CapRename allows renaming and moving files and folders
+	CapRename Capability = "rename"
+	// CapDelete allows moving files and folders to the trash and deleting versions
+	CapDelete Capability = "delete"
+	// CapReshare allows sharing a resource with other users, up to the role of the sharer
+	CapReshare Capability = "reshare"
+	// CapManage allows listing, updating and revoking the shares of a resource
+	CapManage Capability = "manage"
+)
+
+// SharePermission is the role a share gives its recipients
+// Each role includes the capabilities of the roles below it
+type SharePermission string
+
+const (
+	// Viewer can view and download
+	Viewer SharePermission = "viewer"
+	// Commenter can also comment
+	Commenter SharePermission = "commenter"
+	// Editor can also upload, rename, move and delete
+	Editor SharePermission = "editor"
+	// CoOwner can also reshare and manage the shares of the resource
+	CoOwner SharePermission = "co-owner"
+	// Owner is the implicit role of the owner of a resource, it is never stored on a share
+	Owner SharePermission = "owner"
+)
+
+// Roles lists the roles a share can give, from the lowest to the highest
+var Roles = []SharePermission{Viewer, Commenter, Editor, CoOwner}
+
+// roleCapabilities lists the capabilities each role adds to the role below it
+var roleCapabilities = map[SharePermission][]Capability{
+	Viewer:    {CapView, CapDownload},
+	Commenter: {CapComment},
+	Editor:    {CapUpload, CapRename, CapDelete},
+	CoOwner:   {CapReshare, CapManage},
+}
+
+// legacyPermissions maps the permissions used before roles to their role
+var legacyPermissions = map[string]SharePermission{
+	"READ":  Viewer,
+	"WRITE": Editor,
+}
+
+// ParsePermission parses a role name, READ and WRITE are accepted for viewer and editor
+func ParsePermission(value string) (SharePermission, error) {
+	if role, ok := legacyPermissions[strings.ToUpper(value)]; ok {
+		return role, nil
+	}
+
+	role := SharePermission(strings.ToLower(value))
+	if role.rank() == 0 || role == Owner {
+		return "", ErrInvalidPermission
+	}
+	return role, nil
+}
+
+// rank orders the roles, 0 for unknown ones
+func (p SharePermission) rank() int {
+	if p == Owner {
+		return len(Roles) + 1
+	}
+	for i, role := range Roles {
+		if role == p {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Grants checks if a role includes the capabilities of the required one
+func (p SharePermission) Grants(required SharePermission) bool {
+	return p.rank() > 0 && p.rank() >= required.rank()
+}
+
+// Has checks if a role allows a capability
+func (p SharePermission) Has(capability Capability) bool {
+	for _, granted := range p.Capabilities() {
+		if granted == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities lists the capabilities of a role
+func (p SharePermission) Capabilities() []Capability {
+	if p == Owner {
+		p = CoOwner
+	}
+
+	capabilities := []Capability{}
+	for _, role := range Roles {
+		if role.rank() > p.rank() {
+			break
+		}
+		capabilities = append(capabilities, roleCapabilities[role]...)
+	}
+	return capabilities
+}
+
+// Highest returns the highest of two roles, an empty role means no access
+func Highest(a, b SharePermission) SharePermission {
+	if b.rank() > a.rank() {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,85 @@
+package share_test
+
+import (
+	"testing"
+
+	"easy-storage/internal/domain/share"
+)
+
+// allCapabilities lists every capability, from the one given to the lowest role up
+var allCapabilities = []share.Capability{
+	share.CapView, share.CapDownload, share.CapComment, share.CapUpload,
+	share.CapRename, share.CapDelete, share.CapReshare, share.CapManage,
+}
+
+func TestPermissionHas(t *testing.T) {
+	// Number of capabilities of allCapabilities each role has, roles include the ones below them
+	granted := map[share.SharePermission]int{
+		"":              0,
+		"unknown":       0,
+		share.Viewer:    2,
+		share.Commenter: 3,
+		share.Editor:    6,
+		share.CoOwner:   8,
+		share.Owner:     8,
+	}
+
+	for role, count := range granted {
+		for i, capability := range allCapabilities {
+			want := i < count
+			t.Run(string(role)+"/"+string(capability), func(t *testing.T) {
+				if got := role.Has(capability); got != want {
+					t.Errorf("%q.Has(%s) = %v, want %v", role, capability, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestHighest(t *testing.T) {
+	tests := []struct {
+		a, b share.SharePermission
+		want share.SharePermission
+	}{
+		{a: "", b: "", want: ""},
+		{a: "", b: share.Viewer, want: share.Viewer},
+		{a: share.Viewer, b: "", want: share.Viewer},
+		{a: share.Viewer, b: share.Commenter, want: share.Commenter},
+		{a: share.Commenter, b: share.Viewer, want: share.Commenter},
+		{a: share.Commenter, b: share.Editor, want: share.Editor},
+		{a: share.Editor, b: share.CoOwner, want: share.CoOwner},
+		{a: share.CoOwner, b: share.Editor, want: share.CoOwner},
+		{a: share.CoOwner, b: share.Owner, want: share.Owner},
+		{a: share.Editor, b: share.Editor, want: share.Editor},
+		{a: share.Viewer, b: "unknown", want: share.Viewer},
+	}
+	for _, tt := range tests {
+		if got := share.Highest(tt.a, tt.b); got != tt.want {
+			t.Errorf("Highest(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParsePermission(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    share.SharePermission
+		wantErr error
+	}{
+		{value: "viewer", want: share.Viewer},
+		{value: "Commenter", want: share.Commenter},
+		{value: "editor", want: share.Editor},
+		{value: "co-owner", want: share.CoOwner},
+		{value: "READ", want: share.Viewer},
+		{value: "write", want: share.Editor},
+		{value: "owner", wantErr: share.ErrInvalidPermission},
+		{value: "admin", wantErr: share.ErrInvalidPermission},
+		{value: "", wantErr: share.ErrInvalidPermission},
+	}
+	for _, tt := range tests {
+		got, err := share.ParsePermission(tt.value)
+		if got != tt.want || err != tt.wantErr {
+			t.Errorf("ParsePermission(%q) = %q, %v, want %q, %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
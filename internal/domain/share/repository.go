@@ -26,9 +26,53 @@ type Repository interface {
 	// GetByRecipient retrieves all shares shared with a specific recipient
 	GetByRecipient(ctx context.Context, recipientID uuid.UUID) ([]*Share, error)
 
-	// Update updates an existing share
+	// GetByGroups retrieves all shares shared with any of the groups
+	GetByGroups(ctx context.Context, groupIDs []uuid.UUID) ([]*Share, error)
+
+	// GetByInviteToken retrieves a pending invitation by the hash of its invite token
+	GetByInviteToken(ctx context.Context, tokenHash string) (*Share, error)
+
+	// ClaimInvitation gives a pending invitation its recipient and clears its invite token
+	// It reports false when the invitation was claimed meanwhile
+	ClaimInvitation(ctx context.Context, id uuid.UUID, tokenHash string, recipientID uuid.UUID) (bool, error)
+
+	// Update updates the settings of an existing share
+	// Counters, the revocation and the recipient are left alone, they only change through their own methods
 	Update(ctx context.Context, share *Share) error
 
+	// Revoke revokes a share
+	Revoke(ctx context.Context, id uuid.UUID) error
+
+	// IncrementUploadCount counts an upload if the share has not reached its file limit
+	// It reports false when the limit has been reached
+	IncrementUploadCount(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// DecrementUploadCount gives back an upload counted by IncrementUploadCount
+	DecrementUploadCount(ctx context.Context, id uuid.UUID) error
+
+	// IncrementDownloadCount counts a download if the share is not revoked and has downloads left
+	// The share is revoked by the same update when it uses its last download
+	// It reports false when the share is revoked or has no downloads left
+	IncrementDownloadCount(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// RecordAccess increments the access count of a share and sets its last access time
+	RecordAccess(ctx context.Context, id uuid.UUID) error
+
 	// Delete removes a share
 	Delete(ctx context.Context, id uuid.UUID) error
 }
+
+// EventRepository defines the interface for share access event data access
+type EventRepository interface {
+	// Create stores a new access event
+	Create(ctx context.Context, event *AccessEvent) error
+
+	// ListByShare retrieves a page of the events of a share, newest first, with the total number of events
+	ListByShare(ctx context.Context, shareID uuid.UUID, page, pageSize int) ([]*AccessEvent, int64, error)
+
+	// StatsByShare aggregates the events of a share
+	StatsByShare(ctx context.Context, shareID uuid.UUID) (*AccessStats, error)
+
+	// DeleteByShare removes the events of a share
+	DeleteByShare(ctx context.Context, shareID uuid.UUID) error
+}
@@ -4,20 +4,57 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"log"
 	"time"
 
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/user"
+
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// GroupMembership resolves the members of the groups shares can target
+type GroupMembership interface {
+	IsMember(groupID, userID string) (bool, error)
+	GroupIDsOf(userID string) ([]string, error)
+}
+
+// PasswordLimits configures the throttling of share password attempts
+type PasswordLimits struct {
+	MaxAttempts      int           // Failed attempts allowed per share before it is locked, 0 disables the limit
+	MaxAttemptsPerIP int           // Failed attempts allowed per client IP before it is locked, 0 disables the limit
+	Lockout          time.Duration // How long a share or client IP stays locked, and the window failures are counted in
+}
+
 // Service provides share-related operations
 type Service struct {
-	repo Repository
+	repo          Repository
+	eventRepo     EventRepository
+	groups        GroupMembership
+	users         user.Repository
+	notifier      Notifier
+	tokenAttempts *common.AttemptLimiter
+	ipAttempts    *common.AttemptLimiter
 }
 
 // NewService creates a new share service
-func NewService(repo Repository) *Service {
+func NewService(
+	repo Repository,
+	eventRepo EventRepository,
+	groups GroupMembership,
+	users user.Repository,
+	notifier Notifier,
+	limits PasswordLimits,
+) *Service {
 	return &Service{
-		repo: repo,
+		repo:          repo,
+		eventRepo:     eventRepo,
+		groups:        groups,
+		users:         users,
+		notifier:      notifier,
+		tokenAttempts: common.NewAttemptLimiter(limits.MaxAttempts, limits.Lockout),
+		ipAttempts:    common.NewAttemptLimiter(limits.MaxAttemptsPerIP, limits.Lockout),
 	}
 }
 
@@ -65,6 +102,49 @@ func (s *Service) CreateUserShare(
 	return share, nil
 }
 
+// CreateGroupShare creates a new share with every member of a group
+// The owner must be a member of the group
+func (s *Service) CreateGroupShare(
+	ctx context.Context,
+	ownerID uuid.UUID,
+	resourceID uuid.UUID,
+	resourceType string,
+	groupID uuid.UUID,
+	permission SharePermission,
+) (*Share, error) {
+	isMember, err := s.groups.IsMember(groupID.String(), ownerID.String())
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrInvalidGroup
+	}
+
+	share := NewShare(ownerID, resourceID, resourceType, GroupShare, permission)
+	share.SetGroup(groupID)
+
+	if err := s.repo.Create(ctx, share); err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// IsRecipient checks if a user receives a share, directly or as a member of its group
+func (s *Service) IsRecipient(share *Share, userID uuid.UUID) (bool, error) {
+	switch share.Type {
+	case UserShare:
+		return share.RecipientID != nil && *share.RecipientID == userID, nil
+	case GroupShare:
+		if share.GroupID == nil {
+			return false, nil
+		}
+		return s.groups.IsMember(share.GroupID.String(), userID.String())
+	default:
+		return false, nil
+	}
+}
+
 // GetShareByID retrieves a share by its ID
 func (s *Service) GetShareByID(ctx context.Context, id uuid.UUID) (*Share, error) {
 	return s.repo.GetByID(ctx, id)
@@ -86,6 +166,21 @@ func (s *Service) SetShareExpiration(ctx context.Context, shareID uuid.UUID, exp
 	return s.repo.Update(ctx, share)
 }
 
+// SetSharePermission changes the role of a share
+// Upload options are cleared when the new role cannot upload
+func (s *Service) SetSharePermission(ctx context.Context, shareID uuid.UUID, permission SharePermission) error {
+	share, err := s.repo.GetByID(ctx, shareID)
+	if err != nil {
+		return err
+	}
+
+	share.SetPermission(permission)
+	if !permission.Has(CapUpload) {
+		share.SetUploadOptions(false, 0, 0)
+	}
+	return s.repo.Update(ctx, share)
+}
+
 // SetSharePassword sets a password for a share
 func (s *Service) SetSharePassword(ctx context.Context, shareID uuid.UUID, password string) error {
 	share, err := s.repo.GetByID(ctx, shareID)
@@ -93,32 +188,94 @@ func (s *Service) SetSharePassword(ctx context.Context, shareID uuid.UUID, passw
 		return err
 	}
 
-	share.SetPassword(password)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	share.SetPassword(string(hashedPassword))
 	return s.repo.Update(ctx, share)
 }
 
-// RevokeShare revokes access to a share
-func (s *Service) RevokeShare(ctx context.Context, shareID uuid.UUID) error {
+// SetShareUploadOptions configures uploads into a folder shared with a role that can upload
+func (s *Service) SetShareUploadOptions(ctx context.Context, shareID uuid.UUID, uploadOnly bool, maxFiles int, maxFileSize int64) error {
 	share, err := s.repo.GetByID(ctx, shareID)
 	if err != nil {
 		return err
 	}
 
-	share.Revoke()
+	share.SetUploadOptions(uploadOnly, maxFiles, maxFileSize)
 	return s.repo.Update(ctx, share)
 }
 
-// RecordShareAccess records an access to a share
-func (s *Service) RecordShareAccess(ctx context.Context, shareID uuid.UUID) error {
+// ReserveUpload counts an upload against the file limit of a share
+func (s *Service) ReserveUpload(ctx context.Context, shareID uuid.UUID) error {
+	counted, err := s.repo.IncrementUploadCount(ctx, shareID)
+	if err != nil {
+		return err
+	}
+	if !counted {
+		return ErrUploadLimitReached
+	}
+	return nil
+}
+
+// ReleaseUpload gives back an upload reserved for a file that could not be stored
+func (s *Service) ReleaseUpload(ctx context.Context, shareID uuid.UUID) error {
+	return s.repo.DecrementUploadCount(ctx, shareID)
+}
+
+// SetShareDownloadLimit limits the number of downloads through a link share
+func (s *Service) SetShareDownloadLimit(ctx context.Context, shareID uuid.UUID, maxDownloads int, burnAfterReading bool) error {
 	share, err := s.repo.GetByID(ctx, shareID)
 	if err != nil {
 		return err
 	}
 
-	share.RecordAccess()
+	share.SetDownloadLimit(maxDownloads, burnAfterReading)
 	return s.repo.Update(ctx, share)
 }
 
+// ConsumeDownload counts a download through a share and records it in the share's access log
+// A share that uses its last download is revoked, concurrent downloads cannot exceed the limit
+func (s *Service) ConsumeDownload(ctx context.Context, share *Share, visitor Visitor) error {
+	counted, err := s.repo.IncrementDownloadCount(ctx, share.ID)
+	if err != nil {
+		return err
+	}
+	if !counted {
+		err = ErrShareRevoked
+		if share.MaxDownloads > 0 {
+			err = ErrDownloadLimitReached
+		}
+		s.recordEvent(ctx, share, visitor, ActionDownload, "", err)
+		return err
+	}
+	s.recordEvent(ctx, share, visitor, ActionDownload, "", nil)
+
+	share.DownloadCount++
+	if share.IsDownloadLimitReached() {
+		share.IsRevoked = true
+	}
+	return nil
+}
+
+// RevokeShare revokes access to a share
+func (s *Service) RevokeShare(ctx context.Context, shareID uuid.UUID) error {
+	share, err := s.repo.GetByID(ctx, shareID)
+	if err != nil {
+		return err
+	}
+
+	share.Revoke()
+	return s.repo.Revoke(ctx, share.ID)
+}
+
+// RecordShareAccess records an access to a share
+func (s *Service) RecordShareAccess(ctx context.Context, shareID uuid.UUID) error {
+	return s.repo.RecordAccess(ctx, shareID)
+}
+
 // ListSharesByOwner lists all shares created by an owner
 func (s *Service) ListSharesByOwner(ctx context.Context, ownerID uuid.UUID) ([]*Share, error) {
 	return s.repo.GetByOwner(ctx, ownerID)
@@ -129,29 +286,81 @@ func (s *Service) ListSharesByResource(ctx context.Context, resourceID uuid.UUID
 	return s.repo.GetByResource(ctx, resourceID, resourceType)
 }
 
-// ListSharesWithUser lists all shares shared with a specific user
+// ListSharesWithUser lists all shares shared with a specific user, directly or through their groups
 func (s *Service) ListSharesWithUser(ctx context.Context, userID uuid.UUID) ([]*Share, error) {
-	return s.repo.GetByRecipient(ctx, userID)
+	shares, err := s.repo.GetByRecipient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs, err := s.groups.GroupIDsOf(userID.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(groupIDs) == 0 {
+		return shares, nil
+	}
+
+	groupUUIDs := make([]uuid.UUID, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		groupUUID, err := uuid.Parse(groupID)
+		if err != nil {
+			return nil, err
+		}
+		groupUUIDs = append(groupUUIDs, groupUUID)
+	}
+
+	groupShares, err := s.repo.GetByGroups(ctx, groupUUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The owner of a group share is a member of the group too
+	for _, groupShare := range groupShares {
+		if groupShare.OwnerID != userID {
+			shares = append(shares, groupShare)
+		}
+	}
+
+	return shares, nil
 }
 
-// DeleteShare permanently deletes a share
+// DeleteShare permanently deletes a share and its access log
 func (s *Service) DeleteShare(ctx context.Context, shareID uuid.UUID) error {
-	return s.repo.Delete(ctx, shareID)
+	if err := s.repo.Delete(ctx, shareID); err != nil {
+		return err
+	}
+	return s.eventRepo.DeleteByShare(ctx, shareID)
 }
 
-// ValidateSharePassword checks if the provided password is valid for a share
-func (s *Service) ValidateSharePassword(ctx context.Context, shareID uuid.UUID, password string) (bool, error) {
-	share, err := s.repo.GetByID(ctx, shareID)
-	if err != nil {
-		return false, err
+// ListAccessEvents lists a page of the access log of a share, newest first
+// Only the owner of the share can read its access log
+func (s *Service) ListAccessEvents(ctx context.Context, shareID, ownerID uuid.UUID, page, pageSize int) ([]*AccessEvent, int64, error) {
+	if _, err := s.getOwnedShare(ctx, shareID, ownerID); err != nil {
+		return nil, 0, err
 	}
+	return s.eventRepo.ListByShare(ctx, shareID, page, pageSize)
+}
 
-	// If no password is set, any password is invalid
-	if share.Password == nil {
-		return false, nil
+// GetAccessStats aggregates the access log of a share
+// Only the owner of the share can read its access log
+func (s *Service) GetAccessStats(ctx context.Context, shareID, ownerID uuid.UUID) (*AccessStats, error) {
+	if _, err := s.getOwnedShare(ctx, shareID, ownerID); err != nil {
+		return nil, err
 	}
+	return s.eventRepo.StatsByShare(ctx, shareID)
+}
 
-	return *share.Password == password, nil
+// getOwnedShare retrieves a share owned by a user
+func (s *Service) getOwnedShare(ctx context.Context, shareID, ownerID uuid.UUID) (*Share, error) {
+	share, err := s.repo.GetByID(ctx, shareID)
+	if err != nil {
+		return nil, err
+	}
+	if share.OwnerID != ownerID {
+		return nil, ErrUnauthorizedAccess
+	}
+	return share, nil
 }
 
 // GetShareURL generates a full URL for a share based on the base URL
@@ -183,8 +392,12 @@ func (s *Service) CheckAccessToResource(
 			continue
 		}
 
-		// Check if this is a user share directly with this user
-		if share.Type == UserShare && share.RecipientID != nil && *share.RecipientID == userID {
+		// Check if the user receives this share, directly or through a group
+		isRecipient, err := s.IsRecipient(share, userID)
+		if err != nil {
+			return false, err
+		}
+		if isRecipient {
 			return true, nil
 		}
 	}
@@ -192,11 +405,61 @@ func (s *Service) CheckAccessToResource(
 	return false, nil
 }
 
+// GetUserPermission returns the highest role the user and group shares of a resource grant to a user
+// Only shares created by the owner of the resource count, upload-only shares are skipped
+// as they let the recipient upload without seeing the resource
+// An empty role means no share gives the user access
+func (s *Service) GetUserPermission(
+	ctx context.Context,
+	userID uuid.UUID,
+	ownerID uuid.UUID,
+	resourceID uuid.UUID,
+	resourceType string,
+) (SharePermission, error) {
+	// Get all shares for this resource
+	shares, err := s.repo.GetByResource(ctx, resourceID, resourceType)
+	if err != nil {
+		return "", err
+	}
+
+	// Check which shares give this user access
+	var permission SharePermission
+	for _, share := range shares {
+		// Skip if share is revoked or expired
+		if share.IsRevoked || (share.ExpiresAt != nil && share.IsExpired()) {
+			continue
+		}
+
+		// Only shares from the owner count
+		if share.OwnerID != ownerID || share.UploadOnly {
+			continue
+		}
+
+		// Check if the user receives this share, directly or through a group
+		isRecipient, err := s.IsRecipient(share, userID)
+		if err != nil {
+			return "", err
+		}
+		if !isRecipient {
+			continue
+		}
+
+		permission = Highest(permission, share.Permission)
+	}
+
+	return permission, nil
+}
+
 // GetResourceByToken retrieves resource information from a share token
+// Wrong passwords are throttled per share and per client IP
+// Every attempt is recorded in the share's access log, except granted downloads
+// which are recorded by ConsumeDownload once they are counted
 func (s *Service) GetResourceByToken(
 	ctx context.Context,
 	token string,
 	password string,
+	visitor Visitor,
+	action AccessAction,
 ) (*Share, error) {
 	// Get share by token
 	share, err := s.repo.GetByToken(ctx, token)
@@ -204,29 +467,97 @@ func (s *Service) GetResourceByToken(
 		return nil, err
 	}
 
+	if err := s.checkAccess(share, password, visitor); err != nil {
+		s.recordEvent(ctx, share, visitor, action, password, err)
+		return nil, err
+	}
+	if action != ActionDownload {
+		s.recordEvent(ctx, share, visitor, action, password, nil)
+	}
+
+	// Record access
+	share.RecordAccess()
+	if err := s.repo.RecordAccess(ctx, share.ID); err != nil {
+		// Log error but continue
+		// logger.Warn("Failed to record share access", "error", err)
+	}
+
+	return share, nil
+}
+
+// checkAccess checks that a share is accessible and its password, if any, is right
+func (s *Service) checkAccess(share *Share, password string, visitor Visitor) error {
 	// Check if share is accessible
 	if !share.IsAccessible() {
+		if share.IsDownloadLimitReached() {
+			return ErrDownloadLimitReached
+		}
 		if share.IsRevoked {
-			return nil, ErrShareRevoked
+			return ErrShareRevoked
 		}
 		if share.IsExpired() {
-			return nil, ErrShareExpired
+			return ErrShareExpired
 		}
 	}
 
 	// Check password if required
-	if share.Password != nil && *share.Password != password {
-		return nil, ErrInvalidPassword
+	if share.Password != nil {
+		return s.verifyPassword(share, password, visitor.IP)
 	}
 
-	// Record access
-	share.RecordAccess()
-	if err := s.repo.Update(ctx, share); err != nil {
-		// Log error but continue
-		// logger.Warn("Failed to record share access", "error", err)
+	return nil
+}
+
+// recordEvent adds an access to the access log of a share
+// Failing to record an access is logged and does not fail the access itself
+func (s *Service) recordEvent(ctx context.Context, share *Share, visitor Visitor, action AccessAction, password string, accessErr error) {
+	outcome, recorded := outcomeOf(accessErr, password)
+	if !recorded {
+		return
 	}
 
-	return share, nil
+	if err := s.eventRepo.Create(ctx, NewAccessEvent(share.ID, visitor, action, outcome)); err != nil {
+		log.Printf("Error recording access to share %s: %v", share.ID, err)
+	}
+}
+
+// PruneAttempts forgets failed password attempts that no longer count
+func (s *Service) PruneAttempts() error {
+	s.tokenAttempts.Prune()
+	s.ipAttempts.Prune()
+	return nil
+}
+
+// verifyPassword checks the password of a share unless the share or the client is locked out
+func (s *Service) verifyPassword(share *Share, password, clientIP string) error {
+	tokenKey := share.ID.String()
+	if s.tokenAttempts.Locked(tokenKey) || (clientIP != "" && s.ipAttempts.Locked(clientIP)) {
+		return ErrTooManyAttempts
+	}
+
+	// A missing password is a prompt for one, not a guess
+	if password == "" {
+		return ErrInvalidPassword
+	}
+
+	if !checkPassword(share, password) {
+		s.tokenAttempts.Fail(tokenKey)
+		if clientIP != "" {
+			s.ipAttempts.Fail(clientIP)
+		}
+		return ErrInvalidPassword
+	}
+
+	s.tokenAttempts.Reset(tokenKey)
+	return nil
+}
+
+// checkPassword compares a password with the bcrypt hash of a share in constant time
+func checkPassword(share *Share, password string) bool {
+	if share.Password == nil {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(*share.Password), []byte(password)) == nil
 }
 
 // Helper function to generate a secure random token
@@ -0,0 +1,152 @@
+package share_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"easy-storage/internal/domain/share"
+	"easy-storage/internal/domain/share/sharetest"
+
+	"github.com/google/uuid"
+)
+
+func newTestService(limits share.PasswordLimits) (*share.Service, *sharetest.Repository, *sharetest.EventRepository) {
+	repo := sharetest.NewRepository()
+	events := &sharetest.EventRepository{}
+	return share.NewService(repo, events, nil, nil, nil, limits), repo, events
+}
+
+// newProtectedShare creates a link share protected by a password
+func newProtectedShare(t *testing.T, service *share.Service, password string) *share.Share {
+	t.Helper()
+
+	ctx := context.Background()
+	shareObj, err := service.CreateLinkShare(ctx, uuid.New(), uuid.New(), "file", share.Viewer)
+	if err != nil {
+		t.Fatalf("CreateLinkShare: %v", err)
+	}
+	if err := service.SetSharePassword(ctx, shareObj.ID, password); err != nil {
+		t.Fatalf("SetSharePassword: %v", err)
+	}
+	return shareObj
+}
+
+func TestSetSharePasswordStoresHash(t *testing.T) {
+	service, repo, _ := newTestService(share.PasswordLimits{})
+	shareObj := newProtectedShare(t, service, "secret")
+
+	stored, _ := repo.GetByID(context.Background(), shareObj.ID)
+	if stored.Password == nil || *stored.Password == "secret" {
+		t.Fatal("the password was not hashed")
+	}
+
+	// Passwords are only checked through the throttled share access
+	visitor := share.Visitor{IP: "10.0.0.1"}
+	if _, err := service.GetResourceByToken(context.Background(), shareObj.Token, "secret", visitor, share.ActionView); err != nil {
+		t.Errorf("GetResourceByToken with the password: %v", err)
+	}
+	if _, err := service.GetResourceByToken(context.Background(), shareObj.Token, "wrong", visitor, share.ActionView); err != share.ErrInvalidPassword {
+		t.Errorf("GetResourceByToken with a wrong password = %v, want ErrInvalidPassword", err)
+	}
+}
+
+func TestGetResourceByTokenLocksShareAfterFailedAttempts(t *testing.T) {
+	service, _, events := newTestService(share.PasswordLimits{MaxAttempts: 3, Lockout: time.Minute})
+	shareObj := newProtectedShare(t, service, "secret")
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		visitor := share.Visitor{IP: fmt.Sprintf("10.0.0.%d", i+1)}
+		if _, err := service.GetResourceByToken(ctx, shareObj.Token, "guess", visitor, share.ActionView); err != share.ErrInvalidPassword {
+			t.Fatalf("attempt %d error = %v, want ErrInvalidPassword", i+1, err)
+		}
+	}
+
+	// Even the right password is refused while the share is locked, whatever the IP
+	if _, err := service.GetResourceByToken(ctx, shareObj.Token, "secret", share.Visitor{IP: "10.0.0.9"}, share.ActionView); err != share.ErrTooManyAttempts {
+		t.Fatalf("error = %v, want ErrTooManyAttempts", err)
+	}
+
+	want := []share.AccessOutcome{share.OutcomeInvalidPassword, share.OutcomeInvalidPassword, share.OutcomeInvalidPassword, share.OutcomeLockedOut}
+	got := events.Outcomes(shareObj.ID)
+	if len(got) != len(want) {
+		t.Fatalf("recorded outcomes %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("outcome %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGetResourceByTokenLocksClientIPAcrossShares(t *testing.T) {
+	service, _, _ := newTestService(share.PasswordLimits{MaxAttemptsPerIP: 2, Lockout: time.Minute})
+	first := newProtectedShare(t, service, "secret")
+	second := newProtectedShare(t, service, "secret")
+	ctx := context.Background()
+	attacker := share.Visitor{IP: "203.0.113.7"}
+
+	for _, shareObj := range []*share.Share{first, second} {
+		if _, err := service.GetResourceByToken(ctx, shareObj.Token, "guess", attacker, share.ActionView); err != share.ErrInvalidPassword {
+			t.Fatalf("error = %v, want ErrInvalidPassword", err)
+		}
+	}
+
+	if _, err := service.GetResourceByToken(ctx, first.Token, "secret", attacker, share.ActionView); err != share.ErrTooManyAttempts {
+		t.Errorf("locked IP error = %v, want ErrTooManyAttempts", err)
+	}
+	if _, err := service.GetResourceByToken(ctx, first.Token, "secret", share.Visitor{IP: "198.51.100.1"}, share.ActionView); err != nil {
+		t.Errorf("another IP was refused: %v", err)
+	}
+}
+
+func TestGetResourceByTokenMissingPasswordIsNotAGuess(t *testing.T) {
+	service, _, events := newTestService(share.PasswordLimits{MaxAttempts: 1, Lockout: time.Minute})
+	shareObj := newProtectedShare(t, service, "secret")
+	ctx := context.Background()
+
+	if _, err := service.GetResourceByToken(ctx, shareObj.Token, "", share.Visitor{}, share.ActionView); err != share.ErrInvalidPassword {
+		t.Fatalf("error = %v, want ErrInvalidPassword", err)
+	}
+	if _, err := service.GetResourceByToken(ctx, shareObj.Token, "secret", share.Visitor{}, share.ActionView); err != nil {
+		t.Fatalf("the right password was refused after a prompt: %v", err)
+	}
+
+	if got := events.Outcomes(shareObj.ID); len(got) != 2 || got[0] != share.OutcomePasswordRequired || got[1] != share.OutcomeSuccess {
+		t.Errorf("recorded outcomes %v, want password_required then success", got)
+	}
+}
+
+func TestGetResourceByTokenRightPasswordResetsFailures(t *testing.T) {
+	service, _, _ := newTestService(share.PasswordLimits{MaxAttempts: 2, Lockout: time.Minute})
+	shareObj := newProtectedShare(t, service, "secret")
+	ctx := context.Background()
+
+	attempts := []string{"guess", "secret", "guess", "secret"}
+	for i, password := range attempts {
+		_, err := service.GetResourceByToken(ctx, shareObj.Token, password, share.Visitor{}, share.ActionView)
+		if password == "secret" && err != nil {
+			t.Fatalf("attempt %d with the right password failed: %v", i+1, err)
+		}
+	}
+}
+
+func TestGetResourceByTokenLockoutEnds(t *testing.T) {
+	service, _, _ := newTestService(share.PasswordLimits{MaxAttempts: 1, Lockout: 30 * time.Millisecond})
+	shareObj := newProtectedShare(t, service, "secret")
+	ctx := context.Background()
+
+	if _, err := service.GetResourceByToken(ctx, shareObj.Token, "guess", share.Visitor{}, share.ActionView); err != share.ErrInvalidPassword {
+		t.Fatalf("error = %v, want ErrInvalidPassword", err)
+	}
+	if _, err := service.GetResourceByToken(ctx, shareObj.Token, "secret", share.Visitor{}, share.ActionView); err != share.ErrTooManyAttempts {
+		t.Fatalf("error = %v, want ErrTooManyAttempts", err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, err := service.GetResourceByToken(ctx, shareObj.Token, "secret", share.Visitor{}, share.ActionView); err != nil {
+		t.Errorf("the right password was refused after the lockout: %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+// Package sharetest provides in-memory share repositories for tests
+package sharetest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"easy-storage/internal/domain/share"
+
+	"github.com/google/uuid"
+)
+
+// Repository implements share.Repository in memory
+// Shares are stored by value, so callers only see changes they save
+type Repository struct {
+	mu     sync.Mutex
+	shares map[uuid.UUID]share.Share
+}
+
+// NewRepository creates an empty in-memory share repository
+func NewRepository() *Repository {
+	return &Repository{shares: make(map[uuid.UUID]share.Share)}
+}
+
+// Create stores a new share
+func (r *Repository) Create(ctx context.Context, s *share.Share) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.shares[s.ID] = *s
+	return nil
+}
+
+// GetByID retrieves a share by its ID
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*share.Share, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, exists := r.shares[id]
+	if !exists {
+		return nil, share.ErrShareNotFound
+	}
+	return &s, nil
+}
+
+// GetByToken retrieves a share by its token
+func (r *Repository) GetByToken(ctx context.Context, token string) (*share.Share, error) {
+	return r.find(func(s *share.Share) bool { return s.Token == token })
+}
+
+// GetByResource retrieves the shares of a resource
+func (r *Repository) GetByResource(ctx context.Context, resourceID uuid.UUID, resourceType string) ([]*share.Share, error) {
+	return r.filter(func(s *share.Share) bool {
+		return s.ResourceID == resourceID && s.ResourceType == resourceType
+	}), nil
+}
+
+// GetByOwner retrieves the shares created by an owner
+func (r *Repository) GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]*share.Share, error) {
+	return r.filter(func(s *share.Share) bool { return s.OwnerID == ownerID }), nil
+}
+
+// GetByRecipient retrieves the shares shared with a recipient
+func (r *Repository) GetByRecipient(ctx context.Context, recipientID uuid.UUID) ([]*share.Share, error) {
+	return r.filter(func(s *share.Share) bool {
+		return s.RecipientID != nil && *s.RecipientID == recipientID
+	}), nil
+}
+
+// GetByGroups retrieves the shares shared with any of the groups
+func (r *Repository) GetByGroups(ctx context.Context, groupIDs []uuid.UUID) ([]*share.Share, error) {
+	return r.filter(func(s *share.Share) bool {
+		for _, groupID := range groupIDs {
+			if s.GroupID != nil && *s.GroupID == groupID {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// GetByInviteToken retrieves a pending invitation by the hash of its invite token
+func (r *Repository) GetByInviteToken(ctx context.Context, tokenHash string) (*share.Share, error) {
+	shares := r.filter(func(s *share.Share) bool {
+		return s.InviteTokenHash == tokenHash && s.RecipientID == nil
+	})
+	if len(shares) == 0 {
+		return nil, share.ErrShareNotFound
+	}
+	return shares[0], nil
+}
+
+// ClaimInvitation gives a pending invitation its recipient if its invite token was not used meanwhile
+func (r *Repository) ClaimInvitation(ctx context.Context, id uuid.UUID, tokenHash string, recipientID uuid.UUID) (bool, error) {
+	claimed := false
+	err := r.update(id, func(s *share.Share) bool {
+		if s.InviteTokenHash != tokenHash || s.RecipientID != nil {
+			return false
+		}
+		s.RecipientID = &recipientID
+		s.InviteTokenHash = ""
+		claimed = true
+		return true
+	})
+	return claimed, err
+}
+
+// Update keeps the stored counters and revocation, like the GORM repository
+func (r *Repository) Update(ctx context.Context, s *share.Share) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.shares[s.ID]
+	if !exists {
+		return share.ErrShareNotFound
+	}
+	updated := *s
+	updated.UploadCount = stored.UploadCount
+	updated.DownloadCount = stored.DownloadCount
+	updated.AccessCount = stored.AccessCount
+	updated.LastAccessAt = stored.LastAccessAt
+	updated.IsRevoked = stored.IsRevoked
+	r.shares[s.ID] = updated
+	return nil
+}
+
+// Revoke revokes a share
+func (r *Repository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.update(id, func(s *share.Share) bool {
+		s.IsRevoked = true
+		return true
+	})
+}
+
+// IncrementUploadCount counts an upload if the share has not reached its file limit
+func (r *Repository) IncrementUploadCount(ctx context.Context, id uuid.UUID) (bool, error) {
+	counted := false
+	err := r.update(id, func(s *share.Share) bool {
+		if s.MaxFiles > 0 && s.UploadCount >= s.MaxFiles {
+			return false
+		}
+		s.UploadCount++
+		counted = true
+		return true
+	})
+	return counted, err
+}
+
+// DecrementUploadCount gives back a counted upload
+func (r *Repository) DecrementUploadCount(ctx context.Context, id uuid.UUID) error {
+	return r.update(id, func(s *share.Share) bool {
+		s.UploadCount = max(s.UploadCount-1, 0)
+		return true
+	})
+}
+
+// IncrementDownloadCount checks and counts a download in a single step, like the conditional update of the GORM repository
+func (r *Repository) IncrementDownloadCount(ctx context.Context, id uuid.UUID) (bool, error) {
+	counted := false
+	err := r.update(id, func(s *share.Share) bool {
+		if s.IsRevoked || (s.MaxDownloads > 0 && s.DownloadCount >= s.MaxDownloads) {
+			return false
+		}
+		s.DownloadCount++
+		if s.MaxDownloads > 0 && s.DownloadCount >= s.MaxDownloads {
+			s.IsRevoked = true
+		}
+		counted = true
+		return true
+	})
+	return counted, err
+}
+
+// RecordAccess increments the access count of a share and sets its last access time
+func (r *Repository) RecordAccess(ctx context.Context, id uuid.UUID) error {
+	return r.update(id, func(s *share.Share) bool {
+		s.AccessCount++
+		now := time.Now()
+		s.LastAccessAt = &now
+		return true
+	})
+}
+
+// Delete removes a share
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.shares, id)
+	return nil
+}
+
+// update applies a change to a stored share, the change reports whether it applies
+func (r *Repository) update(id uuid.UUID, change func(s *share.Share) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, exists := r.shares[id]
+	if !exists {
+		return share.ErrShareNotFound
+	}
+	if change(&s) {
+		r.shares[id] = s
+	}
+	return nil
+}
+
+// find returns a share matching a condition
+func (r *Repository) find(match func(s *share.Share) bool) (*share.Share, error) {
+	shares := r.filter(match)
+	if len(shares) == 0 {
+		return nil, share.ErrShareNotFound
+	}
+	return shares[0], nil
+}
+
+// filter returns copies of the shares matching a condition
+func (r *Repository) filter(match func(s *share.Share) bool) []*share.Share {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var shares []*share.Share
+	for _, stored := range r.shares {
+		if match(&stored) {
+			s := stored
+			shares = append(shares, &s)
+		}
+	}
+	return shares
+}
+
+// EventRepository implements share.EventRepository in memory
+type EventRepository struct {
+	mu     sync.Mutex
+	events []share.AccessEvent
+}
+
+// Create stores a new access event
+func (r *EventRepository) Create(ctx context.Context, event *share.AccessEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// ListByShare ignores pagination and returns every event of a share, newest first
+func (r *EventRepository) ListByShare(ctx context.Context, shareID uuid.UUID, page, pageSize int) ([]*share.AccessEvent, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*share.AccessEvent
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if r.events[i].ShareID == shareID {
+			event := r.events[i]
+			events = append(events, &event)
+		}
+	}
+	return events, int64(len(events)), nil
+}
+
+// StatsByShare only counts the events of a share
+func (r *EventRepository) StatsByShare(ctx context.Context, shareID uuid.UUID) (*share.AccessStats, error) {
+	return &share.AccessStats{TotalEvents: int64(len(r.Outcomes(shareID)))}, nil
+}
+
+// DeleteByShare keeps the events, tests read them after the share is gone
+func (r *EventRepository) DeleteByShare(ctx context.Context, shareID uuid.UUID) error {
+	return nil
+}
+
+// Outcomes lists the outcomes recorded for a share, oldest first
+func (r *EventRepository) Outcomes(shareID uuid.UUID) []share.AccessOutcome {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var outcomes []share.AccessOutcome
+	for _, event := range r.events {
+		if event.ShareID == shareID {
+			outcomes = append(outcomes, event.Outcome)
+		}
+	}
+	return outcomes
+}
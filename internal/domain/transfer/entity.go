@@ -0,0 +1,55 @@
+package transfer
+
+import (
+	"time"
+)
+
+// Status is the state of an ownership transfer
+type Status string
+
+const (
+	// StatusPending transfers wait for the recipient to answer
+	StatusPending Status = "pending"
+	// StatusAccepted transfers have moved the resource to the recipient
+	StatusAccepted Status = "accepted"
+	// StatusDeclined transfers were refused by the recipient
+	StatusDeclined Status = "declined"
+	// StatusCancelled transfers were withdrawn by the sender
+	StatusCancelled Status = "cancelled"
+)
+
+// Transfer represents a request to hand a file or a folder tree over to another user
+type Transfer struct {
+	ID           string
+	ResourceID   string
+	ResourceType string // "file" or "folder"
+	FromUserID   string
+	ToUserID     string
+	Status       Status
+	CreatedAt    time.Time
+	RespondedAt  *time.Time // When the transfer was accepted, declined or cancelled
+}
+
+// NewTransfer creates a new pending transfer
+func NewTransfer(resourceID, resourceType, fromUserID, toUserID string) *Transfer {
+	return &Transfer{
+		ResourceID:   resourceID,
+		ResourceType: resourceType,
+		FromUserID:   fromUserID,
+		ToUserID:     toUserID,
+		Status:       StatusPending,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// IsPending checks if the transfer still waits for an answer
+func (t *Transfer) IsPending() bool {
+	return t.Status == StatusPending
+}
+
+// respond closes the transfer with the given status
+func (t *Transfer) respond(status Status) {
+	now := time.Now()
+	t.Status = status
+	t.RespondedAt = &now
+}
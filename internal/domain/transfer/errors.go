@@ -0,0 +1,23 @@
+package transfer
+
+import "errors"
+
+var (
+	// ErrTransferNotFound is returned when a transfer does not exist or the user is not part of it
+	ErrTransferNotFound = errors.New("transfer not found")
+
+	// ErrTransferNotPending is returned when answering a transfer that was already answered
+	ErrTransferNotPending = errors.New("transfer is no longer pending")
+
+	// ErrTransferPending is returned when a resource already has a pending transfer
+	ErrTransferPending = errors.New("resource already has a pending transfer")
+
+	// ErrResourceNotFound is returned when the resource does not exist or no longer belongs to the sender
+	ErrResourceNotFound = errors.New("resource not found")
+
+	// ErrInvalidResourceType is returned for resources that are neither files nor folders
+	ErrInvalidResourceType = errors.New("invalid resource type")
+
+	// ErrInvalidRecipient is returned when transferring to yourself or to an unknown user
+	ErrInvalidRecipient = errors.New("invalid transfer recipient")
+)
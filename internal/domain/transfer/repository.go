@@ -0,0 +1,24 @@
+package transfer
+
+// Repository defines the interface for ownership transfer data access
+type Repository interface {
+	Save(transfer *Transfer) error
+	FindByID(id string) (*Transfer, error)
+	// FindIncoming finds the transfers sent to a user, newest first
+	FindIncoming(userID string) ([]*Transfer, error)
+	// FindOutgoing finds the transfers sent by a user, newest first
+	FindOutgoing(userID string) ([]*Transfer, error)
+	// HasPending checks if a resource has a pending transfer
+	HasPending(resourceID string) (bool, error)
+
+	// Respond stores the answer to a transfer if it is still pending, else it fails with ErrTransferNotPending
+	Respond(transfer *Transfer) error
+
+	// Complete stores an accepted transfer and moves the resource tree to the recipient in a single transaction
+	// The tree is selected in the transaction, trashed files and folders included, and the storage its files use,
+	// every version included, moves to the recipient. The transferred resource lands in the recipient's root folder
+	// as rootName and the shares of moved resources change owner
+	// It fails with ErrTransferNotPending when the transfer was answered meanwhile, with ErrResourceNotFound when
+	// the resource no longer belongs to the sender and with user.ErrStorageQuotaExceeded when it does not fit
+	Complete(transfer *Transfer, rootName string) error
+}
@@ -0,0 +1,209 @@
+package transfer
+
+import (
+	"errors"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/folder"
+	"easy-storage/internal/domain/user"
+)
+
+// Service provides ownership transfer operations
+type Service struct {
+	repo          Repository
+	fileService   *file.Service
+	folderService *folder.Service
+	userService   *user.Service
+}
+
+// NewService creates a new transfer service
+func NewService(repo Repository, fileService *file.Service, folderService *folder.Service, userService *user.Service) *Service {
+	return &Service{
+		repo:          repo,
+		fileService:   fileService,
+		folderService: folderService,
+		userService:   userService,
+	}
+}
+
+// RequestTransfer asks a user to take over a file or a folder tree of the sender
+// Nothing changes hands until the recipient accepts
+func (s *Service) RequestTransfer(resourceType, resourceID, fromUserID, toUserID string) (*Transfer, error) {
+	if resourceType != "file" && resourceType != "folder" {
+		return nil, ErrInvalidResourceType
+	}
+
+	if toUserID == fromUserID {
+		return nil, ErrInvalidRecipient
+	}
+	if _, err := s.userService.GetUserByID(toUserID); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, ErrInvalidRecipient
+		}
+		return nil, err
+	}
+
+	if err := s.checkOwner(resourceType, resourceID, fromUserID); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.repo.HasPending(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrTransferPending
+	}
+
+	transfer := NewTransfer(resourceID, resourceType, fromUserID, toUserID)
+	if err := s.repo.Save(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// GetTransfer retrieves a transfer the user sent or received
+func (s *Service) GetTransfer(id, userID string) (*Transfer, error) {
+	transfer, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.FromUserID != userID && transfer.ToUserID != userID {
+		return nil, ErrTransferNotFound
+	}
+	return transfer, nil
+}
+
+// ListIncoming lists the transfers sent to a user
+func (s *Service) ListIncoming(userID string) ([]*Transfer, error) {
+	return s.repo.FindIncoming(userID)
+}
+
+// ListOutgoing lists the transfers sent by a user
+func (s *Service) ListOutgoing(userID string) ([]*Transfer, error) {
+	return s.repo.FindOutgoing(userID)
+}
+
+// AcceptTransfer moves the resource of a transfer to its recipient
+// The storage used by the moved files is charged to the recipient, the transfer is rejected
+// with user.ErrStorageQuotaExceeded and stays pending when it does not fit in their quota
+func (s *Service) AcceptTransfer(id, userID string) (*Transfer, error) {
+	transfer, err := s.receivedTransfer(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOwner(transfer.ResourceType, transfer.ResourceID, transfer.FromUserID); err != nil {
+		return nil, err
+	}
+	rootName, err := s.rootName(transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.respond(StatusAccepted)
+	if err := s.repo.Complete(transfer, rootName); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// DeclineTransfer refuses a transfer sent to the user
+func (s *Service) DeclineTransfer(id, userID string) (*Transfer, error) {
+	transfer, err := s.receivedTransfer(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.respond(StatusDeclined)
+	if err := s.repo.Respond(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// CancelTransfer withdraws a transfer sent by the user
+func (s *Service) CancelTransfer(id, userID string) (*Transfer, error) {
+	transfer, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.FromUserID != userID {
+		return nil, ErrTransferNotFound
+	}
+	if !transfer.IsPending() {
+		return nil, ErrTransferNotPending
+	}
+
+	transfer.respond(StatusCancelled)
+	if err := s.repo.Respond(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// receivedTransfer retrieves a pending transfer sent to the user
+func (s *Service) receivedTransfer(id, userID string) (*Transfer, error) {
+	transfer, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID != userID {
+		return nil, ErrTransferNotFound
+	}
+	if !transfer.IsPending() {
+		return nil, ErrTransferNotPending
+	}
+	return transfer, nil
+}
+
+// checkOwner checks that a resource exists and belongs to the user
+func (s *Service) checkOwner(resourceType, resourceID, userID string) error {
+	var ownerID string
+	switch resourceType {
+	case "file":
+		f, err := s.fileService.GetFile(resourceID)
+		if err == file.ErrFileNotFound {
+			return ErrResourceNotFound
+		} else if err != nil {
+			return err
+		}
+		ownerID = f.UserID
+	case "folder":
+		f, err := s.folderService.GetFolder(resourceID)
+		if err == folder.ErrFolderNotFound {
+			return ErrResourceNotFound
+		} else if err != nil {
+			return err
+		}
+		ownerID = f.UserID
+	default:
+		return ErrInvalidResourceType
+	}
+
+	if ownerID != userID {
+		return ErrResourceNotFound
+	}
+	return nil
+}
+
+// rootName returns a name for the transferred resource that is free in the recipient's root folder
+func (s *Service) rootName(transfer *Transfer) (string, error) {
+	if transfer.ResourceType == "folder" {
+		root, err := s.folderService.GetFolder(transfer.ResourceID)
+		if err != nil {
+			return "", err
+		}
+		return s.folderService.FreeName(transfer.ToUserID, "", root.Name)
+	}
+
+	transferred, err := s.fileService.GetFile(transfer.ResourceID)
+	if err != nil {
+		return "", err
+	}
+	return s.fileService.FreeName(transfer.ToUserID, "", transferred.Name)
+}
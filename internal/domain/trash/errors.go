@@ -0,0 +1,6 @@
+package trash
+
+import "errors"
+
+// ErrItemNotFound is returned when a file or folder is not in the user's trash
+var ErrItemNotFound = errors.New("item not found in trash")
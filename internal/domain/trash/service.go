@@ -0,0 +1,170 @@
+package trash
+
+import (
+	"log"
+	"time"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/folder"
+)
+
+// Service provides trash operations over files and folders
+type Service struct {
+	fileService   *file.Service
+	folderService *folder.Service
+	retention     time.Duration
+}
+
+// NewService creates a new trash service
+// Items are purged once they have been in the trash for longer than retention, zero keeps them forever
+func NewService(fileService *file.Service, folderService *folder.Service, retention time.Duration) *Service {
+	return &Service{
+		fileService:   fileService,
+		folderService: folderService,
+		retention:     retention,
+	}
+}
+
+// List lists the folders and files a user moved to the trash
+func (s *Service) List(userID string) ([]*folder.Folder, []*file.File, error) {
+	folders, err := s.folderService.ListTrashedFolders(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := s.fileService.ListTrashedFiles(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return folders, files, nil
+}
+
+// RestoreFile takes a file out of the user's trash
+func (s *Service) RestoreFile(id, userID string) (*file.File, error) {
+	trashedFile, err := s.getFile(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fileService.RestoreFile(trashedFile)
+}
+
+// RestoreFolder takes a folder and its subtree out of the user's trash
+func (s *Service) RestoreFolder(id, userID string) (*folder.Folder, error) {
+	trashedFolder, err := s.getFolder(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.folderService.RestoreFolder(trashedFolder)
+}
+
+// DeleteFile permanently deletes a file in the user's trash
+func (s *Service) DeleteFile(id, userID string) error {
+	trashedFile, err := s.getFile(id, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.fileService.PurgeFile(trashedFile)
+}
+
+// DeleteFolder permanently deletes a folder in the user's trash and its subtree
+func (s *Service) DeleteFolder(id, userID string) error {
+	trashedFolder, err := s.getFolder(id, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.folderService.PurgeFolder(trashedFolder)
+}
+
+// Empty permanently deletes everything in the user's trash
+func (s *Service) Empty(userID string) error {
+	folders, files, err := s.List(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, trashedFolder := range folders {
+		if err := s.folderService.PurgeFolder(trashedFolder); err != nil {
+			return err
+		}
+	}
+
+	for _, trashedFile := range files {
+		if err := s.fileService.PurgeFile(trashedFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeExpired permanently deletes items that have been in the trash longer than the retention period
+func (s *Service) PurgeExpired() error {
+	if s.retention <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-s.retention)
+
+	folders, err := s.folderService.ListTrashedFoldersBefore(before)
+	if err != nil {
+		return err
+	}
+	for _, trashedFolder := range folders {
+		if err := s.folderService.PurgeFolder(trashedFolder); err != nil {
+			log.Printf("Error purging folder %s from trash: %v", trashedFolder.ID, err)
+		}
+	}
+
+	files, err := s.fileService.ListTrashedFilesBefore(before)
+	if err != nil {
+		return err
+	}
+	for _, trashedFile := range files {
+		if err := s.fileService.PurgeFile(trashedFile); err != nil {
+			log.Printf("Error purging file %s from trash: %v", trashedFile.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// getFile retrieves a file in the trash owned by the user
+func (s *Service) getFile(id, userID string) (*file.File, error) {
+	trashedFile, err := s.fileService.GetTrashedFile(id)
+	if err != nil {
+		if err == file.ErrFileNotFound {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	// Hide the trash of other users
+	if trashedFile.UserID != userID {
+		return nil, ErrItemNotFound
+	}
+
+	return trashedFile, nil
+}
+
+// getFolder retrieves a folder in the trash owned by the user
+func (s *Service) getFolder(id, userID string) (*folder.Folder, error) {
+	trashedFolder, err := s.folderService.GetTrashedFolder(id)
+	if err != nil {
+		if err == folder.ErrFolderNotFound {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	// Hide the trash of other users
+	if trashedFolder.UserID != userID {
+		return nil, ErrItemNotFound
+	}
+
+	return trashedFolder, nil
+}
@@ -0,0 +1,279 @@
+package trash_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/file/filetest"
+	"easy-storage/internal/domain/folder"
+	"easy-storage/internal/domain/folder/foldertest"
+	"easy-storage/internal/domain/trash"
+	"easy-storage/internal/domain/user/usertest"
+	"easy-storage/internal/infrastructure/storage/memory"
+)
+
+// fixture wires the trash service to in-memory repositories and storage
+type fixture struct {
+	trash   *trash.Service
+	files   *file.Service
+	folders *folder.Service
+	users   *usertest.Repository
+	storage *memory.MemoryProvider
+}
+
+func newFixture(t *testing.T, retention time.Duration) *fixture {
+	t.Helper()
+
+	users := usertest.NewRepository()
+	users.AddUser(t, "owner", 1000)
+
+	folderRepo := foldertest.NewRepository()
+	files := filetest.NewService(users, folderRepo)
+	folders := folder.NewService(folderRepo, files.Service)
+	return &fixture{
+		trash:   trash.NewService(files.Service, folders, retention),
+		files:   files.Service,
+		folders: folders,
+		users:   users,
+		storage: files.Storage,
+	}
+}
+
+func (f *fixture) createFolder(t *testing.T, name, parentID string) *folder.Folder {
+	t.Helper()
+
+	created, err := f.folders.CreateFolder(name, parentID, "owner")
+	if err != nil {
+		t.Fatalf("CreateFolder %s: %v", name, err)
+	}
+	return created
+}
+
+func (f *fixture) uploadFile(t *testing.T, name, folderID string) *file.File {
+	t.Helper()
+
+	uploaded, err := f.files.UploadFile(name, 5, "text/plain", strings.NewReader("hello"), "owner", folderID)
+	if err != nil {
+		t.Fatalf("UploadFile %s: %v", name, err)
+	}
+	return uploaded
+}
+
+func (f *fixture) storageUsed(t *testing.T) int64 {
+	t.Helper()
+
+	return f.users.StorageUsed(t, "owner")
+}
+
+func TestDeleteFolderTrashesSubtree(t *testing.T) {
+	f := newFixture(t, 0)
+	parent := f.createFolder(t, "projects", "")
+	child := f.createFolder(t, "drafts", parent.ID)
+	f.uploadFile(t, "plan.txt", parent.ID)
+	nested := f.uploadFile(t, "draft.txt", child.ID)
+
+	if err := f.folders.DeleteFolder(parent.ID, "owner"); err != nil {
+		t.Fatalf("DeleteFolder: %v", err)
+	}
+
+	// Only the deleted folder is listed, its contents go with it
+	folders, files, err := f.trash.List("owner")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(folders) != 1 || folders[0].ID != parent.ID || len(files) != 0 {
+		t.Errorf("trash lists %d folders and %d files, want only the deleted folder", len(folders), len(files))
+	}
+
+	if _, err := f.files.GetFile(nested.ID); err != file.ErrFileNotFound {
+		t.Errorf("GetFile of a trashed file error = %v, want ErrFileNotFound", err)
+	}
+	if _, err := f.folders.GetFolder(child.ID); err != folder.ErrFolderNotFound {
+		t.Errorf("GetFolder of a trashed subfolder error = %v, want ErrFolderNotFound", err)
+	}
+
+	// Trashed content keeps its quota until it is purged
+	if used := f.storageUsed(t); used != 10 {
+		t.Errorf("storage used = %d, want 10", used)
+	}
+}
+
+func TestRestoreFolderRestoresSubtree(t *testing.T) {
+	f := newFixture(t, 0)
+	parent := f.createFolder(t, "projects", "")
+	child := f.createFolder(t, "drafts", parent.ID)
+	nested := f.uploadFile(t, "draft.txt", child.ID)
+
+	if err := f.folders.DeleteFolder(parent.ID, "owner"); err != nil {
+		t.Fatalf("DeleteFolder: %v", err)
+	}
+
+	// A new folder takes the name in the meantime
+	f.createFolder(t, "projects", "")
+
+	restored, err := f.trash.RestoreFolder(parent.ID, "owner")
+	if err != nil {
+		t.Fatalf("RestoreFolder: %v", err)
+	}
+	if restored.Name != "projects (1)" {
+		t.Errorf("restored folder name = %q, want %q", restored.Name, "projects (1)")
+	}
+
+	if _, err := f.folders.GetFolder(child.ID); err != nil {
+		t.Errorf("subfolder was not restored: %v", err)
+	}
+	restoredFile, err := f.files.GetFile(nested.ID)
+	if err != nil {
+		t.Fatalf("file was not restored: %v", err)
+	}
+	if restoredFile.FolderID != child.ID {
+		t.Errorf("file restored into %q, want its subfolder %q", restoredFile.FolderID, child.ID)
+	}
+
+	if folders, files, _ := f.trash.List("owner"); len(folders) != 0 || len(files) != 0 {
+		t.Errorf("trash still lists %d folders and %d files", len(folders), len(files))
+	}
+}
+
+func TestRestoreFileToRootWhenFolderIsGone(t *testing.T) {
+	f := newFixture(t, 0)
+	parent := f.createFolder(t, "projects", "")
+	trashed := f.uploadFile(t, "plan.txt", parent.ID)
+
+	if err := f.files.DeleteFile(trashed.ID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if err := f.folders.DeleteFolder(parent.ID, "owner"); err != nil {
+		t.Fatalf("DeleteFolder: %v", err)
+	}
+
+	// The file was trashed on its own, so it is listed next to its folder
+	folders, files, _ := f.trash.List("owner")
+	if len(folders) != 1 || len(files) != 1 {
+		t.Fatalf("trash lists %d folders and %d files, want 1 of each", len(folders), len(files))
+	}
+
+	restored, err := f.trash.RestoreFile(trashed.ID, "owner")
+	if err != nil {
+		t.Fatalf("RestoreFile: %v", err)
+	}
+	if restored.FolderID != "" {
+		t.Errorf("file restored into %q, want the root folder", restored.FolderID)
+	}
+}
+
+func TestRestoreFileRenamesOnConflict(t *testing.T) {
+	f := newFixture(t, 0)
+	trashed := f.uploadFile(t, "plan.txt", "")
+
+	if err := f.files.DeleteFile(trashed.ID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	f.uploadFile(t, "plan.txt", "")
+
+	restored, err := f.trash.RestoreFile(trashed.ID, "owner")
+	if err != nil {
+		t.Fatalf("RestoreFile: %v", err)
+	}
+	if restored.Name != "plan (1).txt" {
+		t.Errorf("restored file name = %q, want %q", restored.Name, "plan (1).txt")
+	}
+}
+
+func TestTrashHidesOtherUsersItems(t *testing.T) {
+	f := newFixture(t, 0)
+	trashed := f.uploadFile(t, "plan.txt", "")
+	if err := f.files.DeleteFile(trashed.ID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+
+	if _, err := f.trash.RestoreFile(trashed.ID, "intruder"); err != trash.ErrItemNotFound {
+		t.Errorf("RestoreFile error = %v, want ErrItemNotFound", err)
+	}
+	if err := f.trash.DeleteFile(trashed.ID, "intruder"); err != trash.ErrItemNotFound {
+		t.Errorf("DeleteFile error = %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestDeleteFolderPurgesSubtree(t *testing.T) {
+	f := newFixture(t, 0)
+	parent := f.createFolder(t, "projects", "")
+	child := f.createFolder(t, "drafts", parent.ID)
+	f.uploadFile(t, "plan.txt", parent.ID)
+	f.uploadFile(t, "draft.txt", child.ID)
+
+	if err := f.folders.DeleteFolder(parent.ID, "owner"); err != nil {
+		t.Fatalf("DeleteFolder: %v", err)
+	}
+	if err := f.trash.DeleteFolder(parent.ID, "owner"); err != nil {
+		t.Fatalf("trash DeleteFolder: %v", err)
+	}
+
+	if f.storage.Count() != 0 {
+		t.Errorf("storage holds %d objects after purging, want 0", f.storage.Count())
+	}
+	if used := f.storageUsed(t); used != 0 {
+		t.Errorf("storage used = %d, want the quota released", used)
+	}
+	if _, err := f.trash.RestoreFolder(child.ID, "owner"); err != trash.ErrItemNotFound {
+		t.Errorf("RestoreFolder of a purged subfolder error = %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	f := newFixture(t, 0)
+	parent := f.createFolder(t, "projects", "")
+	f.uploadFile(t, "plan.txt", parent.ID)
+	loose := f.uploadFile(t, "notes.txt", "")
+	kept := f.uploadFile(t, "kept.txt", "")
+
+	if err := f.files.DeleteFile(loose.ID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if err := f.folders.DeleteFolder(parent.ID, "owner"); err != nil {
+		t.Fatalf("DeleteFolder: %v", err)
+	}
+
+	if err := f.trash.Empty("owner"); err != nil {
+		t.Fatalf("Empty: %v", err)
+	}
+
+	if folders, files, _ := f.trash.List("owner"); len(folders) != 0 || len(files) != 0 {
+		t.Errorf("trash still lists %d folders and %d files", len(folders), len(files))
+	}
+	if f.storage.Count() != 1 || !f.storage.Exists(kept.Path) {
+		t.Error("emptying the trash touched content outside of it")
+	}
+	if used := f.storageUsed(t); used != 5 {
+		t.Errorf("storage used = %d, want only the kept file charged", used)
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	f := newFixture(t, 20*time.Millisecond)
+	old := f.uploadFile(t, "old.txt", "")
+	if err := f.files.DeleteFile(old.ID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	recent := f.uploadFile(t, "recent.txt", "")
+	if err := f.files.DeleteFile(recent.ID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+
+	if err := f.trash.PurgeExpired(); err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+
+	_, files, _ := f.trash.List("owner")
+	if len(files) != 1 || files[0].ID != recent.ID {
+		t.Errorf("trash lists %d files, want only the recent one", len(files))
+	}
+	if f.storage.Exists(old.Path) || !f.storage.Exists(recent.Path) {
+		t.Error("PurgeExpired did not purge exactly the expired file")
+	}
+}
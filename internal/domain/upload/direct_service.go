@@ -0,0 +1,183 @@
+package upload
+
+import (
+	"log"
+	"time"
+
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/user"
+)
+
+// DirectStorageProvider defines the storage operations needed for direct uploads
+type DirectStorageProvider interface {
+	GetSignedUploadURL(filename string, contentType string, size int64, expiryTime int64) (string, string, error)
+	Stat(path string) (common.ObjectInfo, error)
+	Delete(path string) error
+}
+
+// DirectOptions configures direct uploads
+type DirectOptions struct {
+	MaxSize int64         // Maximum upload size in bytes
+	Expiry  time.Duration // How long a signed upload URL is valid
+}
+
+// DirectService provides uploads that clients send straight to storage with a signed URL
+// Quota is reserved when the upload is initiated and released if it is never completed
+type DirectService struct {
+	repo        ReservationRepository
+	storage     DirectStorageProvider
+	fileService *file.Service
+	userStorage *user.StorageService
+	options     DirectOptions
+	locks       keyedLocks // Serializes completion of the same reservation within this process
+}
+
+// NewDirectService creates a new direct upload service
+func NewDirectService(repo ReservationRepository, storage DirectStorageProvider, fileService *file.Service, userStorage *user.StorageService, options DirectOptions) *DirectService {
+	return &DirectService{
+		repo:        repo,
+		storage:     storage,
+		fileService: fileService,
+		userStorage: userStorage,
+		options:     options,
+	}
+}
+
+// InitiateUpload reserves quota for a file and returns the reservation and the signed upload URL
+func (s *DirectService) InitiateUpload(userID, folderID, filename, contentType string, size int64) (*Reservation, string, error) {
+	if size < 0 {
+		return nil, "", ErrInvalidSize
+	}
+	if s.options.MaxSize > 0 && size > s.options.MaxSize {
+		return nil, "", ErrUploadTooLarge
+	}
+
+	// Validate folder ownership if folderID is provided
+	if err := s.fileService.ValidateFolder(folderID, userID); err != nil {
+		return nil, "", err
+	}
+
+	// Reserve the quota now, so concurrent uploads cannot overcommit it
+	if s.userStorage != nil {
+		if err := s.userStorage.AddStorage(userID, size); err != nil {
+			return nil, "", err
+		}
+	}
+
+	path, url, err := s.storage.GetSignedUploadURL(filename, contentType, size, int64(s.options.Expiry.Seconds()))
+	if err != nil {
+		s.releaseQuota(userID, size)
+		return nil, "", err
+	}
+
+	// Leave time to complete uploads that started just before the URL expired
+	reservation := NewReservation(userID, folderID, filename, contentType, size, path, time.Now().Add(2*s.options.Expiry))
+
+	if err := s.repo.Save(reservation); err != nil {
+		s.releaseQuota(userID, size)
+		return nil, "", err
+	}
+
+	return reservation, url, nil
+}
+
+// CompleteUpload verifies the uploaded object and turns the reservation into a file
+func (s *DirectService) CompleteUpload(id, userID string) (*file.File, error) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	reservation, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hide reservations of other users
+	if reservation.UserID != userID {
+		return nil, ErrUploadNotFound
+	}
+
+	if reservation.IsExpired() {
+		s.release(reservation)
+		return nil, ErrUploadExpired
+	}
+
+	info, err := s.storage.Stat(reservation.StoragePath)
+	if err != nil {
+		if err == common.ErrObjectNotFound {
+			// Keep the reservation, the client may still be uploading
+			return nil, ErrObjectNotUploaded
+		}
+		return nil, err
+	}
+
+	// Content type is only checked when the storage backend records it
+	if info.Size != reservation.Size || (info.ContentType != "" && info.ContentType != reservation.ContentType) {
+		s.release(reservation)
+		return nil, ErrObjectMismatch
+	}
+
+	createdFile, err := s.fileService.RegisterReservedFile(
+		reservation.Filename,
+		reservation.Size,
+		reservation.ContentType,
+		reservation.StoragePath,
+		reservation.UserID,
+		reservation.FolderID,
+	)
+	if err != nil {
+		// The stored object has been removed, only the quota is left to release
+		if deleteErr := s.repo.Delete(reservation.ID); deleteErr == nil {
+			s.releaseQuota(reservation.UserID, reservation.Size)
+		}
+		return nil, err
+	}
+
+	// The quota now belongs to the file
+	if err := s.repo.Delete(reservation.ID); err != nil {
+		log.Printf("Error deleting completed upload reservation %s: %v", reservation.ID, err)
+	}
+
+	return createdFile, nil
+}
+
+// ReleaseExpired releases the quota and objects of reservations that were never completed
+func (s *DirectService) ReleaseExpired() error {
+	reservations, err := s.repo.FindExpired(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		unlock := s.locks.lock(reservation.ID)
+		s.release(reservation)
+		unlock()
+	}
+
+	return nil
+}
+
+// release discards a reservation, its quota and any object uploaded for it
+func (s *DirectService) release(reservation *Reservation) {
+	if err := s.repo.Delete(reservation.ID); err != nil {
+		log.Printf("Error deleting upload reservation %s: %v", reservation.ID, err)
+		return
+	}
+
+	s.releaseQuota(reservation.UserID, reservation.Size)
+
+	if err := s.storage.Delete(reservation.StoragePath); err != nil {
+		log.Printf("Error deleting object of upload reservation %s: %v", reservation.ID, err)
+	}
+}
+
+// releaseQuota gives reserved bytes back to the user
+func (s *DirectService) releaseQuota(userID string, size int64) {
+	if s.userStorage == nil {
+		return
+	}
+
+	if err := s.userStorage.RemoveStorage(userID, size); err != nil {
+		log.Printf("Error releasing reserved storage for user %s: %v", userID, err)
+	}
+}
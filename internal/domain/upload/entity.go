@@ -0,0 +1,58 @@
+package upload
+
+import (
+	"time"
+
+	"easy-storage/internal/domain/common"
+)
+
+// Upload represents a resumable upload in progress
+type Upload struct {
+	ID              string
+	UserID          string
+	FolderID        string
+	Filename        string
+	ContentType     string
+	Size            int64 // Total length announced by the client
+	Offset          int64 // Number of bytes received so far
+	StoragePath     string
+	StorageUploadID string                 // ID of the multipart upload in storage
+	Parts           []common.CompletedPart // Parts already uploaded to storage
+	PendingPath     string                 // Bytes received but too few to form a part
+	PendingSize     int64
+	FileID          string // Set once the upload has been turned into a file
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// NewUpload creates a new upload entity
+func NewUpload(userID, folderID, filename, contentType string, size int64, expiresAt time.Time) *Upload {
+	now := time.Now()
+	return &Upload{
+		UserID:      userID,
+		FolderID:    folderID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		Offset:      0,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// IsComplete checks if all bytes of the upload have been received
+func (u *Upload) IsComplete() bool {
+	return u.FileID != ""
+}
+
+// IsExpired checks if the upload can no longer be resumed
+func (u *Upload) IsExpired() bool {
+	return !u.IsComplete() && time.Now().After(u.ExpiresAt)
+}
+
+// CommittedSize returns the number of bytes stored as multipart parts
+func (u *Upload) CommittedSize() int64 {
+	return u.Offset - u.PendingSize
+}
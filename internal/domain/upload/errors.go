@@ -0,0 +1,26 @@
+package upload
+
+import "errors"
+
+var (
+	// ErrUploadNotFound is returned when an upload cannot be found
+	ErrUploadNotFound = errors.New("upload not found")
+
+	// ErrUploadExpired is returned when attempting to resume an expired upload
+	ErrUploadExpired = errors.New("upload has expired")
+
+	// ErrUploadTooLarge is returned when an upload exceeds the maximum allowed size
+	ErrUploadTooLarge = errors.New("upload exceeds maximum size")
+
+	// ErrOffsetMismatch is returned when a chunk does not start at the current upload offset
+	ErrOffsetMismatch = errors.New("upload offset mismatch")
+
+	// ErrInvalidSize is returned when an upload length is negative
+	ErrInvalidSize = errors.New("invalid upload size")
+
+	// ErrObjectNotUploaded is returned when completing a direct upload before the object was stored
+	ErrObjectNotUploaded = errors.New("object has not been uploaded")
+
+	// ErrObjectMismatch is returned when a directly uploaded object does not match its reservation
+	ErrObjectMismatch = errors.New("uploaded object does not match the reservation")
+)
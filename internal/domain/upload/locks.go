@@ -0,0 +1,50 @@
+package upload
+
+import "sync"
+
+// keyedLocks serializes work on the same upload or reservation within this process
+// A mutex only exists while it is held or waited for, so finished uploads leave nothing behind
+type keyedLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is the mutex of a key with the number of callers holding or waiting for it
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock acquires the mutex of a key and returns the function releasing it
+func (l *keyedLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*keyedLock)
+	}
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &keyedLock{}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		l.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// size returns the number of keys currently locked or waited for
+func (l *keyedLocks) size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.locks)
+}
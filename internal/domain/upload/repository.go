@@ -0,0 +1,21 @@
+package upload
+
+import "time"
+
+// Repository defines the interface for upload data access
+type Repository interface {
+	Save(upload *Upload) error
+	FindByID(id string) (*Upload, error)
+	// FindExpired returns uploads, finished or not, that expired before the given time
+	FindExpired(before time.Time) ([]*Upload, error)
+	Delete(id string) error
+}
+
+// ReservationRepository defines the interface for direct upload reservation data access
+type ReservationRepository interface {
+	Save(reservation *Reservation) error
+	FindByID(id string) (*Reservation, error)
+	// FindExpired returns reservations that expired before the given time
+	FindExpired(before time.Time) ([]*Reservation, error)
+	Delete(id string) error
+}
@@ -0,0 +1,35 @@
+package upload
+
+import "time"
+
+// Reservation represents storage quota held for a file uploaded directly to storage
+type Reservation struct {
+	ID          string
+	UserID      string
+	FolderID    string
+	Filename    string
+	ContentType string
+	Size        int64  // Bytes reserved, the uploaded object must have exactly this size
+	StoragePath string // Path the signed upload URL writes to
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+// NewReservation creates a new reservation entity
+func NewReservation(userID, folderID, filename, contentType string, size int64, storagePath string, expiresAt time.Time) *Reservation {
+	return &Reservation{
+		UserID:      userID,
+		FolderID:    folderID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		StoragePath: storagePath,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// IsExpired checks if the reservation can no longer be completed
+func (r *Reservation) IsExpired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
@@ -0,0 +1,384 @@
+package upload
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"time"
+
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/user"
+)
+
+// MinPartSize is the smallest part size accepted by S3 for all but the last part
+const MinPartSize = 5 * 1024 * 1024
+
+// MaxParts is the largest number of parts S3 accepts in a multipart upload
+const MaxParts = 10000
+
+// StorageProvider defines the storage operations needed for resumable uploads
+type StorageProvider interface {
+	common.MultipartStorage
+	Upload(filename string, contentType string, size int64, file io.Reader) (string, error)
+	Download(path string) (io.ReadCloser, error)
+	Delete(path string) error
+}
+
+// Options configures resumable uploads
+type Options struct {
+	MaxSize  int64         // Maximum upload length in bytes
+	PartSize int64         // Size of the parts sent to storage, raised for uploads that would need more than MaxParts
+	Expiry   time.Duration // How long an unfinished upload can be resumed
+}
+
+// Service provides resumable upload operations
+type Service struct {
+	repo        Repository
+	storage     StorageProvider
+	fileService *file.Service
+	userStorage *user.StorageService
+	options     Options
+	locks       keyedLocks // Serializes writes to the same upload within this process
+}
+
+// NewService creates a new upload service
+func NewService(repo Repository, storage StorageProvider, fileService *file.Service, userStorage *user.StorageService, options Options) *Service {
+	if options.PartSize < MinPartSize {
+		options.PartSize = MinPartSize
+	}
+
+	return &Service{
+		repo:        repo,
+		storage:     storage,
+		fileService: fileService,
+		userStorage: userStorage,
+		options:     options,
+	}
+}
+
+// MaxSize returns the maximum upload length in bytes
+func (s *Service) MaxSize() int64 {
+	return s.options.MaxSize
+}
+
+// CreateUpload starts a new resumable upload
+func (s *Service) CreateUpload(userID, folderID, filename, contentType string, size int64) (*Upload, error) {
+	if size < 0 {
+		return nil, ErrInvalidSize
+	}
+	if s.options.MaxSize > 0 && size > s.options.MaxSize {
+		return nil, ErrUploadTooLarge
+	}
+
+	// Validate folder ownership if folderID is provided
+	if err := s.fileService.ValidateFolder(folderID, userID); err != nil {
+		return nil, err
+	}
+
+	// Fail early if the user cannot store the file, quota is charged on completion
+	if s.userStorage != nil {
+		hasQuota, err := s.userStorage.CheckQuota(userID, size)
+		if err != nil {
+			return nil, err
+		}
+		if !hasQuota {
+			return nil, user.ErrStorageQuotaExceeded
+		}
+	}
+
+	upload := NewUpload(userID, folderID, filename, contentType, size, time.Now().Add(s.options.Expiry))
+
+	// Empty files cannot be stored as multipart uploads, store them right away
+	if size == 0 {
+		return upload, s.createEmptyFile(upload)
+	}
+
+	path, uploadID, err := s.storage.CreateMultipartUpload(filename, contentType)
+	if err != nil {
+		return nil, err
+	}
+	upload.StoragePath = path
+	upload.StorageUploadID = uploadID
+
+	if err := s.repo.Save(upload); err != nil {
+		_ = s.storage.AbortMultipartUpload(path, uploadID)
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// GetUpload retrieves an upload owned by the user
+func (s *Service) GetUpload(id, userID string) (*Upload, error) {
+	upload, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hide uploads of other users
+	if upload.UserID != userID {
+		return nil, ErrUploadNotFound
+	}
+
+	return upload, nil
+}
+
+// WriteChunk appends a chunk starting at offset to an upload
+// Bytes received before a read error are kept, so the client can resume from the new offset
+func (s *Service) WriteChunk(id, userID string, offset int64, chunk io.Reader) (*Upload, error) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	upload, err := s.GetUpload(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.IsExpired() {
+		return nil, ErrUploadExpired
+	}
+	if offset != upload.Offset {
+		return upload, ErrOffsetMismatch
+	}
+	if upload.IsComplete() {
+		return upload, nil
+	}
+
+	// Bytes that were too few to form a part are replayed before the new chunk
+	pending, err := s.readPending(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := upload.Size - upload.Offset
+	reader := io.MultiReader(bytes.NewReader(pending), io.LimitReader(chunk, remaining))
+
+	leftover, writeErr := s.writeParts(upload, reader)
+	if err := s.storePending(upload, leftover); err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return upload, writeErr
+	}
+
+	if upload.Offset == upload.Size {
+		if err := s.finalize(upload); err != nil {
+			return nil, err
+		}
+	}
+
+	return upload, nil
+}
+
+// TerminateUpload cancels an upload and discards its data
+func (s *Service) TerminateUpload(id, userID string) error {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	upload, err := s.GetUpload(id, userID)
+	if err != nil {
+		return err
+	}
+
+	s.discard(upload)
+	return s.repo.Delete(id)
+}
+
+// PurgeExpired discards uploads that were not finished before they expired
+// and deletes the records of finished uploads once they expired
+func (s *Service) PurgeExpired() error {
+	now := time.Now()
+	uploads, err := s.repo.FindExpired(now)
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range uploads {
+		s.purge(upload.ID, now)
+	}
+
+	return nil
+}
+
+// purge deletes an upload that had expired before now
+// The upload is read again under its lock, a chunk written meanwhile may have changed it
+func (s *Service) purge(id string, now time.Time) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	upload, err := s.repo.FindByID(id)
+	if err != nil {
+		if err != ErrUploadNotFound {
+			log.Printf("Error loading expired upload %s: %v", id, err)
+		}
+		return
+	}
+	if !upload.ExpiresAt.Before(now) {
+		return
+	}
+
+	s.discard(upload)
+	if err := s.repo.Delete(upload.ID); err != nil {
+		log.Printf("Error deleting expired upload %s: %v", upload.ID, err)
+	}
+}
+
+// writeParts uploads every full part read from reader and returns the bytes left over
+// A final part shorter than the part size is uploaded once the whole upload is received
+func (s *Service) writeParts(upload *Upload, reader io.Reader) ([]byte, error) {
+	committed := upload.CommittedSize()
+	buffer := make([]byte, s.partSize(upload))
+
+	for {
+		n, readErr := io.ReadFull(reader, buffer)
+		isLastPart := n > 0 && committed+int64(n) == upload.Size
+
+		if n < len(buffer) && !isLastPart {
+			upload.Offset = committed + int64(n)
+			if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+				readErr = nil
+			}
+			return buffer[:n], readErr
+		}
+
+		partNumber := int32(len(upload.Parts) + 1)
+		part, err := s.storage.UploadPart(upload.StoragePath, upload.StorageUploadID, partNumber, bytes.NewReader(buffer[:n]), int64(n))
+		if err != nil {
+			upload.Offset = committed + int64(n)
+			return buffer[:n], err
+		}
+
+		upload.Parts = append(upload.Parts, part)
+		committed += int64(n)
+		upload.Offset = committed
+
+		if isLastPart {
+			return nil, nil
+		}
+	}
+}
+
+// partSize returns the size of the parts of an upload
+// Uploads that would need more than MaxParts parts of the configured size use larger parts,
+// rounded up to a whole megabyte, the size only depends on the upload length so it is the same for every chunk
+func (s *Service) partSize(upload *Upload) int64 {
+	const megabyte = 1024 * 1024
+
+	partSize := s.options.PartSize
+	if needed := (upload.Size + MaxParts - 1) / MaxParts; needed > partSize {
+		partSize = (needed + megabyte - 1) / megabyte * megabyte
+	}
+	return partSize
+}
+
+// readPending loads the bytes received after the last uploaded part
+func (s *Service) readPending(upload *Upload) ([]byte, error) {
+	if upload.PendingPath == "" {
+		return nil, nil
+	}
+
+	content, err := s.storage.Download(upload.PendingPath)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	return io.ReadAll(content)
+}
+
+// storePending persists leftover bytes and the new upload state
+func (s *Service) storePending(upload *Upload, leftover []byte) error {
+	previousPath := upload.PendingPath
+	upload.PendingPath = ""
+	upload.PendingSize = 0
+
+	if len(leftover) > 0 {
+		path, err := s.storage.Upload(upload.Filename, upload.ContentType, int64(len(leftover)), bytes.NewReader(leftover))
+		if err != nil {
+			// The leftover bytes are lost, the client will resend them from the committed offset
+			upload.Offset -= int64(len(leftover))
+		} else {
+			upload.PendingPath = path
+			upload.PendingSize = int64(len(leftover))
+		}
+	}
+
+	upload.UpdatedAt = time.Now()
+	if err := s.repo.Save(upload); err != nil {
+		return err
+	}
+
+	if previousPath != "" {
+		if err := s.storage.Delete(previousPath); err != nil {
+			log.Printf("Error deleting pending upload data: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// finalize assembles the uploaded parts and registers the resulting file
+func (s *Service) finalize(upload *Upload) error {
+	if err := s.storage.CompleteMultipartUpload(upload.StoragePath, upload.StorageUploadID, upload.Parts); err != nil {
+		return err
+	}
+
+	createdFile, err := s.fileService.RegisterUploadedFile(
+		upload.Filename,
+		upload.Size,
+		upload.ContentType,
+		upload.StoragePath,
+		upload.UserID,
+		upload.FolderID,
+	)
+	if err != nil {
+		// The stored object has been removed, the upload cannot be resumed
+		_ = s.repo.Delete(upload.ID)
+		return err
+	}
+
+	upload.FileID = createdFile.ID
+	upload.UpdatedAt = time.Now()
+	return s.repo.Save(upload)
+}
+
+// createEmptyFile stores a zero-length upload as a file
+func (s *Service) createEmptyFile(upload *Upload) error {
+	path, err := s.storage.Upload(upload.Filename, upload.ContentType, 0, bytes.NewReader(nil))
+	if err != nil {
+		return err
+	}
+	upload.StoragePath = path
+
+	createdFile, err := s.fileService.RegisterUploadedFile(
+		upload.Filename,
+		0,
+		upload.ContentType,
+		path,
+		upload.UserID,
+		upload.FolderID,
+	)
+	if err != nil {
+		return err
+	}
+
+	upload.FileID = createdFile.ID
+	return s.repo.Save(upload)
+}
+
+// discard releases the storage held by an unfinished upload
+func (s *Service) discard(upload *Upload) {
+	if upload.IsComplete() {
+		return
+	}
+
+	if err := s.storage.AbortMultipartUpload(upload.StoragePath, upload.StorageUploadID); err != nil {
+		log.Printf("Error aborting multipart upload %s: %v", upload.ID, err)
+	}
+
+	if upload.PendingPath != "" {
+		if err := s.storage.Delete(upload.PendingPath); err != nil {
+			log.Printf("Error deleting pending upload data: %v", err)
+		}
+	}
+}
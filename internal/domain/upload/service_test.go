@@ -0,0 +1,432 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/file/filetest"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/domain/user/usertest"
+	"easy-storage/internal/infrastructure/storage/memory"
+
+	"github.com/google/uuid"
+)
+
+// memoryRepository implements Repository in memory
+type memoryRepository struct {
+	mu      sync.Mutex
+	uploads map[string]Upload
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{uploads: make(map[string]Upload)}
+}
+
+func (r *memoryRepository) Save(upload *Upload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if upload.ID == "" {
+		upload.ID = uuid.New().String()
+	}
+	stored := *upload
+	stored.Parts = append([]common.CompletedPart(nil), upload.Parts...)
+	r.uploads[upload.ID] = stored
+	return nil
+}
+
+func (r *memoryRepository) FindByID(id string) (*Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.uploads[id]
+	if !exists {
+		return nil, ErrUploadNotFound
+	}
+	stored.Parts = append([]common.CompletedPart(nil), stored.Parts...)
+	return &stored, nil
+}
+
+func (r *memoryRepository) FindExpired(before time.Time) ([]*Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []*Upload
+	for _, stored := range r.uploads {
+		if stored.ExpiresAt.Before(before) {
+			upload := stored
+			expired = append(expired, &upload)
+		}
+	}
+	return expired, nil
+}
+
+func (r *memoryRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.uploads, id)
+	return nil
+}
+
+// failingReader returns its data and then fails, like a dropped connection
+type failingReader struct {
+	data io.Reader
+}
+
+var errConnectionDropped = errors.New("connection dropped")
+
+func (r *failingReader) Read(buf []byte) (int, error) {
+	n, err := r.data.Read(buf)
+	if err == io.EOF {
+		return n, errConnectionDropped
+	}
+	return n, err
+}
+
+// fixture wires an upload service to in-memory repositories and storage
+type fixture struct {
+	service *Service
+	repo    *memoryRepository
+	files   *filetest.Repository
+	users   *usertest.Repository
+	storage *memory.MemoryProvider
+}
+
+func newFixture(t *testing.T, options Options) *fixture {
+	t.Helper()
+
+	users := usertest.NewRepository()
+	users.AddUser(t, "owner", 1<<30)
+
+	files := filetest.NewService(users, nil)
+	f := &fixture{
+		repo:    newMemoryRepository(),
+		files:   files.Files,
+		users:   users,
+		storage: files.Storage,
+	}
+	f.service = NewService(f.repo, f.storage, files.Service, user.NewStorageService(users), options)
+	return f
+}
+
+// content returns size bytes of recognizable data
+func content(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestWriteChunkKeepsShortChunkPending(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+	data := content(MinPartSize + 1024)
+
+	upload, err := f.service.CreateUpload("owner", "", "video.mp4", "video/mp4", int64(len(data)))
+	if err != nil {
+		t.Fatalf("CreateUpload: %v", err)
+	}
+
+	upload, err = f.service.WriteChunk(upload.ID, "owner", 0, bytes.NewReader(data[:1024]))
+	if err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+
+	if upload.Offset != 1024 || upload.PendingSize != 1024 || len(upload.Parts) != 0 {
+		t.Errorf("upload = offset %d, pending %d, %d parts, want 1024 bytes pending and no part",
+			upload.Offset, upload.PendingSize, len(upload.Parts))
+	}
+}
+
+func TestWriteChunkOffsetMismatch(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+
+	upload, err := f.service.CreateUpload("owner", "", "notes.txt", "text/plain", 100)
+	if err != nil {
+		t.Fatalf("CreateUpload: %v", err)
+	}
+
+	upload, err = f.service.WriteChunk(upload.ID, "owner", 50, bytes.NewReader(content(50)))
+	if err != ErrOffsetMismatch {
+		t.Fatalf("WriteChunk error = %v, want ErrOffsetMismatch", err)
+	}
+	if upload.Offset != 0 {
+		t.Errorf("offset = %d, want the current offset 0", upload.Offset)
+	}
+}
+
+func TestWriteChunkResumesAndCompletes(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+	data := content(2*MinPartSize + 4096)
+
+	upload, err := f.service.CreateUpload("owner", "", "video.mp4", "video/mp4", int64(len(data)))
+	if err != nil {
+		t.Fatalf("CreateUpload: %v", err)
+	}
+
+	// The first request drops after a part and a half
+	dropAt := MinPartSize + MinPartSize/2
+	upload, err = f.service.WriteChunk(upload.ID, "owner", 0, &failingReader{data: bytes.NewReader(data[:dropAt])})
+	if err != errConnectionDropped {
+		t.Fatalf("WriteChunk error = %v, want the read error", err)
+	}
+	if upload.Offset != int64(dropAt) || len(upload.Parts) != 1 {
+		t.Fatalf("upload = offset %d with %d parts, want offset %d with 1 part", upload.Offset, len(upload.Parts), dropAt)
+	}
+
+	// The client asks for the offset and resumes from there
+	stored, err := f.service.GetUpload(upload.ID, "owner")
+	if err != nil {
+		t.Fatalf("GetUpload: %v", err)
+	}
+	upload, err = f.service.WriteChunk(upload.ID, "owner", stored.Offset, bytes.NewReader(data[stored.Offset:]))
+	if err != nil {
+		t.Fatalf("resumed WriteChunk: %v", err)
+	}
+	if !upload.IsComplete() || upload.Offset != int64(len(data)) {
+		t.Fatalf("upload = offset %d, complete %v, want it complete", upload.Offset, upload.IsComplete())
+	}
+
+	created, err := f.files.FindByID(upload.FileID)
+	if err != nil {
+		t.Fatalf("finding the created file: %v", err)
+	}
+	reader, err := f.storage.Download(created.Path)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	stitched, _ := io.ReadAll(reader)
+	if !bytes.Equal(stitched, data) {
+		t.Error("the assembled object differs from the uploaded data")
+	}
+
+	// Only the final object remains, pending bytes were cleaned up
+	if f.storage.Count() != 1 {
+		t.Errorf("storage holds %d objects, want 1", f.storage.Count())
+	}
+	if u, _ := f.users.FindByID("owner"); u.StorageUsed != int64(len(data)) {
+		t.Errorf("storage used = %d, want %d", u.StorageUsed, len(data))
+	}
+}
+
+func TestWriteChunkPartFailureResumesFromCommittedOffset(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+	data := content(MinPartSize + 1024)
+
+	upload, err := f.service.CreateUpload("owner", "", "video.mp4", "video/mp4", int64(len(data)))
+	if err != nil {
+		t.Fatalf("CreateUpload: %v", err)
+	}
+
+	// The part upload fails, its bytes are kept as pending data instead
+	f.storage.FailNthUpload(1)
+	upload, err = f.service.WriteChunk(upload.ID, "owner", 0, bytes.NewReader(data))
+	if err != memory.ErrInjectedFailure {
+		t.Fatalf("WriteChunk error = %v, want ErrInjectedFailure", err)
+	}
+	f.storage.FailNthUpload(0)
+
+	stored, _ := f.service.GetUpload(upload.ID, "owner")
+	upload, err = f.service.WriteChunk(upload.ID, "owner", stored.Offset, bytes.NewReader(data[stored.Offset:]))
+	if err != nil {
+		t.Fatalf("resumed WriteChunk: %v", err)
+	}
+	if !upload.IsComplete() {
+		t.Error("the upload did not complete after resuming")
+	}
+}
+
+func TestCreateUploadEmptyFile(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+
+	upload, err := f.service.CreateUpload("owner", "", "empty.txt", "text/plain", 0)
+	if err != nil {
+		t.Fatalf("CreateUpload: %v", err)
+	}
+	if !upload.IsComplete() {
+		t.Error("an empty upload was not stored right away")
+	}
+}
+
+func TestCreateUploadTooLarge(t *testing.T) {
+	f := newFixture(t, Options{MaxSize: 100, Expiry: time.Hour})
+
+	if _, err := f.service.CreateUpload("owner", "", "big.bin", "application/octet-stream", 101); err != ErrUploadTooLarge {
+		t.Errorf("CreateUpload error = %v, want ErrUploadTooLarge", err)
+	}
+}
+
+func TestTerminateUpload(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+
+	upload, _ := f.service.CreateUpload("owner", "", "notes.txt", "text/plain", 100)
+	if _, err := f.service.WriteChunk(upload.ID, "owner", 0, bytes.NewReader(content(10))); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+
+	if err := f.service.TerminateUpload(upload.ID, "owner"); err != nil {
+		t.Fatalf("TerminateUpload: %v", err)
+	}
+	if _, err := f.service.GetUpload(upload.ID, "owner"); err != ErrUploadNotFound {
+		t.Errorf("GetUpload error = %v, want ErrUploadNotFound", err)
+	}
+	if f.storage.Count() != 0 {
+		t.Errorf("storage holds %d objects after terminating, want 0", f.storage.Count())
+	}
+}
+
+func TestPartSize(t *testing.T) {
+	s := &Service{options: Options{PartSize: MinPartSize}}
+
+	tests := []struct {
+		size int64
+		want int64
+	}{
+		{size: 1024, want: MinPartSize},
+		{size: MinPartSize * MaxParts, want: MinPartSize},
+		{size: MinPartSize*MaxParts + 1, want: 6 * 1024 * 1024},
+		{size: 100 << 30, want: 11 * 1024 * 1024},
+	}
+	for _, tt := range tests {
+		got := s.partSize(&Upload{Size: tt.size})
+		if got != tt.want {
+			t.Errorf("partSize(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+		if got*MaxParts < tt.size {
+			t.Errorf("partSize(%d) = %d needs more than %d parts", tt.size, got, MaxParts)
+		}
+	}
+}
+
+func TestKeyedLocksReleaseEntries(t *testing.T) {
+	var locks keyedLocks
+
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock("upload")
+			counter++
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	if counter != 50 {
+		t.Errorf("counter = %d, want 50", counter)
+	}
+	if locks.size() != 0 {
+		t.Errorf("%d lock entries left after every holder released them", locks.size())
+	}
+}
+
+func TestWriteChunkReleasesLock(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+
+	upload, _ := f.service.CreateUpload("owner", "", "notes.txt", "text/plain", 10)
+	if _, err := f.service.WriteChunk(upload.ID, "owner", 0, bytes.NewReader(content(10))); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if err := f.service.TerminateUpload("unknown", "owner"); err != ErrUploadNotFound {
+		t.Fatalf("TerminateUpload error = %v, want ErrUploadNotFound", err)
+	}
+
+	if f.service.locks.size() != 0 {
+		t.Errorf("%d lock entries outlived their requests", f.service.locks.size())
+	}
+}
+
+// staleRepository lists expired uploads as they were before a chunk changed them
+type staleRepository struct {
+	*memoryRepository
+	expired []*Upload
+}
+
+func (r *staleRepository) FindExpired(before time.Time) ([]*Upload, error) {
+	return r.expired, nil
+}
+
+// expire moves the expiry of a stored upload to the past
+func (f *fixture) expire(t *testing.T, id string) {
+	t.Helper()
+
+	stored, err := f.repo.FindByID(id)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := f.repo.Save(stored); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+
+	unfinished, _ := f.service.CreateUpload("owner", "", "unfinished.bin", "application/octet-stream", 100)
+	if _, err := f.service.WriteChunk(unfinished.ID, "owner", 0, bytes.NewReader(content(10))); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	finished, _ := f.service.CreateUpload("owner", "", "finished.txt", "text/plain", 10)
+	if _, err := f.service.WriteChunk(finished.ID, "owner", 0, bytes.NewReader(content(10))); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	active, _ := f.service.CreateUpload("owner", "", "active.bin", "application/octet-stream", 100)
+
+	f.expire(t, unfinished.ID)
+	f.expire(t, finished.ID)
+
+	if err := f.service.PurgeExpired(); err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+
+	for _, purged := range []*Upload{unfinished, finished} {
+		if _, err := f.repo.FindByID(purged.ID); err != ErrUploadNotFound {
+			t.Errorf("expired upload %s error = %v, want ErrUploadNotFound", purged.Filename, err)
+		}
+	}
+	if _, err := f.repo.FindByID(active.ID); err != nil {
+		t.Errorf("upload that has not expired was purged: %v", err)
+	}
+	// Only the stored file of the finished upload is left
+	if f.storage.Count() != 1 {
+		t.Errorf("storage holds %d objects after purging, want 1", f.storage.Count())
+	}
+}
+
+func TestPurgeExpiredRereadsUploads(t *testing.T) {
+	f := newFixture(t, Options{Expiry: time.Hour})
+
+	upload, _ := f.service.CreateUpload("owner", "", "notes.txt", "text/plain", 100)
+	if _, err := f.service.WriteChunk(upload.ID, "owner", 0, bytes.NewReader(content(10))); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	terminated, _ := f.service.CreateUpload("owner", "", "gone.txt", "text/plain", 100)
+	if err := f.service.TerminateUpload(terminated.ID, "owner"); err != nil {
+		t.Fatalf("TerminateUpload: %v", err)
+	}
+
+	// Both were listed as expired, the first one has since been given a new expiry and the other one removed
+	stale := *upload
+	stale.ExpiresAt = time.Now().Add(-time.Minute)
+	f.service.repo = &staleRepository{memoryRepository: f.repo, expired: []*Upload{&stale, terminated}}
+
+	if err := f.service.PurgeExpired(); err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+
+	if _, err := f.repo.FindByID(upload.ID); err != nil {
+		t.Errorf("upload that is no longer expired was purged: %v", err)
+	}
+	if _, err := f.service.WriteChunk(upload.ID, "owner", 10, bytes.NewReader(content(90))); err != nil {
+		t.Errorf("WriteChunk after the purge: %v", err)
+	}
+}
@@ -10,4 +10,7 @@ type Repository interface {
 	UpdateStorageUsed(userID string, storageUsed int64) error
 	IncrementStorageUsed(userID string, size int64) error
 	DecrementStorageUsed(userID string, size int64) error
+	// MoveStorageUsed moves storage used from one user to another in a single transaction
+	// It fails with ErrStorageQuotaExceeded when the recipient's quota cannot hold the size
+	MoveStorageUsed(fromUserID, toUserID string, size int64) error
 }
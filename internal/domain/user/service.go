@@ -84,6 +84,11 @@ func (s *Service) GetUserByID(id string) (*User, error) {
 	return s.repo.FindByID(id)
 }
 
+// GetUserByEmail retrieves a user by email
+func (s *Service) GetUserByEmail(email string) (*User, error) {
+	return s.repo.FindByEmail(email)
+}
+
 // UpdateUser updates a user's information
 func (s *Service) UpdateUser(user *User) error {
 	return s.repo.Update(user)
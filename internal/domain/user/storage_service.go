@@ -56,6 +56,15 @@ func (s *StorageService) RemoveStorage(userID string, size int64) error {
 	return s.repo.DecrementStorageUsed(userID, size)
 }
 
+// TransferStorage moves storage used from one user to another, e.g. when files change owner
+// The recipient's quota is checked by the same update that charges it
+func (s *StorageService) TransferStorage(fromUserID, toUserID string, size int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.repo.MoveStorageUsed(fromUserID, toUserID, size)
+}
+
 // GetStorageStats gets a user's storage statistics
 func (s *StorageService) GetStorageStats(userID string) (quota int64, used int64, err error) {
 	user, err := s.repo.FindByID(userID)
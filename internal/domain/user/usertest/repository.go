@@ -0,0 +1,153 @@
+// Package usertest provides an in-memory user repository for tests
+package usertest
+
+import (
+	"sync"
+	"testing"
+
+	"easy-storage/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+// Repository implements user.Repository in memory
+type Repository struct {
+	mu    sync.Mutex
+	users map[string]user.User
+}
+
+// NewRepository creates an empty in-memory user repository
+func NewRepository() *Repository {
+	return &Repository{users: make(map[string]user.User)}
+}
+
+// Save stores a user, assigning an ID to new users
+func (r *Repository) Save(u *user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+	r.users[u.ID] = *u
+	return nil
+}
+
+// FindByID finds a user by ID
+func (r *Repository) FindByID(id string) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, exists := r.users[id]
+	if !exists {
+		return nil, user.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+// FindByEmail finds a user by email
+func (r *Repository) FindByEmail(email string) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+// Update overwrites a stored user
+func (r *Repository) Update(u *user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[u.ID]; !exists {
+		return user.ErrUserNotFound
+	}
+	r.users[u.ID] = *u
+	return nil
+}
+
+// Delete removes a user
+func (r *Repository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id)
+	return nil
+}
+
+// UpdateStorageUsed sets the storage used by a user
+func (r *Repository) UpdateStorageUsed(userID string, storageUsed int64) error {
+	return r.updateStorageUsed(userID, func(used int64) int64 { return storageUsed })
+}
+
+// IncrementStorageUsed adds to the storage used by a user
+func (r *Repository) IncrementStorageUsed(userID string, size int64) error {
+	return r.updateStorageUsed(userID, func(used int64) int64 { return used + size })
+}
+
+// DecrementStorageUsed subtracts from the storage used by a user, never going below zero
+func (r *Repository) DecrementStorageUsed(userID string, size int64) error {
+	return r.updateStorageUsed(userID, func(used int64) int64 { return max(used-size, 0) })
+}
+
+// MoveStorageUsed moves storage used from one user to another, checking the recipient's quota
+func (r *Repository) MoveStorageUsed(fromUserID, toUserID string, size int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	from, exists := r.users[fromUserID]
+	if !exists {
+		return user.ErrUserNotFound
+	}
+	to, exists := r.users[toUserID]
+	if !exists {
+		return user.ErrUserNotFound
+	}
+	if to.StorageUsed+size > to.StorageQuota {
+		return user.ErrStorageQuotaExceeded
+	}
+
+	from.StorageUsed = max(from.StorageUsed-size, 0)
+	r.users[fromUserID] = from
+	to.StorageUsed += size
+	r.users[toUserID] = to
+	return nil
+}
+
+// updateStorageUsed applies a change to the storage used by a user
+func (r *Repository) updateStorageUsed(userID string, update func(used int64) int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, exists := r.users[userID]
+	if !exists {
+		return user.ErrUserNotFound
+	}
+	u.StorageUsed = update(u.StorageUsed)
+	r.users[userID] = u
+	return nil
+}
+
+// AddUser stores a user with a storage quota, failing the test when it cannot
+func (r *Repository) AddUser(t testing.TB, id string, quota int64) {
+	t.Helper()
+
+	if err := r.Save(&user.User{ID: id, StorageQuota: quota}); err != nil {
+		t.Fatalf("saving user %s: %v", id, err)
+	}
+}
+
+// StorageUsed returns the storage used by a user, failing the test when the user is missing
+func (r *Repository) StorageUsed(t testing.TB, id string) int64 {
+	t.Helper()
+
+	u, err := r.FindByID(id)
+	if err != nil {
+		t.Fatalf("finding user %s: %v", id, err)
+	}
+	return u.StorageUsed
+}
@@ -12,6 +12,26 @@ type FileResponse struct {
 	UpdatedAt   string `json:"updated_at"`
 }
 
+// UpdateFileRequest represents the request to rename and/or move a file
+// Omitted fields are left unchanged, an empty folder_id moves the file to the root folder
+type UpdateFileRequest struct {
+	Name     *string `json:"name"`
+	FolderID *string `json:"folder_id"`
+}
+
+// CopyFileRequest represents the request to copy a file
+// An empty folder_id copies the file to the root folder, an empty name keeps the file's name
+type CopyFileRequest struct {
+	FolderID string `json:"folder_id"`
+	Name     string `json:"name"`
+}
+
+// ArchiveFilesRequest represents the request to download a selection of files as a ZIP archive
+type ArchiveFilesRequest struct {
+	FileIDs []string `json:"file_ids"`
+	Name    string   `json:"name"`
+}
+
 // UploadFileResponse represents the response for a file upload
 type UploadFileResponse struct {
 	File FileResponse `json:"file"`
@@ -22,3 +42,25 @@ type FilesListResponse struct {
 	Files []FileResponse `json:"files"`
 	Total int            `json:"total"`
 }
+
+// FileVersionResponse represents a file version returned to the client
+type FileVersionResponse struct {
+	Version     int    `json:"version"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	UploadedBy  string `json:"uploaded_by"`
+	IsCurrent   bool   `json:"is_current"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// FileVersionsListResponse represents the versions of a file
+type FileVersionsListResponse struct {
+	Versions []FileVersionResponse `json:"versions"`
+	Total    int                   `json:"total"`
+}
+
+// PruneVersionsRequest represents the retention applied when pruning versions
+type PruneVersionsRequest struct {
+	KeepLast int `json:"keep_last"`
+	KeepDays int `json:"keep_days"`
+}
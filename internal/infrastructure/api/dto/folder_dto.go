@@ -6,6 +6,20 @@ type CreateFolderRequest struct {
 	ParentID string `json:"parent_id,omitempty"`
 }
 
+// UpdateFolderRequest represents the request to rename and/or move a folder
+// Omitted fields are left unchanged, an empty parent_id moves the folder to the root folder
+type UpdateFolderRequest struct {
+	Name     *string `json:"name"`
+	ParentID *string `json:"parent_id"`
+}
+
+// CopyFolderRequest represents the request to copy a folder with its contents
+// An empty parent_id copies the folder to the root folder, an empty name keeps the folder's name
+type CopyFolderRequest struct {
+	ParentID string `json:"parent_id"`
+	Name     string `json:"name"`
+}
+
 // FolderResponse represents folder information returned to the client
 type FolderResponse struct {
 	ID        string `json:"id"`
@@ -30,3 +44,27 @@ type FoldersListResponse struct {
 	Folders    []FolderResponse `json:"folders"`
 	Pagination *PaginationInfo  `json:"pagination,omitempty"`
 }
+
+// TrashedFolderResponse represents a folder in the trash
+type TrashedFolderResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ParentID  string `json:"parent_id,omitempty"`
+	TrashedAt string `json:"trashed_at"`
+}
+
+// TrashedFileResponse represents a file in the trash
+type TrashedFileResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	FolderID    string `json:"folder_id,omitempty"`
+	TrashedAt   string `json:"trashed_at"`
+}
+
+// TrashResponse represents the contents of the trash
+type TrashResponse struct {
+	Folders []TrashedFolderResponse `json:"folders"`
+	Files   []TrashedFileResponse   `json:"files"`
+}
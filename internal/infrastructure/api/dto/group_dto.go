@@ -0,0 +1,42 @@
+package dto
+
+// CreateGroupRequest represents the request to create a group
+type CreateGroupRequest struct {
+	Name string `json:"name"`
+}
+
+// UpdateGroupRequest represents the request to rename a group
+type UpdateGroupRequest struct {
+	Name string `json:"name"`
+}
+
+// AddGroupMemberRequest represents the request to add a user to a group
+// The role defaults to member
+type AddGroupMemberRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role,omitempty"`
+}
+
+// UpdateGroupMemberRequest represents the request to change the role of a group member
+type UpdateGroupMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// GroupResponse represents group information returned to the client
+type GroupResponse struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	CreatedBy string                `json:"created_by"`
+	CreatedAt string                `json:"created_at"`
+	UpdatedAt string                `json:"updated_at"`
+	Members   []GroupMemberResponse `json:"members,omitempty"`
+}
+
+// GroupMemberResponse represents a group member returned to the client
+type GroupMemberResponse struct {
+	UserID   string `json:"user_id"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
@@ -11,9 +11,10 @@ import (
 type CreateShareRequest struct {
 	ResourceID   uuid.UUID  `json:"resource_id" validate:"required"`
 	ResourceType string     `json:"resource_type" validate:"required,oneof=file folder"`
-	ShareType    string     `json:"share_type" validate:"required,oneof=LINK USER"`
-	Permission   string     `json:"permission" validate:"required,oneof=READ WRITE"`
+	ShareType    string     `json:"share_type" validate:"required,oneof=LINK USER GROUP"`
+	Permission   string     `json:"permission" validate:"required,oneof=viewer commenter editor co-owner READ WRITE"`
 	RecipientID  *uuid.UUID `json:"recipient_id,omitempty"`
+	GroupID      *uuid.UUID `json:"group_id,omitempty"`
 	Password     *string    `json:"password,omitempty"`
 	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
 }
@@ -25,7 +26,9 @@ type ShareResponse struct {
 	ResourceType string     `json:"resource_type"`
 	ShareType    string     `json:"share_type"`
 	Permission   string     `json:"permission"`
+	Capabilities []string   `json:"capabilities"`
 	RecipientID  *uuid.UUID `json:"recipient_id,omitempty"`
+	GroupID      *uuid.UUID `json:"group_id,omitempty"`
 	Token        string     `json:"token,omitempty"`
 	HasPassword  bool       `json:"has_password"`
 	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
@@ -0,0 +1,22 @@
+package dto
+
+// CreateTransferRequest represents the request to hand a file or folder over to another user
+// The recipient is given by ID or by email
+type CreateTransferRequest struct {
+	ResourceID     string `json:"resource_id"`
+	ResourceType   string `json:"resource_type"`
+	RecipientID    string `json:"recipient_id,omitempty"`
+	RecipientEmail string `json:"recipient_email,omitempty"`
+}
+
+// TransferResponse represents an ownership transfer returned to the client
+type TransferResponse struct {
+	ID           string  `json:"id"`
+	ResourceID   string  `json:"resource_id"`
+	ResourceType string  `json:"resource_type"`
+	FromUserID   string  `json:"from_user_id"`
+	ToUserID     string  `json:"to_user_id"`
+	Status       string  `json:"status"`
+	CreatedAt    string  `json:"created_at"`
+	RespondedAt  *string `json:"responded_at,omitempty"`
+}
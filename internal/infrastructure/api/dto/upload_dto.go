@@ -0,0 +1,18 @@
+package dto
+
+// InitiateDirectUploadRequest represents the request to upload a file directly to storage
+type InitiateDirectUploadRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	FolderID    string `json:"folder_id,omitempty"`
+}
+
+// InitiateDirectUploadResponse describes how to send the file to storage
+type InitiateDirectUploadResponse struct {
+	ID        string            `json:"id"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"` // Headers the upload request must send unchanged
+	ExpiresAt string            `json:"expires_at"`
+}
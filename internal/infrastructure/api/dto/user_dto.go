@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // RegisterUserRequest represents a user registration request
 type RegisterUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -43,8 +45,78 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// LogoutRequest represents a request to sign out the client of a refresh token
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
 // ChangePasswordRequest represents a password change request
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
 	NewPassword     string `json:"new_password" validate:"required,min=8"`
 }
+
+// SessionResponse represents a session of the current user
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+	Current    bool   `json:"current"` // Whether the request was made with a token of this session
+}
+
+// CreatePersonalTokenRequest represents a request to create a personal access token
+type CreatePersonalTokenRequest struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Omitted for tokens that never expire
+}
+
+// PersonalTokenResponse represents a personal access token of the current user
+type PersonalTokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"` // Last characters of the token
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Token      string     `json:"token,omitempty"` // Only returned when the token is created
+}
+
+// MFARequiredResponse represents the response to a login that needs a second factor
+type MFARequiredResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`  // Exchanged with a code at /api/auth/mfa
+	ExpiresIn   int    `json:"expires_in"` // In seconds
+}
+
+// MFASignInRequest represents a request to complete a login with a second factor
+type MFASignInRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"` // TOTP or recovery code
+}
+
+// MFACodeRequest represents a request confirmed with a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAStatusResponse represents the two-factor authentication of the current user
+type MFAStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// TOTPEnrollmentResponse represents an authenticator waiting to be confirmed
+type TOTPEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth URI, usually shown as a QR code
+}
+
+// RecoveryCodesResponse represents newly generated recovery codes, only shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"log"
+
+	"easy-storage/internal/domain/access"
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/folder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// accessErrorResponse maps authorization errors to responses
+// The message explains what the user is not allowed to do
+func accessErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch err {
+	case file.ErrFileNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "File not found",
+		})
+	case folder.ErrFolderNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Folder not found",
+		})
+	case file.ErrInvalidFolder:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid destination folder",
+		})
+	case access.ErrPermissionDenied:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": message,
+		})
+	default:
+		log.Printf("Error checking permissions: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not check permissions",
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bufio"
+	"io"
+	"log"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/folder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendArchive streams a ZIP archive of the entries as the response body
+// Files are read from storage one at a time and never buffered whole in memory,
+// archives over 4GB or with more than 65535 entries are written as zip64
+func sendArchive(c *fiber.Ctx, fileService *file.Service, name string, entries []folder.ArchiveEntry) error {
+	c.Attachment(name + ".zip")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		archive := zip.NewWriter(w)
+
+		for _, entry := range entries {
+			if err := writeArchiveEntry(archive, fileService, entry); err != nil {
+				// The status is already sent, an archive without central directory tells the client it failed
+				log.Printf("Error writing %s to archive %s: %v", entry.Path, name, err)
+				w.Flush()
+				return
+			}
+		}
+
+		if err := archive.Close(); err != nil {
+			log.Printf("Error closing archive %s: %v", name, err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// writeArchiveEntry adds a folder or the content of a file to an archive
+func writeArchiveEntry(archive *zip.Writer, fileService *file.Service, entry folder.ArchiveEntry) error {
+	if entry.File == nil {
+		_, err := archive.CreateHeader(&zip.FileHeader{
+			Name:   entry.Path,
+			Method: zip.Store,
+		})
+		return err
+	}
+
+	content, err := fileService.GetFileContent(entry.File)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	writer, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     entry.Path,
+		Method:   zip.Deflate,
+		Modified: entry.File.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, content)
+	return err
+}
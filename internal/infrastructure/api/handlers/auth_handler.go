@@ -2,9 +2,11 @@
 package handlers
 
 import (
+	"log"
+
+	"easy-storage/internal/domain/auth"
 	"easy-storage/internal/domain/user"
 	"easy-storage/internal/infrastructure/api/dto"
-	"easy-storage/internal/infrastructure/auth/jwt"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,14 +14,14 @@ import (
 // AuthHandler handles authentication routes
 type AuthHandler struct {
 	userService *user.Service
-	jwtProvider *jwt.Provider
+	authService *auth.Service
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(userService *user.Service, jwtProvider *jwt.Provider) *AuthHandler {
+func NewAuthHandler(userService *user.Service, authService *auth.Service) *AuthHandler {
 	return &AuthHandler{
 		userService: userService,
-		jwtProvider: jwtProvider,
+		authService: authService,
 	}
 }
 
@@ -43,21 +45,14 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		})
 	}
 
-	// Generate tokens
-	accessToken, err := h.jwtProvider.GenerateToken(newUser)
+	// Generate tokens, starting a new session
+	tokens, err := h.authService.SignIn(newUser, authClient(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate token",
 		})
 	}
 
-	refreshToken, err := h.jwtProvider.GenerateRefreshToken(newUser)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate refresh token",
-		})
-	}
-
 	// Return user info and tokens
 	return c.Status(fiber.StatusCreated).JSON(dto.AuthResponse{
 		User: dto.UserResponse{
@@ -67,8 +62,8 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 			StorageQuota: newUser.StorageQuota,
 			StorageUsed:  newUser.StorageUsed,
 		},
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		ExpiresIn:    24 * 60 * 60, // 24 hours in seconds
 	})
 }
@@ -95,21 +90,51 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	// Generate tokens
-	accessToken, err := h.jwtProvider.GenerateToken(authenticatedUser)
+	// Generate tokens, starting a new session, unless the user needs a second factor
+	tokens, mfaToken, err := h.authService.StartSignIn(authenticatedUser, authClient(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate token",
 		})
 	}
+	if mfaToken != "" {
+		return c.Status(fiber.StatusOK).JSON(dto.MFARequiredResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   5 * 60, // 5 minutes in seconds
+		})
+	}
 
-	refreshToken, err := h.jwtProvider.GenerateRefreshToken(authenticatedUser)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate refresh token",
+	// Return user info and tokens
+	return c.Status(fiber.StatusOK).JSON(dto.AuthResponse{
+		User: dto.UserResponse{
+			ID:           authenticatedUser.ID,
+			Email:        authenticatedUser.Email,
+			Name:         authenticatedUser.Name,
+			StorageQuota: authenticatedUser.StorageQuota,
+			StorageUsed:  authenticatedUser.StorageUsed,
+		},
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    24 * 60 * 60, // 24 hours in seconds
+	})
+}
+
+// VerifyMFA handles completing a login with a TOTP or recovery code
+func (h *AuthHandler) VerifyMFA(c *fiber.Ctx) error {
+	var req dto.MFASignInRequest
+	if err := c.BodyParser(&req); err != nil || req.MFAToken == "" || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "MFA token and code are required",
 		})
 	}
 
+	// Check the code, then start the session the login was waiting for
+	tokens, authenticatedUser, err := h.authService.CompleteMFASignIn(req.MFAToken, req.Code, authClient(c))
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to generate token")
+	}
+
 	// Return user info and tokens
 	return c.Status(fiber.StatusOK).JSON(dto.AuthResponse{
 		User: dto.UserResponse{
@@ -119,13 +144,14 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 			StorageQuota: authenticatedUser.StorageQuota,
 			StorageUsed:  authenticatedUser.StorageUsed,
 		},
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		ExpiresIn:    24 * 60 * 60, // 24 hours in seconds
 	})
 }
 
 // RefreshToken handles token refresh
+// Every refresh token can be used once, the response carries the next one
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	var req dto.RefreshTokenRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -134,43 +160,66 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate refresh token
-	claims, err := h.jwtProvider.ValidateToken(req.RefreshToken)
+	// Exchange the refresh token, replaying a used one signs out its whole session
+	tokens, _, err := h.authService.Refresh(req.RefreshToken, authClient(c))
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid refresh token",
+		switch err {
+		case auth.ErrInvalidRefreshToken:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid refresh token",
+			})
+		case auth.ErrRefreshTokenReused:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Refresh token already used, please sign in again",
+			})
+		}
+		log.Printf("Error refreshing token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate token",
 		})
 	}
 
-	// Get user from claims
-	user, err := h.userService.GetUserByID(claims.UserID)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "User not found",
+	// Return new tokens
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    24 * 60 * 60, // 24 hours in seconds
+	})
+}
+
+// Logout handles signing out the session of a refresh token
+// Its access tokens are rejected once the session cache of every API instance sees the revocation
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req dto.LogoutRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Refresh token is required",
 		})
 	}
 
-	// Generate new tokens
-	accessToken, err := h.jwtProvider.GenerateToken(user)
-	if err != nil {
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		log.Printf("Error revoking refresh token: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate token",
+			"error": "Failed to log out",
 		})
 	}
 
-	refreshToken, err := h.jwtProvider.GenerateRefreshToken(user)
-	if err != nil {
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// LogoutAll handles signing the current user out of every session
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		log.Printf("Error revoking refresh tokens of %s: %v", userID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate refresh token",
+			"error": "Failed to log out",
 		})
 	}
 
-	// Return new tokens
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-		"expires_in":    24 * 60 * 60, // 24 hours in seconds
-	})
+	return c.SendStatus(fiber.StatusNoContent)
 }
 
 // GetMe retrieves the current user
@@ -245,3 +294,11 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		"message": "Password changed successfully",
 	})
 }
+
+// authClient identifies the device of a sign in or refresh request
+func authClient(c *fiber.Ctx) auth.Client {
+	return auth.Client{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/upload"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/infrastructure/api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DirectUploadHandler handles uploads sent by clients straight to storage
+type DirectUploadHandler struct {
+	directService *upload.DirectService
+}
+
+// NewDirectUploadHandler creates a new direct upload handler
+func NewDirectUploadHandler(directService *upload.DirectService) *DirectUploadHandler {
+	return &DirectUploadHandler{
+		directService: directService,
+	}
+}
+
+// InitiateUpload reserves quota and returns a signed URL the client uploads the file to
+func (h *DirectUploadHandler) InitiateUpload(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.InitiateDirectUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Filename == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Filename is required",
+		})
+	}
+
+	if req.ContentType == "" {
+		req.ContentType = "application/octet-stream"
+	}
+
+	reservation, uploadURL, err := h.directService.InitiateUpload(userID, req.FolderID, req.Filename, req.ContentType, req.Size)
+	if err != nil {
+		return directUploadErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.InitiateDirectUploadResponse{
+		ID:        reservation.ID,
+		UploadURL: uploadURL,
+		Method:    fiber.MethodPut,
+		Headers: map[string]string{
+			fiber.HeaderContentType:   reservation.ContentType,
+			fiber.HeaderContentLength: strconv.FormatInt(reservation.Size, 10),
+		},
+		ExpiresAt: reservation.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// CompleteUpload verifies the uploaded object and creates the file
+func (h *DirectUploadHandler) CompleteUpload(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	createdFile, err := h.directService.CompleteUpload(c.Params("id"), userID)
+	if err != nil {
+		return directUploadErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.FileResponse{
+		ID:          createdFile.ID,
+		Name:        createdFile.Name,
+		Size:        createdFile.Size,
+		ContentType: createdFile.ContentType,
+		FolderID:    createdFile.FolderID,
+		CreatedAt:   createdFile.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   createdFile.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// directUploadErrorResponse maps direct upload errors to responses
+func directUploadErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case upload.ErrUploadNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Upload not found",
+		})
+	case upload.ErrUploadExpired:
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "Upload has expired",
+		})
+	case upload.ErrObjectNotUploaded:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "File has not been uploaded yet",
+		})
+	case upload.ErrObjectMismatch:
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "Uploaded file does not match the announced size or content type",
+		})
+	case upload.ErrUploadTooLarge:
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": "Upload exceeds the maximum size",
+		})
+	case upload.ErrInvalidSize:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid size",
+		})
+	case file.ErrInvalidFolder:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid folder",
+		})
+	case user.ErrStorageQuotaExceeded:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Storage quota exceeded",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not process upload",
+		})
+	}
+}
@@ -6,7 +6,10 @@ import (
 
 	"easy-storage/internal/domain/access"
 	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/folder"
+	"easy-storage/internal/domain/share"
 	"easy-storage/internal/domain/user"
+	"easy-storage/internal/infrastructure/api/dto"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -33,8 +36,14 @@ func (h *FileHandler) UploadFile(c *fiber.Ctx) error {
 	// Get folder ID from query parameter (optional)
 	folderID := c.Query("folder_id", "")
 
+	// Files uploaded into a folder shared with the user belong to the folder's owner
+	ownerID, err := h.accessService.AuthorizeUpload(c.Context(), userID, folderID)
+	if err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to upload into this folder")
+	}
+
 	// Get file from form
-	file, err := c.FormFile("file")
+	formFile, err := c.FormFile("file")
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "No file provided",
@@ -42,14 +51,14 @@ func (h *FileHandler) UploadFile(c *fiber.Ctx) error {
 	}
 
 	// Check file size (example: limit to 100MB)
-	if file.Size > 100*1024*1024 {
+	if formFile.Size > 100*1024*1024 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "File too large, maximum size is 100MB",
 		})
 	}
 
 	// Open uploaded file
-	src, err := file.Open()
+	src, err := formFile.Open()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Could not open uploaded file",
@@ -58,20 +67,33 @@ func (h *FileHandler) UploadFile(c *fiber.Ctx) error {
 	defer src.Close()
 
 	// Determine content type
-	contentType := file.Header.Get("Content-Type")
+	contentType := formFile.Header.Get("Content-Type")
 	if contentType == "" {
 		contentType = "application/octet-stream"
 	}
 
-	// Upload file
-	uploadedFile, err := h.fileService.UploadFile(
-		file.Filename,
-		file.Size,
-		contentType,
-		src,
-		userID,
-		folderID,
-	)
+	// Upload file, uploads into shared folders never replace the owner's files
+	var uploadedFile *file.File
+	if ownerID == userID {
+		uploadedFile, err = h.fileService.UploadFile(
+			formFile.Filename,
+			formFile.Size,
+			contentType,
+			src,
+			userID,
+			folderID,
+		)
+	} else {
+		uploadedFile, err = h.fileService.UploadNewFile(
+			formFile.Filename,
+			formFile.Size,
+			contentType,
+			src,
+			ownerID,
+			folderID,
+			userID,
+		)
+	}
 	if err != nil {
 		if err == user.ErrStorageQuotaExceeded {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -108,14 +130,6 @@ func (h *FileHandler) DownloadFile(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if file belongs to user or has been shared with user
-	hasAccess, err := h.accessService.CheckFileAccess(c.Context(), fileID, userID)
-	if err != nil || !hasAccess {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You don't have permission to access this file",
-		})
-	}
-
 	// Get file from service
 	downloadedFile, err := h.fileService.GetFile(fileID)
 	if err != nil {
@@ -129,6 +143,11 @@ func (h *FileHandler) DownloadFile(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check if the user's role on the file allows downloads
+	if err := h.accessService.AuthorizeFile(c.Context(), userID, downloadedFile, share.CapDownload); err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to download this file")
+	}
+
 	// Get signed URL (valid for 1 hour = 3600 seconds)
 	signedURL, err := h.fileService.GetFileSignedURL(downloadedFile, 3600)
 	if err != nil {
@@ -222,7 +241,7 @@ func (h *FileHandler) DeleteFile(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get file from service to check ownership
+	// Get file from service to check permissions
 	deletedFile, err := h.fileService.GetFile(fileID)
 	if err != nil {
 		if err == file.ErrFileNotFound {
@@ -235,11 +254,9 @@ func (h *FileHandler) DeleteFile(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if file belongs to user
-	if deletedFile.UserID != userID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You don't have permission to delete this file",
-		})
+	// Check if the user's role on the file allows deleting it
+	if err := h.accessService.AuthorizeFile(c.Context(), userID, deletedFile, share.CapDelete); err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to delete this file")
 	}
 
 	// Delete file
@@ -251,3 +268,183 @@ func (h *FileHandler) DeleteFile(c *fiber.Ctx) error {
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// UpdateFile handles renaming and moving a file
+func (h *FileHandler) UpdateFile(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.UpdateFileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Get file from service to check permissions
+	existingFile, err := h.fileService.GetFile(c.Params("id"))
+	if err != nil {
+		if err == file.ErrFileNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "File not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not retrieve file",
+		})
+	}
+
+	// Check if the user's role on the file allows renaming and moving it
+	if err := h.accessService.AuthorizeFile(c.Context(), userID, existingFile, share.CapRename); err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to modify this file")
+	}
+
+	// Keep the current values of omitted fields
+	name := existingFile.Name
+	if req.Name != nil {
+		name = *req.Name
+	}
+	folderID := existingFile.FolderID
+	if req.FolderID != nil {
+		folderID = *req.FolderID
+	}
+
+	// Moving the file also requires adding files to the destination
+	if folderID != existingFile.FolderID {
+		if err := h.accessService.AuthorizeDestination(c.Context(), userID, existingFile.UserID, folderID); err != nil {
+			return accessErrorResponse(c, err, "You don't have permission to move files into this folder")
+		}
+	}
+
+	updatedFile, err := h.fileService.MoveFile(existingFile, folderID, name)
+	if err != nil {
+		switch err {
+		case file.ErrInvalidName:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid file name",
+			})
+		case file.ErrInvalidFolder:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid folder",
+			})
+		case file.ErrNameConflict:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "A file with this name already exists in the folder",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not update file",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fileResponse(updatedFile))
+}
+
+// CopyFile handles copying a file into a folder
+func (h *FileHandler) CopyFile(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.CopyFileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Get file from service to check permissions
+	existingFile, err := h.fileService.GetFile(c.Params("id"))
+	if err != nil {
+		if err == file.ErrFileNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "File not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not retrieve file",
+		})
+	}
+
+	// The copy is made for the owner, in a folder the user can add files to
+	if err := h.accessService.AuthorizeFile(c.Context(), userID, existingFile, share.CapDownload); err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to copy this file")
+	}
+	if err := h.accessService.AuthorizeDestination(c.Context(), userID, existingFile.UserID, req.FolderID); err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to copy files into this folder")
+	}
+
+	copiedFile, err := h.fileService.CopyFile(existingFile, req.FolderID, req.Name)
+	if err != nil {
+		switch err {
+		case file.ErrInvalidName:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid file name",
+			})
+		case file.ErrInvalidFolder:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid folder",
+			})
+		case user.ErrStorageQuotaExceeded:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Storage quota exceeded",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not copy file",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fileResponse(copiedFile))
+}
+
+// maxArchiveFiles bounds the number of files in a selection archive
+const maxArchiveFiles = 1000
+
+// DownloadArchive streams a selection of files as a ZIP archive
+func (h *FileHandler) DownloadArchive(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.ArchiveFilesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if len(req.FileIDs) == 0 || len(req.FileIDs) > maxArchiveFiles {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Between 1 and %d file IDs are required", maxArchiveFiles),
+		})
+	}
+
+	name := req.Name
+	if name == "" {
+		name = "files"
+	}
+
+	files := make([]*file.File, 0, len(req.FileIDs))
+	for _, fileID := range req.FileIDs {
+		selectedFile, err := h.fileService.GetFile(fileID)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "File not found",
+			})
+		}
+
+		// Check if the user's role on the file allows downloads
+		if err := h.accessService.AuthorizeFile(c.Context(), userID, selectedFile, share.CapDownload); err != nil {
+			return accessErrorResponse(c, err, "You don't have permission to download file "+fileID)
+		}
+		files = append(files, selectedFile)
+	}
+
+	entries, err := folder.FileArchiveEntries(files)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not prepare archive",
+		})
+	}
+
+	return sendArchive(c, h.fileService, name, entries)
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"time"
+
+	"easy-storage/internal/domain/access"
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/share"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/infrastructure/api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FileVersionHandler handles file version API endpoints
+type FileVersionHandler struct {
+	fileService   *file.Service
+	accessService *access.Service
+}
+
+// NewFileVersionHandler creates a new file version handler
+func NewFileVersionHandler(fileService *file.Service, accessService *access.Service) *FileVersionHandler {
+	return &FileVersionHandler{
+		fileService:   fileService,
+		accessService: accessService,
+	}
+}
+
+// ListVersions lists the versions of a file, newest first
+func (h *FileVersionHandler) ListVersions(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	currentFile, err := h.getAuthorizedFile(c, userID, share.CapView)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	versions, err := h.fileService.ListVersions(currentFile.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not list versions",
+		})
+	}
+
+	versionResponses := make([]dto.FileVersionResponse, len(versions))
+	for i, version := range versions {
+		versionResponses[i] = dto.FileVersionResponse{
+			Version:     version.Version,
+			Size:        version.Size,
+			ContentType: version.ContentType,
+			UploadedBy:  version.UserID,
+			IsCurrent:   version.Version == currentFile.Version,
+			CreatedAt:   version.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.FileVersionsListResponse{
+		Versions: versionResponses,
+		Total:    len(versionResponses),
+	})
+}
+
+// UploadVersion uploads new content for an existing file
+func (h *FileVersionHandler) UploadVersion(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	existingFile, err := h.getAuthorizedFile(c, userID, share.CapUpload)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	// Get file from form
+	formFile, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No file provided",
+		})
+	}
+
+	// Check file size, same limit as regular uploads
+	if formFile.Size > 100*1024*1024 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "File too large, maximum size is 100MB",
+		})
+	}
+
+	// Open uploaded file
+	src, err := formFile.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not open uploaded file",
+		})
+	}
+	defer src.Close()
+
+	// Determine content type
+	contentType := formFile.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	updatedFile, err := h.fileService.UploadVersion(existingFile, formFile.Size, contentType, src, userID)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fileResponse(updatedFile))
+}
+
+// DownloadVersion returns a signed URL for a specific version of a file
+func (h *FileVersionHandler) DownloadVersion(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	versionNumber, err := c.ParamsInt("version")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid version",
+		})
+	}
+
+	currentFile, err := h.getAuthorizedFile(c, userID, share.CapDownload)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	version, err := h.fileService.GetVersion(currentFile.ID, versionNumber)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	// Get signed URL (valid for 1 hour = 3600 seconds)
+	signedURL, err := h.fileService.GetVersionSignedURL(version, 3600)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not generate download URL",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"url":          signedURL,
+		"expires_in":   3600,
+		"filename":     currentFile.Name,
+		"version":      version.Version,
+		"content_type": version.ContentType,
+		"size":         version.Size,
+	})
+}
+
+// RestoreVersion makes an older version the current content of a file
+func (h *FileVersionHandler) RestoreVersion(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	versionNumber, err := c.ParamsInt("version")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid version",
+		})
+	}
+
+	existingFile, err := h.getAuthorizedFile(c, userID, share.CapUpload)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	restoredFile, err := h.fileService.RestoreVersion(existingFile, versionNumber)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fileResponse(restoredFile))
+}
+
+// DeleteVersion deletes a version that is not the current one
+func (h *FileVersionHandler) DeleteVersion(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	versionNumber, err := c.ParamsInt("version")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid version",
+		})
+	}
+
+	existingFile, err := h.getAuthorizedFile(c, userID, share.CapDelete)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	if err := h.fileService.DeleteVersion(existingFile, versionNumber); err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PruneVersions deletes old versions of a file according to the requested retention
+func (h *FileVersionHandler) PruneVersions(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.PruneVersionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.KeepLast < 0 || req.KeepDays < 0 || (req.KeepLast == 0 && req.KeepDays == 0) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "keep_last or keep_days must be a positive number",
+		})
+	}
+
+	existingFile, err := h.getAuthorizedFile(c, userID, share.CapDelete)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	pruned, err := h.fileService.PruneVersions(existingFile.ID, file.VersionRetention{
+		KeepLast: req.KeepLast,
+		KeepDays: req.KeepDays,
+	})
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"pruned": pruned,
+	})
+}
+
+// getAuthorizedFile loads the file of the request and checks that the user's role on it allows a capability
+func (h *FileVersionHandler) getAuthorizedFile(c *fiber.Ctx, userID string, capability share.Capability) (*file.File, error) {
+	existingFile, err := h.fileService.GetFile(c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.accessService.AuthorizeFile(c.Context(), userID, existingFile, capability); err != nil {
+		return nil, err
+	}
+
+	return existingFile, nil
+}
+
+// fileResponse converts a file into its API representation
+func fileResponse(f *file.File) dto.FileResponse {
+	return dto.FileResponse{
+		ID:          f.ID,
+		Name:        f.Name,
+		Size:        f.Size,
+		ContentType: f.ContentType,
+		FolderID:    f.FolderID,
+		CreatedAt:   f.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   f.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// versionErrorResponse maps file version errors to responses
+func versionErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case file.ErrFileNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "File not found",
+		})
+	case access.ErrPermissionDenied:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to modify this file",
+		})
+	case file.ErrVersionNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Version not found",
+		})
+	case file.ErrCurrentVersion:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Cannot delete the current version, restore another version first",
+		})
+	case user.ErrStorageQuotaExceeded:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Storage quota exceeded",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not process file version",
+		})
+	}
+}
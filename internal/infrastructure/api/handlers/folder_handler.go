@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"easy-storage/internal/domain/access"
+	"easy-storage/internal/domain/file"
 	"easy-storage/internal/domain/folder"
+	"easy-storage/internal/domain/share"
+	"easy-storage/internal/domain/user"
 	"easy-storage/internal/infrastructure/api/dto"
 	"log"
 	"time"
@@ -12,12 +16,16 @@ import (
 // FolderHandler handles folder-related API endpoints
 type FolderHandler struct {
 	folderService *folder.Service
+	fileService   *file.Service
+	accessService *access.Service
 }
 
 // NewFolderHandler creates a new folder handler
-func NewFolderHandler(folderService *folder.Service) *FolderHandler {
+func NewFolderHandler(folderService *folder.Service, fileService *file.Service, accessService *access.Service) *FolderHandler {
 	return &FolderHandler{
 		folderService: folderService,
+		fileService:   fileService,
+		accessService: accessService,
 	}
 }
 
@@ -41,8 +49,14 @@ func (h *FolderHandler) CreateFolder(c *fiber.Ctx) error {
 		})
 	}
 
+	// Folders created inside a folder shared with the user belong to the folder's owner
+	ownerID, err := h.accessService.AuthorizeUpload(c.Context(), userID, req.ParentID)
+	if err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to create folders in this folder")
+	}
+
 	// Create folder
-	createdFolder, err := h.folderService.CreateFolder(req.Name, req.ParentID, userID)
+	createdFolder, err := h.folderService.CreateFolder(req.Name, req.ParentID, ownerID)
 	if err != nil {
 		if err == folder.ErrInvalidParent {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -152,8 +166,14 @@ func (h *FolderHandler) GetFolderContents(c *fiber.Ctx) error {
 	// Get folder ID from parameter
 	folderID := c.Params("folder_id")
 
+	// Get folder the user owns or that is inside a folder shared with them
+	existingFolder, err := h.getAuthorizedFolder(c, folderID, userID, share.CapView)
+	if err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to view this folder")
+	}
+
 	// Get folder contents
-	folders, files, err := h.folderService.GetFolderContents(folderID, userID)
+	folders, files, err := h.folderService.GetFolderContents(folderID, existingFolder.UserID)
 	if err != nil {
 		if err == folder.ErrFolderNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -215,8 +235,14 @@ func (h *FolderHandler) DeleteFolder(c *fiber.Ctx) error {
 		})
 	}
 
-	// Delete folder and all its contents
-	err := h.folderService.DeleteFolder(folderID, userID)
+	// Check if the user's role on the folder allows deleting it
+	existingFolder, err := h.getAuthorizedFolder(c, folderID, userID, share.CapDelete)
+	if err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to delete this folder")
+	}
+
+	// Move folder and all its contents to the owner's trash
+	err = h.folderService.DeleteFolder(folderID, existingFolder.UserID)
 	if err != nil {
 		if err == folder.ErrFolderNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -230,6 +256,165 @@ func (h *FolderHandler) DeleteFolder(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Folder deleted successfully",
+		"message": "Folder moved to trash",
 	})
 }
+
+// UpdateFolder handles renaming and moving a folder
+func (h *FolderHandler) UpdateFolder(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.UpdateFolderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Get folder and check that the user's role allows renaming and moving it
+	existingFolder, err := h.getAuthorizedFolder(c, c.Params("folder_id"), userID, share.CapRename)
+	if err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to modify this folder")
+	}
+
+	// Keep the current values of omitted fields
+	name := existingFolder.Name
+	if req.Name != nil {
+		name = *req.Name
+	}
+	parentID := existingFolder.ParentID
+	if req.ParentID != nil {
+		parentID = *req.ParentID
+	}
+
+	// Moving the folder also requires adding folders to the destination
+	if parentID != existingFolder.ParentID {
+		if err := h.accessService.AuthorizeDestination(c.Context(), userID, existingFolder.UserID, parentID); err != nil {
+			return accessErrorResponse(c, err, "You don't have permission to move folders into this folder")
+		}
+	}
+
+	updatedFolder, err := h.folderService.MoveFolder(existingFolder, parentID, name)
+	if err != nil {
+		switch err {
+		case folder.ErrInvalidName:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid folder name",
+			})
+		case folder.ErrInvalidParent:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid parent folder",
+			})
+		case folder.ErrFolderCycle:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Cannot move a folder into itself or one of its subfolders",
+			})
+		case folder.ErrNameConflict:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "A folder with this name already exists in the parent folder",
+			})
+		}
+		log.Printf("Error updating folder: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not update folder",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.FolderResponse{
+		ID:        updatedFolder.ID,
+		Name:      updatedFolder.Name,
+		ParentID:  updatedFolder.ParentID,
+		CreatedAt: updatedFolder.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: updatedFolder.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// CopyFolder handles copying a folder with all its files and subfolders
+func (h *FolderHandler) CopyFolder(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.CopyFolderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// The copy is made for the owner, in a folder the user can add folders to
+	existingFolder, err := h.getAuthorizedFolder(c, c.Params("folder_id"), userID, share.CapDownload)
+	if err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to copy this folder")
+	}
+	if err := h.accessService.AuthorizeDestination(c.Context(), userID, existingFolder.UserID, req.ParentID); err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to copy folders into this folder")
+	}
+
+	copiedFolder, err := h.folderService.CopyFolder(existingFolder, req.ParentID, req.Name)
+	if err != nil {
+		switch err {
+		case folder.ErrInvalidName:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid folder name",
+			})
+		case folder.ErrInvalidParent:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid parent folder",
+			})
+		case user.ErrStorageQuotaExceeded:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Storage quota exceeded",
+			})
+		}
+		log.Printf("Error copying folder: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not copy folder",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.FolderResponse{
+		ID:        copiedFolder.ID,
+		Name:      copiedFolder.Name,
+		ParentID:  copiedFolder.ParentID,
+		CreatedAt: copiedFolder.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: copiedFolder.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// DownloadFolder streams a folder and its subtree as a ZIP archive
+func (h *FolderHandler) DownloadFolder(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Get folder the user owns or that is inside a folder shared with them
+	existingFolder, err := h.getAuthorizedFolder(c, c.Params("folder_id"), userID, share.CapDownload)
+	if err != nil {
+		return accessErrorResponse(c, err, "You don't have permission to download this folder")
+	}
+
+	entries, err := h.folderService.ListArchiveEntries(existingFolder)
+	if err != nil {
+		log.Printf("Error listing folder contents for archive: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not prepare archive",
+		})
+	}
+
+	return sendArchive(c, h.fileService, existingFolder.Name, entries)
+}
+
+// getAuthorizedFolder gets a folder and checks that the user's role on it allows a capability
+// Folders the user has no role on are reported as not found
+func (h *FolderHandler) getAuthorizedFolder(c *fiber.Ctx, folderID, userID string, capability share.Capability) (*folder.Folder, error) {
+	existingFolder, err := h.folderService.GetFolder(folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.accessService.AuthorizeFolder(c.Context(), userID, existingFolder, capability); err != nil {
+		return nil, err
+	}
+
+	return existingFolder, nil
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"easy-storage/internal/domain/group"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/infrastructure/api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GroupHandler handles group-related API endpoints
+type GroupHandler struct {
+	groupService *group.Service
+	userService  *user.Service
+}
+
+// NewGroupHandler creates a new group handler
+func NewGroupHandler(groupService *group.Service, userService *user.Service) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+		userService:  userService,
+	}
+}
+
+// CreateGroup handles group creation, the creator becomes its owner
+func (h *GroupHandler) CreateGroup(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.CreateGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	newGroup, err := h.groupService.CreateGroup(req.Name, userID)
+	if err != nil {
+		return groupErrorResponse(c, err, "Could not create group")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(buildGroupResponse(newGroup, nil))
+}
+
+// ListGroups handles listing the groups the current user is a member of
+func (h *GroupHandler) ListGroups(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	groups, err := h.groupService.ListUserGroups(userID)
+	if err != nil {
+		return groupErrorResponse(c, err, "Could not list groups")
+	}
+
+	groupResponses := make([]dto.GroupResponse, len(groups))
+	for i, g := range groups {
+		groupResponses[i] = buildGroupResponse(g, nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"groups": groupResponses,
+		"total":  len(groupResponses),
+	})
+}
+
+// GetGroup handles retrieving a group with its members
+func (h *GroupHandler) GetGroup(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+	groupID := c.Params("id")
+
+	existingGroup, err := h.groupService.GetGroup(groupID, userID)
+	if err != nil {
+		return groupErrorResponse(c, err, "Could not retrieve group")
+	}
+
+	members, err := h.groupService.ListMembers(groupID, userID)
+	if err != nil {
+		return groupErrorResponse(c, err, "Could not list group members")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(buildGroupResponse(existingGroup, h.buildMemberResponses(members)))
+}
+
+// UpdateGroup handles renaming a group
+func (h *GroupHandler) UpdateGroup(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.UpdateGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	updatedGroup, err := h.groupService.RenameGroup(c.Params("id"), userID, req.Name)
+	if err != nil {
+		return groupErrorResponse(c, err, "Could not update group")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(buildGroupResponse(updatedGroup, nil))
+}
+
+// DeleteGroup handles deleting a group
+func (h *GroupHandler) DeleteGroup(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.groupService.DeleteGroup(c.Params("id"), userID); err != nil {
+		return groupErrorResponse(c, err, "Could not delete group")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// AddMember handles adding a user to a group
+func (h *GroupHandler) AddMember(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.AddGroupMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role := group.RoleMember
+	if req.Role != "" {
+		role = group.Role(req.Role)
+	}
+
+	// Check that the user exists
+	if _, err := h.userService.GetUserByID(req.UserID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	member, err := h.groupService.AddMember(c.Params("id"), userID, req.UserID, role)
+	if err != nil {
+		return groupErrorResponse(c, err, "Could not add group member")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(h.buildMemberResponses([]*group.Member{member})[0])
+}
+
+// UpdateMember handles changing the role of a group member
+func (h *GroupHandler) UpdateMember(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.UpdateGroupMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	member, err := h.groupService.UpdateMemberRole(c.Params("id"), userID, c.Params("user_id"), group.Role(req.Role))
+	if err != nil {
+		return groupErrorResponse(c, err, "Could not update group member")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(h.buildMemberResponses([]*group.Member{member})[0])
+}
+
+// RemoveMember handles removing a user from a group, members can remove themselves to leave it
+func (h *GroupHandler) RemoveMember(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.groupService.RemoveMember(c.Params("id"), userID, c.Params("user_id")); err != nil {
+		return groupErrorResponse(c, err, "Could not remove group member")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// buildMemberResponses converts members into responses with their name and email
+func (h *GroupHandler) buildMemberResponses(members []*group.Member) []dto.GroupMemberResponse {
+	responses := make([]dto.GroupMemberResponse, len(members))
+	for i, member := range members {
+		responses[i] = dto.GroupMemberResponse{
+			UserID:   member.UserID,
+			Role:     string(member.Role),
+			JoinedAt: member.JoinedAt.Format(time.RFC3339),
+		}
+
+		if memberUser, err := h.userService.GetUserByID(member.UserID); err == nil {
+			responses[i].Name = memberUser.Name
+			responses[i].Email = memberUser.Email
+		}
+	}
+	return responses
+}
+
+// buildGroupResponse converts a group into a response
+func buildGroupResponse(g *group.Group, members []dto.GroupMemberResponse) dto.GroupResponse {
+	return dto.GroupResponse{
+		ID:        g.ID,
+		Name:      g.Name,
+		CreatedBy: g.CreatedBy,
+		CreatedAt: g.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: g.UpdatedAt.Format(time.RFC3339),
+		Members:   members,
+	}
+}
+
+// groupErrorResponse maps group errors to responses
+func groupErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch err {
+	case group.ErrGroupNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Group not found",
+		})
+	case group.ErrMemberNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Group member not found",
+		})
+	case group.ErrInvalidName:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid group name",
+		})
+	case group.ErrInvalidRole:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role, must be 'owner' or 'member'",
+		})
+	case group.ErrNotGroupOwner:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only group owners can manage the group",
+		})
+	case group.ErrAlreadyMember:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "User is already a member of the group",
+		})
+	case group.ErrLastOwner:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A group must keep at least one owner",
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+}
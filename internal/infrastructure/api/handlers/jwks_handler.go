@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"easy-storage/internal/infrastructure/auth/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with
+type JWKSHandler struct {
+	jwtProvider *jwt.Provider
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(jwtProvider *jwt.Provider) *JWKSHandler {
+	return &JWKSHandler{
+		jwtProvider: jwtProvider,
+	}
+}
+
+// GetKeys returns the verification keys as a JSON Web Key Set, so other services can verify our tokens
+// The keys only change with the configuration, clients may cache them for a few minutes
+func (h *JWKSHandler) GetKeys(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(h.jwtProvider.JWKS())
+}
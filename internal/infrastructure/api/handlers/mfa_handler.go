@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"log"
+
+	"easy-storage/internal/domain/auth"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/infrastructure/api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MFAHandler handles the two-factor authentication endpoints of the current user
+type MFAHandler struct {
+	mfaService  *auth.MFAService
+	userService *user.Service
+}
+
+// NewMFAHandler creates a new two-factor authentication handler
+func NewMFAHandler(mfaService *auth.MFAService, userService *user.Service) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		userService: userService,
+	}
+}
+
+// GetStatus handles retrieving the two-factor authentication of the current user
+func (h *MFAHandler) GetStatus(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	status, err := h.mfaService.Status(userID)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Could not retrieve two-factor status")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.MFAStatusResponse{
+		Enabled:           status.Enabled,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	})
+}
+
+// EnrollTOTP handles starting the enrollment of an authenticator app
+func (h *MFAHandler) EnrollTOTP(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	currentUser, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	enrollment, uri, err := h.mfaService.EnrollTOTP(currentUser)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Could not enroll authenticator")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.TOTPEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: uri,
+	})
+}
+
+// ConfirmTOTP handles enabling two-factor authentication with a first code of the authenticator
+func (h *MFAHandler) ConfirmTOTP(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code is required",
+		})
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(userID, req.Code)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Could not confirm authenticator")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTOTP handles turning two-factor authentication off
+func (h *MFAHandler) DisableTOTP(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code is required",
+		})
+	}
+
+	if err := h.mfaService.DisableTOTP(userID, req.Code); err != nil {
+		return mfaErrorResponse(c, err, "Could not disable two-factor authentication")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes handles replacing the recovery codes of the current user
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code is required",
+		})
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Could not regenerate recovery codes")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// mfaErrorResponse maps two-factor authentication errors to responses
+func mfaErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch err {
+	case auth.ErrInvalidMFACode:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid two-factor code",
+		})
+	case auth.ErrInvalidMFAToken:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Two-factor sign in expired, please sign in again",
+		})
+	case auth.ErrTooManyMFAAttempts:
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Too many failed attempts, try again later",
+		})
+	case auth.ErrMFANotEnabled:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Two-factor authentication is not enabled",
+		})
+	case auth.ErrMFANotEnrolled:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No authenticator to confirm, enroll one first",
+		})
+	case auth.ErrMFAAlreadyEnabled:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Two-factor authentication is already enabled",
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+}
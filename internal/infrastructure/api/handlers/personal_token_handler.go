@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"log"
+
+	"easy-storage/internal/domain/auth"
+	"easy-storage/internal/infrastructure/api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PersonalTokenHandler handles the personal access token endpoints of the current user
+type PersonalTokenHandler struct {
+	personalTokenService *auth.PersonalTokenService
+}
+
+// NewPersonalTokenHandler creates a new personal access token handler
+func NewPersonalTokenHandler(personalTokenService *auth.PersonalTokenService) *PersonalTokenHandler {
+	return &PersonalTokenHandler{
+		personalTokenService: personalTokenService,
+	}
+}
+
+// CreateToken handles creating a personal access token, the token is only shown in this response
+func (h *PersonalTokenHandler) CreateToken(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.CreatePersonalTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	personalToken, token, err := h.personalTokenService.CreateToken(userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		return personalTokenErrorResponse(c, err, "Could not create token")
+	}
+
+	response := buildPersonalTokenResponse(personalToken)
+	response.Token = token
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// ListTokens handles listing the personal access tokens of the current user
+func (h *PersonalTokenHandler) ListTokens(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	personalTokens, err := h.personalTokenService.ListTokens(userID)
+	if err != nil {
+		return personalTokenErrorResponse(c, err, "Could not list tokens")
+	}
+
+	responses := make([]dto.PersonalTokenResponse, len(personalTokens))
+	for i, personalToken := range personalTokens {
+		responses[i] = buildPersonalTokenResponse(personalToken)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"tokens": responses,
+	})
+}
+
+// RevokeToken handles deleting a personal access token of the current user
+func (h *PersonalTokenHandler) RevokeToken(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.personalTokenService.RevokeToken(userID, c.Params("id")); err != nil {
+		return personalTokenErrorResponse(c, err, "Could not revoke token")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// buildPersonalTokenResponse converts a personal access token into a response
+func buildPersonalTokenResponse(t *auth.PersonalAccessToken) dto.PersonalTokenResponse {
+	scopes := make([]string, len(t.Scopes))
+	for i, scope := range t.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return dto.PersonalTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Hint:       t.Hint,
+		Scopes:     scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// personalTokenErrorResponse maps personal access token errors to responses
+func personalTokenErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch err {
+	case auth.ErrPersonalTokenNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Token not found",
+		})
+	case auth.ErrInvalidTokenName:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token name is required",
+		})
+	case auth.ErrInvalidScope:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":  "At least one valid scope is required",
+			"scopes": auth.Scopes,
+		})
+	case auth.ErrInvalidExpiration:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Expiration date must be in the future",
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"easy-storage/internal/domain/auth"
+	"easy-storage/internal/infrastructure/api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SessionHandler handles the session endpoints of the current user
+type SessionHandler struct {
+	authService *auth.Service
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(authService *auth.Service) *SessionHandler {
+	return &SessionHandler{
+		authService: authService,
+	}
+}
+
+// ListSessions handles listing the active sessions of the current user
+func (h *SessionHandler) ListSessions(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	sessions, err := h.authService.ListSessions(userID)
+	if err != nil {
+		return sessionErrorResponse(c, err, "Could not list sessions")
+	}
+
+	// The session of the token the request was made with, when it has one
+	currentID, _ := c.Locals("sessionID").(string)
+
+	responses := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = buildSessionResponse(session, currentID)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"sessions": responses,
+	})
+}
+
+// RevokeSession handles signing out one of the sessions of the current user
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.authService.RevokeSession(userID, c.Params("id")); err != nil {
+		return sessionErrorResponse(c, err, "Could not revoke session")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// buildSessionResponse converts a session into a response
+func buildSessionResponse(session *auth.Session, currentID string) dto.SessionResponse {
+	return dto.SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+		LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		ExpiresAt:  session.ExpiresAt.Format(time.RFC3339),
+		Current:    session.ID == currentID,
+	}
+}
+
+// sessionErrorResponse maps session errors to responses
+func sessionErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch err {
+	case auth.ErrSessionNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Session not found",
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+}
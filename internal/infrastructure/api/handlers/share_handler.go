@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"io"
 	"log"
 	"time"
 
 	"easy-storage/internal/domain/access"
 	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/folder"
 	"easy-storage/internal/domain/share"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/infrastructure/api/dto"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -16,14 +20,16 @@ import (
 type ShareHandler struct {
 	shareService      *share.Service
 	fileService       *file.Service
+	folderService     *folder.Service
 	fileAccessService *access.Service
 }
 
 // NewShareHandler creates a new share handler
-func NewShareHandler(shareService *share.Service, fileService *file.Service, fileAccessService *access.Service) *ShareHandler {
+func NewShareHandler(shareService *share.Service, fileService *file.Service, folderService *folder.Service, fileAccessService *access.Service) *ShareHandler {
 	return &ShareHandler{
 		shareService:      shareService,
 		fileService:       fileService,
+		folderService:     folderService,
 		fileAccessService: fileAccessService,
 	}
 }
@@ -35,13 +41,20 @@ func (h *ShareHandler) CreateShare(c *fiber.Ctx) error {
 
 	// Parse request body
 	var req struct {
-		ResourceID   string `json:"resource_id" validate:"required"`
-		ResourceType string `json:"resource_type" validate:"required,oneof=file folder"`
-		ShareType    string `json:"share_type" validate:"required,oneof=LINK USER"`
-		Permission   string `json:"permission" validate:"required,oneof=READ WRITE"`
-		RecipientID  string `json:"recipient_id,omitempty"`
-		Password     string `json:"password,omitempty"`
-		ExpiresAt    string `json:"expires_at,omitempty"`
+		ResourceID       string `json:"resource_id" validate:"required"`
+		ResourceType     string `json:"resource_type" validate:"required,oneof=file folder"`
+		ShareType        string `json:"share_type" validate:"required,oneof=LINK USER GROUP"`
+		Permission       string `json:"permission" validate:"required,oneof=viewer commenter editor co-owner READ WRITE"`
+		RecipientID      string `json:"recipient_id,omitempty"`
+		RecipientEmail   string `json:"recipient_email,omitempty"`
+		GroupID          string `json:"group_id,omitempty"`
+		Password         string `json:"password,omitempty"`
+		ExpiresAt        string `json:"expires_at,omitempty"`
+		UploadOnly       bool   `json:"upload_only,omitempty"`
+		MaxFiles         int    `json:"max_files,omitempty"`
+		MaxFileSize      int64  `json:"max_file_size,omitempty"`
+		MaxDownloads     int    `json:"max_downloads,omitempty"`
+		BurnAfterReading bool   `json:"burn_after_reading,omitempty"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -50,11 +63,44 @@ func (h *ShareHandler) CreateShare(c *fiber.Ctx) error {
 		})
 	}
 
-	// Convert string IDs to UUID
-	ownerID, err := uuid.Parse(userID)
+	// READ and WRITE are still accepted for the viewer and editor roles
+	permission, err := share.ParsePermission(req.Permission)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID",
+			"error": "Invalid permission, must be viewer, commenter, editor or co-owner",
+		})
+	}
+
+	// Link recipients are anonymous, they cannot reshare or manage shares
+	if req.ShareType == string(share.LinkShare) && permission.Has(share.CapReshare) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Link shares cannot give the co-owner role",
+		})
+	}
+
+	// Upload options only apply to folders shared with a role that can upload
+	hasUploadOptions := req.UploadOnly || req.MaxFiles != 0 || req.MaxFileSize != 0
+	if hasUploadOptions && (!permission.Has(share.CapUpload) || req.ResourceType != "folder") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Upload options require a folder share with the editor or co-owner role",
+		})
+	}
+	if req.MaxFiles < 0 || req.MaxFileSize < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "max_files and max_file_size cannot be negative",
+		})
+	}
+
+	// Download limits only apply to link shares
+	hasDownloadLimit := req.MaxDownloads != 0 || req.BurnAfterReading
+	if hasDownloadLimit && req.ShareType != string(share.LinkShare) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Download limits require a link share",
+		})
+	}
+	if req.MaxDownloads < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "max_downloads cannot be negative",
 		})
 	}
 
@@ -65,6 +111,20 @@ func (h *ShareHandler) CreateShare(c *fiber.Ctx) error {
 		})
 	}
 
+	// Only owners and users whose role allows resharing can share, shares always belong to the resource owner
+	resourceName, resourceOwnerID, err := h.shareableResource(c, req.ResourceType, req.ResourceID, userID, permission)
+	if err != nil {
+		return accessErrorResponse(c, err, "You can only share resources you can reshare, with a role up to your own")
+	}
+
+	// Convert string IDs to UUID
+	ownerID, err := uuid.Parse(resourceOwnerID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
 	// Create share based on type
 	var newShare *share.Share
 	if req.ShareType == "LINK" {
@@ -74,18 +134,68 @@ func (h *ShareHandler) CreateShare(c *fiber.Ctx) error {
 			ownerID,
 			resourceID,
 			req.ResourceType,
-			share.SharePermission(req.Permission),
+			permission,
 		)
+	} else if req.ShareType == string(share.GroupShare) {
+		// For group shares, the group is required
+		if req.GroupID == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Group ID is required for group shares",
+			})
+		}
+
+		groupID, parseErr := uuid.Parse(req.GroupID)
+		if parseErr != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid group ID",
+			})
+		}
+
+		// Create group share
+		newShare, err = h.shareService.CreateGroupShare(
+			c.Context(),
+			ownerID,
+			resourceID,
+			req.ResourceType,
+			groupID,
+			permission,
+		)
+		if err == share.ErrInvalidGroup {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Group not found",
+			})
+		}
+	} else if req.RecipientID == "" && req.RecipientEmail != "" {
+		// Invite the recipient by email, the invitation waits for unregistered emails to sign up
+		newShare, err = h.shareService.InviteByEmail(
+			c.Context(),
+			ownerID,
+			resourceID,
+			req.ResourceType,
+			resourceName,
+			req.RecipientEmail,
+			permission,
+		)
+		switch err {
+		case share.ErrInvalidEmail:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid recipient email",
+			})
+		case share.ErrInvalidRecipient:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "You cannot share with yourself",
+			})
+		}
 	} else {
-		// For user shares, recipient is required
+		// For user shares, a recipient ID or email is required
 		if req.RecipientID == "" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Recipient ID is required for user shares",
+				"error": "Recipient ID or email is required for user shares",
 			})
 		}
 
-		recipientID, err := uuid.Parse(req.RecipientID)
-		if err != nil {
+		recipientID, parseErr := uuid.Parse(req.RecipientID)
+		if parseErr != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Invalid recipient ID",
 			})
@@ -98,7 +208,7 @@ func (h *ShareHandler) CreateShare(c *fiber.Ctx) error {
 			resourceID,
 			req.ResourceType,
 			recipientID,
-			share.SharePermission(req.Permission),
+			permission,
 		)
 	}
 
@@ -133,6 +243,22 @@ func (h *ShareHandler) CreateShare(c *fiber.Ctx) error {
 		}
 	}
 
+	if hasUploadOptions {
+		if err := h.shareService.SetShareUploadOptions(c.Context(), newShare.ID, req.UploadOnly, req.MaxFiles, req.MaxFileSize); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not set upload options",
+			})
+		}
+	}
+
+	if hasDownloadLimit {
+		if err := h.shareService.SetShareDownloadLimit(c.Context(), newShare.ID, req.MaxDownloads, req.BurnAfterReading); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not set download limit",
+			})
+		}
+	}
+
 	// Get the updated share
 	newShare, err = h.shareService.GetShareByID(c.Context(), newShare.ID)
 	if err != nil {
@@ -188,10 +314,20 @@ func (h *ShareHandler) GetShare(c *fiber.Ctx) error {
 	}
 
 	// Check if user is authorized to view this share
-	isOwner := existingShare.OwnerID == userUUID
-	isRecipient := existingShare.RecipientID != nil && *existingShare.RecipientID == userUUID
+	canManage, err := h.canManageShare(c, existingShare, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not retrieve share",
+		})
+	}
+	isRecipient, err := h.shareService.IsRecipient(existingShare, userUUID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not retrieve share",
+		})
+	}
 
-	if !isOwner && !isRecipient {
+	if !canManage && !isRecipient {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "You don't have permission to access this share",
 		})
@@ -268,6 +404,50 @@ func (h *ShareHandler) ListSharesWithMe(c *fiber.Ctx) error {
 	})
 }
 
+// ClaimInvitation handles accepting an email invitation with the token sent to the invited email
+func (h *ShareHandler) ClaimInvitation(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Parse user ID
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req struct {
+		Token string `json:"token"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invitation token is required",
+		})
+	}
+
+	claimed, err := h.shareService.ClaimInvitation(c.Context(), req.Token, userUUID)
+	if err != nil {
+		switch err {
+		case share.ErrInvitationNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Invitation not found or already claimed",
+			})
+		case share.ErrInvalidRecipient:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "You cannot claim your own invitation",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not claim invitation",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(buildShareResponse(claimed))
+}
+
 // ListSharesByResource handles listing all shares for a specific resource
 func (h *ShareHandler) ListSharesByResource(c *fiber.Ctx) error {
 	// Get user ID from context (set by auth middleware)
@@ -290,19 +470,17 @@ func (h *ShareHandler) ListSharesByResource(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse IDs
-	userUUID, err := uuid.Parse(userID)
+	resourceUUID, err := uuid.Parse(resourceID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID",
+			"error": "Invalid resource ID",
 		})
 	}
 
-	resourceUUID, err := uuid.Parse(resourceID)
+	// Only owners and users whose role allows managing shares can list them
+	ownerID, err := h.manageableResourceOwner(c, resourceType, resourceID, userID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid resource ID",
-		})
+		return accessErrorResponse(c, err, "You don't have permission to manage the shares of this resource")
 	}
 
 	// List shares for the resource
@@ -313,10 +491,10 @@ func (h *ShareHandler) ListSharesByResource(c *fiber.Ctx) error {
 		})
 	}
 
-	// Filter to only include shares owned by the requesting user
+	// Filter to only include shares of the resource owner
 	ownedShares := make([]*share.Share, 0)
 	for _, s := range shares {
-		if s.OwnerID == userUUID {
+		if s.OwnerID.String() == ownerID {
 			ownedShares = append(ownedShares, s)
 		}
 	}
@@ -346,14 +524,7 @@ func (h *ShareHandler) RevokeShare(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse IDs
-	userUUID, err := uuid.Parse(userID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
-	}
-
+	// Parse share ID
 	shareUUID, err := uuid.Parse(shareID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -374,8 +545,14 @@ func (h *ShareHandler) RevokeShare(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if user is the owner
-	if existingShare.OwnerID != userUUID {
+	// Check if user is the owner or can manage the shares of the resource
+	canManage, err := h.canManageShare(c, existingShare, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not retrieve share",
+		})
+	}
+	if !canManage {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "You don't have permission to revoke this share",
 		})
@@ -406,8 +583,9 @@ func (h *ShareHandler) UpdateShare(c *fiber.Ctx) error {
 
 	// Parse request body
 	var req struct {
-		Password  string `json:"password"`
-		ExpiresAt string `json:"expires_at"`
+		Password   string `json:"password"`
+		ExpiresAt  string `json:"expires_at"`
+		Permission string `json:"permission"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -416,14 +594,7 @@ func (h *ShareHandler) UpdateShare(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse IDs
-	userUUID, err := uuid.Parse(userID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
-	}
-
+	// Parse share ID
 	shareUUID, err := uuid.Parse(shareID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -444,13 +615,44 @@ func (h *ShareHandler) UpdateShare(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if user is the owner
-	if existingShare.OwnerID != userUUID {
+	// Check if user is the owner or can manage the shares of the resource
+	canManage, err := h.canManageShare(c, existingShare, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not retrieve share",
+		})
+	}
+	if !canManage {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "You don't have permission to update this share",
 		})
 	}
 
+	// Update role if provided, users can only give roles up to their own
+	if req.Permission != "" {
+		permission, err := share.ParsePermission(req.Permission)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid permission, must be viewer, commenter, editor or co-owner",
+			})
+		}
+		if existingShare.Type == share.LinkShare && permission.Has(share.CapReshare) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Link shares cannot give the co-owner role",
+			})
+		}
+
+		if _, _, err := h.shareableResource(c, existingShare.ResourceType, existingShare.ResourceID.String(), userID, permission); err != nil {
+			return accessErrorResponse(c, err, "You can only give roles up to your own")
+		}
+
+		if err := h.shareService.SetSharePermission(c.Context(), shareUUID, permission); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not update permission",
+			})
+		}
+	}
+
 	// Update password if provided
 	if req.Password != "" {
 		if err := h.shareService.SetSharePassword(c.Context(), shareUUID, req.Password); err != nil {
@@ -490,73 +692,137 @@ func (h *ShareHandler) UpdateShare(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
-// AccessShare handles accessing a shared resource via token
-func (h *ShareHandler) AccessShare(c *fiber.Ctx) error {
-	// Get token from parameter
-	token := c.Params("token")
-	if token == "" {
+// ListShareEvents handles listing the access log of a share
+func (h *ShareHandler) ListShareEvents(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Parse IDs
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Token is required",
+			"error": "Invalid user ID",
 		})
 	}
 
-	// Parse password from query if provided
-	password := c.Query("password")
-
-	// Get share by token
-	existingShare, err := h.shareService.GetShareByToken(c.Context(), token)
+	shareUUID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		if err == share.ErrShareNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Share not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Could not retrieve share",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid share ID",
 		})
 	}
 
-	// Check if share is accessible
-	if !existingShare.IsAccessible() {
-		if existingShare.IsRevoked {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "This share has been revoked",
-			})
+	// Get pagination parameters from query
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("pageSize", 50)
+
+	// Validate pagination parameters
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50 // Default page size with a reasonable limit
+	}
+
+	events, totalCount, err := h.shareService.ListAccessEvents(c.Context(), shareUUID, userUUID, page, pageSize)
+	if err != nil {
+		return shareOwnerErrorResponse(c, err, "Could not list share access events")
+	}
+
+	// Calculate total pages
+	totalPages := int(totalCount) / pageSize
+	if int(totalCount)%pageSize > 0 {
+		totalPages++
+	}
+
+	eventResponses := make([]fiber.Map, len(events))
+	for i, event := range events {
+		eventResponse := fiber.Map{
+			"id":          event.ID,
+			"action":      event.Action,
+			"outcome":     event.Outcome,
+			"ip":          event.IP,
+			"user_agent":  event.UserAgent,
+			"occurred_at": event.OccurredAt.Format(time.RFC3339),
 		}
-		if existingShare.IsExpired() {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "This share has expired",
-			})
+		if event.UserID != nil {
+			eventResponse["user_id"] = event.UserID
 		}
+		eventResponses[i] = eventResponse
 	}
 
-	// Check password if required
-	if existingShare.Password != nil {
-		// If no password provided but required
-		if password == "" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":             "Password required",
-				"requires_password": true,
-			})
-		}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"events": eventResponses,
+		"pagination": dto.PaginationInfo{
+			CurrentPage: page,
+			PageSize:    pageSize,
+			TotalItems:  totalCount,
+			TotalPages:  totalPages,
+			HasNextPage: page < totalPages,
+			HasPrevPage: page > 1,
+		},
+	})
+}
 
-		// If password doesn't match
-		if *existingShare.Password != password {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Invalid password",
-			})
-		}
+// GetShareStats handles aggregating the access log of a share
+func (h *ShareHandler) GetShareStats(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Parse IDs
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
 	}
 
-	// Record access
-	if err := h.shareService.RecordShareAccess(c.Context(), existingShare.ID); err != nil {
-		// Log this error but don't fail the request
-		// logger.Warn("Failed to record share access", "error", err)
+	shareUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid share ID",
+		})
 	}
 
-	// Build response
-	response := buildShareResponse(existingShare)
-	return c.Status(fiber.StatusOK).JSON(response)
+	stats, err := h.shareService.GetAccessStats(c.Context(), shareUUID, userUUID)
+	if err != nil {
+		return shareOwnerErrorResponse(c, err, "Could not compute share access stats")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
+
+// shareOwnerErrorResponse maps errors of operations reserved to the owner of a share to responses
+func shareOwnerErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch err {
+	case share.ErrShareNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Share not found",
+		})
+	case share.ErrUnauthorizedAccess:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to view this share",
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+}
+
+// AccessShare handles accessing a shared resource via token
+// The password of a protected share is read from the X-Share-Password header
+func (h *ShareHandler) AccessShare(c *fiber.Ctx) error {
+	// Get token from parameter
+	token := c.Params("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	return h.respondWithShare(c, token, sharePassword(c))
 }
 
 // ValidateShareAccess handles validating access to a shared resource with password
@@ -580,51 +846,138 @@ func (h *ShareHandler) ValidateShareAccess(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get share by token
-	existingShare, err := h.shareService.GetShareByToken(c.Context(), token)
+	return h.respondWithShare(c, token, req.Password)
+}
+
+// respondWithShare checks access to a share and responds with its metadata
+func (h *ShareHandler) respondWithShare(c *fiber.Ctx, token, password string) error {
+	existingShare, err := h.shareService.GetResourceByToken(c.Context(), token, password, shareVisitor(c), share.ActionView)
 	if err != nil {
-		if err == share.ErrShareNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Share not found",
+		// If no password provided but required
+		if err == share.ErrInvalidPassword && password == "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":             "Password required",
+				"requires_password": true,
 			})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Could not retrieve share",
-		})
+		return sharedResourceErrorResponse(c, err)
 	}
 
-	// Check if share is accessible
-	if !existingShare.IsAccessible() {
-		if existingShare.IsRevoked {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "This share has been revoked",
-			})
-		}
-		if existingShare.IsExpired() {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "This share has expired",
-			})
-		}
+	// Build response
+	response := buildShareResponse(existingShare)
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// shareVisitor identifies the client accessing a share for its access log
+// The user is only known when an optional bearer token was sent
+func shareVisitor(c *fiber.Ctx) share.Visitor {
+	visitor := share.Visitor{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
 	}
 
-	// Check password
-	if existingShare.Password != nil {
-		if req.Password == "" || *existingShare.Password != req.Password {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Invalid password",
-			})
+	if userID, ok := c.Locals("userID").(string); ok {
+		if userUUID, err := uuid.Parse(userID); err == nil {
+			visitor.UserID = &userUUID
 		}
 	}
 
-	// Record access
-	if err := h.shareService.RecordShareAccess(c.Context(), existingShare.ID); err != nil {
-		// Log this error but don't fail the request
-		// logger.Warn("Failed to record share access", "error", err)
+	return visitor
+}
+
+// sharePassword reads the password of a protected share from the X-Share-Password header,
+// or from the form of POST requests. Passwords are never read from the query string,
+// which ends up in access logs and browser history
+func sharePassword(c *fiber.Ctx) string {
+	if password := c.Get("X-Share-Password"); password != "" {
+		return password
 	}
+	if c.Method() == fiber.MethodPost {
+		return c.FormValue("password")
+	}
+	return ""
+}
 
-	// Build response
-	response := buildShareResponse(existingShare)
-	return c.Status(fiber.StatusOK).JSON(response)
+// shareableResource gets the name and owner of a file or folder the user can share with a role
+// Users need a role that allows resharing and can only give roles up to their own
+func (h *ShareHandler) shareableResource(c *fiber.Ctx, resourceType, resourceID, userID string, permission share.SharePermission) (string, string, error) {
+	name, ownerID, role, err := h.resourceRole(c, resourceType, resourceID, userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if !role.Has(share.CapReshare) || !role.Grants(permission) {
+		return "", "", access.ErrPermissionDenied
+	}
+
+	return name, ownerID, nil
+}
+
+// manageableResourceOwner gets the owner of a file or folder whose shares the user can manage
+func (h *ShareHandler) manageableResourceOwner(c *fiber.Ctx, resourceType, resourceID, userID string) (string, error) {
+	_, ownerID, role, err := h.resourceRole(c, resourceType, resourceID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if !role.Has(share.CapManage) {
+		return "", access.ErrPermissionDenied
+	}
+
+	return ownerID, nil
+}
+
+// canManageShare checks if a user can update or revoke a share
+// Besides the owner of the share, users whose role on the shared resource allows managing its shares can
+func (h *ShareHandler) canManageShare(c *fiber.Ctx, existingShare *share.Share, userID string) (bool, error) {
+	if existingShare.OwnerID.String() == userID {
+		return true, nil
+	}
+
+	ownerID, err := h.manageableResourceOwner(c, existingShare.ResourceType, existingShare.ResourceID.String(), userID)
+	switch err {
+	case nil:
+		return ownerID == existingShare.OwnerID.String(), nil
+	case access.ErrPermissionDenied, file.ErrFileNotFound, folder.ErrFolderNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// resourceRole gets the name and owner of a file or folder with the role of the user on it
+// Resources the user has no role on are reported as not found
+func (h *ShareHandler) resourceRole(c *fiber.Ctx, resourceType, resourceID, userID string) (string, string, share.SharePermission, error) {
+	switch resourceType {
+	case "file":
+		sharedFile, err := h.fileService.GetFile(resourceID)
+		if err != nil {
+			return "", "", "", err
+		}
+		role, err := h.fileAccessService.FileRole(c.Context(), sharedFile, userID)
+		if err != nil {
+			return "", "", "", err
+		}
+		if role == "" {
+			return "", "", "", file.ErrFileNotFound
+		}
+		return sharedFile.Name, sharedFile.UserID, role, nil
+	case "folder":
+		sharedFolder, err := h.folderService.GetFolder(resourceID)
+		if err != nil {
+			return "", "", "", err
+		}
+		role, err := h.fileAccessService.FolderRole(c.Context(), sharedFolder, userID)
+		if err != nil {
+			return "", "", "", err
+		}
+		if role == "" {
+			return "", "", "", folder.ErrFolderNotFound
+		}
+		return sharedFolder.Name, sharedFolder.UserID, role, nil
+	default:
+		return "", "", "", file.ErrFileNotFound
+	}
 }
 
 // Helper function to build share response
@@ -635,6 +988,7 @@ func buildShareResponse(s *share.Share) map[string]interface{} {
 		"resource_type": s.ResourceType,
 		"share_type":    s.Type,
 		"permission":    s.Permission,
+		"capabilities":  s.Permission.Capabilities(),
 		"has_password":  s.Password != nil,
 		"is_revoked":    s.IsRevoked,
 		"access_count":  s.AccessCount,
@@ -655,9 +1009,33 @@ func buildShareResponse(s *share.Share) map[string]interface{} {
 		response["recipient_id"] = s.RecipientID
 	}
 
+	if s.GroupID != nil {
+		response["group_id"] = s.GroupID
+	}
+
+	// Invitations sent by email, pending until the invitation is claimed
+	if s.RecipientEmail != "" {
+		response["recipient_email"] = s.RecipientEmail
+		response["pending"] = s.IsPendingInvitation()
+	}
+
+	// Upload options of folders shared with a role that can upload
+	if s.AllowsUpload() {
+		response["upload_only"] = s.UploadOnly
+		response["max_files"] = s.MaxFiles
+		response["max_file_size"] = s.MaxFileSize
+		response["upload_count"] = s.UploadCount
+	}
+
 	// Only include token in response for link shares
 	if s.Type == share.LinkShare {
 		response["token"] = s.Token
+		response["download_count"] = s.DownloadCount
+		if s.MaxDownloads > 0 {
+			response["max_downloads"] = s.MaxDownloads
+			response["remaining_downloads"] = s.RemainingDownloads()
+			response["burn_after_reading"] = s.BurnAfterReading
+		}
 		// You could add a full URL here if you have a base URL configured
 		// response["url"] = fmt.Sprintf("%s/share/%s", baseURL, s.Token)
 	}
@@ -675,43 +1053,167 @@ func (h *ShareHandler) DownloadSharedFile(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse password from query if provided
-	password := c.Query("password", "")
+	// Get password of protected shares
+	password := sharePassword(c)
 
 	// Get file using share token
-	downloadedFile, err := h.fileAccessService.GetFileByShareToken(c.Context(), token, password)
+	downloadedFile, sharedVia, err := h.fileAccessService.GetFileByShareToken(c.Context(), token, password, shareVisitor(c))
 	if err != nil {
-		switch err {
-		case share.ErrShareNotFound:
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Share not found",
-			})
-		case share.ErrShareRevoked:
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "This share has been revoked",
-			})
-		case share.ErrShareExpired:
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "This share has expired",
+		if err == access.ErrInvalidResourceType {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Only files can be downloaded",
 			})
-		case share.ErrInvalidPassword:
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":             "Invalid password",
-				"requires_password": true,
+		}
+		return sharedResourceErrorResponse(c, err)
+	}
+
+	return h.sendSharedFile(c, sharedVia, downloadedFile)
+}
+
+// DownloadSharedFolder streams a folder shared by link as a ZIP archive
+func (h *ShareHandler) DownloadSharedFolder(c *fiber.Ctx) error {
+	// Get token from parameter
+	token := c.Params("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	// Get password of protected shares
+	password := sharePassword(c)
+
+	// Get folder using share token
+	sharedFolder, err := h.fileAccessService.DownloadFolderByShareToken(c.Context(), token, password, shareVisitor(c))
+	if err != nil {
+		if err == access.ErrInvalidResourceType {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Only folders can be downloaded as an archive",
 			})
-		case access.ErrInvalidResourceType:
+		}
+		return sharedResourceErrorResponse(c, err)
+	}
+
+	entries, err := h.folderService.ListArchiveEntries(sharedFolder)
+	if err != nil {
+		log.Printf("Error listing shared folder contents for archive: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not prepare archive",
+		})
+	}
+
+	return sendArchive(c, h.fileService, sharedFolder.Name, entries)
+}
+
+// ListSharedFolderContents lists a folder inside a folder shared by link
+func (h *ShareHandler) ListSharedFolderContents(c *fiber.Ctx) error {
+	// Get token from parameter
+	token := c.Params("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	// Get password of protected shares
+	password := sharePassword(c)
+
+	// Subfolder to list, the shared folder itself by default
+	folderID := c.Query("folder_id", "")
+
+	listedFolder, folders, files, err := h.fileAccessService.GetSharedFolderContents(c.Context(), token, password, shareVisitor(c), folderID)
+	if err != nil {
+		if err == access.ErrInvalidResourceType {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Only files can be downloaded",
+				"error": "Only folder shares can be browsed",
 			})
-		case file.ErrFileNotFound:
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "File not found",
+		}
+		return sharedResourceErrorResponse(c, err)
+	}
+
+	// Build response for folders
+	folderResponses := make([]map[string]interface{}, len(folders))
+	for i, folder := range folders {
+		folderResponses[i] = map[string]interface{}{
+			"id":         folder.ID,
+			"name":       folder.Name,
+			"parent_id":  folder.ParentID,
+			"type":       "folder",
+			"created_at": folder.CreatedAt.Format(time.RFC3339),
+			"updated_at": folder.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+
+	// Build response for files
+	fileResponses := make([]map[string]interface{}, len(files))
+	for i, file := range files {
+		fileResponses[i] = map[string]interface{}{
+			"id":           file.ID,
+			"name":         file.Name,
+			"size":         file.Size,
+			"content_type": file.ContentType,
+			"type":         "file",
+			"created_at":   file.CreatedAt.Format(time.RFC3339),
+			"updated_at":   file.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+
+	// Combine both responses
+	contents := append(folderResponses, fileResponses...)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"folder_id":   listedFolder.ID,
+		"folder_name": listedFolder.Name,
+		"contents":    contents,
+		"total":       len(contents),
+	})
+}
+
+// DownloadSharedFolderFile handles downloading a file inside a folder shared by link
+func (h *ShareHandler) DownloadSharedFolderFile(c *fiber.Ctx) error {
+	// Get token from parameter
+	token := c.Params("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	// Get password of protected shares
+	password := sharePassword(c)
+
+	downloadedFile, sharedVia, err := h.fileAccessService.GetSharedFolderFile(c.Context(), token, password, shareVisitor(c), c.Params("file_id"))
+	if err != nil {
+		if err == access.ErrInvalidResourceType {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Only folder shares contain files, use the download endpoint",
 			})
-		default:
+		}
+		return sharedResourceErrorResponse(c, err)
+	}
+
+	return h.sendSharedFile(c, sharedVia, downloadedFile)
+}
+
+// sendSharedFile answers a download through a link share
+// Shares with a download limit stream the content, a signed URL could be reused until it expires
+// and let a single counted download be repeated
+func (h *ShareHandler) sendSharedFile(c *fiber.Ctx, sharedVia *share.Share, downloadedFile *file.File) error {
+	if sharedVia.IsDownloadLimited() {
+		content, err := h.fileService.GetFileContent(downloadedFile)
+		if err != nil {
+			log.Printf("Error reading shared file %s: %v", downloadedFile.ID, err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not retrieve file",
+				"error": "Could not read file",
 			})
 		}
+
+		c.Attachment(downloadedFile.Name)
+		c.Set(fiber.HeaderContentType, downloadedFile.ContentType)
+		c.Set(fiber.HeaderCacheControl, "no-store")
+
+		// Fiber closes the stream once the response has been written
+		return c.SendStream(content, int(downloadedFile.Size))
 	}
 
 	// Get signed URL (valid for 1 hour = 3600 seconds)
@@ -730,3 +1232,162 @@ func (h *ShareHandler) DownloadSharedFile(c *fiber.Ctx) error {
 		"size":         downloadedFile.Size,
 	})
 }
+
+// UploadToShare handles uploading a file into a folder shared with the user with a role that can upload
+func (h *ShareHandler) UploadToShare(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	shareUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid share ID",
+		})
+	}
+
+	return h.handleSharedUpload(c, func(filename string, size int64, contentType string, content io.Reader) (*file.File, error) {
+		return h.fileAccessService.UploadToUserShare(c.Context(), shareUUID, userID, c.Query("folder_id", ""), filename, size, contentType, content)
+	})
+}
+
+// UploadToSharedFolder handles anonymous uploads into a folder shared by a link with a role that can upload
+func (h *ShareHandler) UploadToSharedFolder(c *fiber.Ctx) error {
+	// Get token from parameter
+	token := c.Params("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	// Get password of protected shares
+	password := sharePassword(c)
+
+	return h.handleSharedUpload(c, func(filename string, size int64, contentType string, content io.Reader) (*file.File, error) {
+		return h.fileAccessService.UploadToLinkShare(c.Context(), token, password, shareVisitor(c), c.Query("folder_id", ""), filename, size, contentType, content)
+	})
+}
+
+// handleSharedUpload reads the uploaded file from the form and stores it through a share
+func (h *ShareHandler) handleSharedUpload(c *fiber.Ctx, upload func(filename string, size int64, contentType string, content io.Reader) (*file.File, error)) error {
+	// Get file from form
+	formFile, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No file provided",
+		})
+	}
+
+	// Check file size, same limit as regular uploads
+	if formFile.Size > 100*1024*1024 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "File too large, maximum size is 100MB",
+		})
+	}
+
+	// Open uploaded file
+	src, err := formFile.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not open uploaded file",
+		})
+	}
+	defer src.Close()
+
+	// Determine content type
+	contentType := formFile.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	uploadedFile, err := upload(formFile.Filename, formFile.Size, contentType, src)
+	if err != nil {
+		switch err {
+		case access.ErrInvalidResourceType, share.ErrReadOnlyShare:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "This share does not allow uploads",
+			})
+		case share.ErrUploadLimitReached:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "This share has reached its upload limit",
+			})
+		case share.ErrUploadTooLarge:
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": "File exceeds the maximum upload size of this share",
+			})
+		case share.ErrUnauthorizedAccess:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "You don't have permission to upload through this share",
+			})
+		case file.ErrInvalidName:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid file name",
+			})
+		case user.ErrStorageQuotaExceeded:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "The owner's storage quota is exceeded",
+			})
+		}
+		return sharedResourceErrorResponse(c, err)
+	}
+
+	// The folder is left out, uploaders of upload-only shares cannot browse it
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"id":           uploadedFile.ID,
+		"name":         uploadedFile.Name,
+		"size":         uploadedFile.Size,
+		"content_type": uploadedFile.ContentType,
+		"created_at":   uploadedFile.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+// sharedResourceErrorResponse maps errors of resources accessed through a share token to responses
+func sharedResourceErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case share.ErrShareNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Share not found",
+		})
+	case share.ErrShareRevoked:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "This share has been revoked",
+		})
+	case share.ErrShareExpired:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "This share has expired",
+		})
+	case share.ErrDownloadLimitReached:
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "This share has reached its download limit",
+		})
+	case share.ErrInvalidPassword:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":             "Invalid password",
+			"requires_password": true,
+		})
+	case share.ErrTooManyAttempts:
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Too many failed password attempts, try again later",
+		})
+	case file.ErrFileNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "File not found",
+		})
+	case access.ErrResourceNotInShare:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Not found in this share",
+		})
+	case folder.ErrFolderNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Folder not found",
+		})
+	case share.ErrUploadOnly:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "This share only allows uploads",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not retrieve shared resource",
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"easy-storage/internal/infrastructure/storage/local"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocalStorageHandler serves signed URLs generated by the local storage provider
+type LocalStorageHandler struct {
+	provider *local.LocalProvider
+}
+
+// NewLocalStorageHandler creates a new local storage handler
+func NewLocalStorageHandler(provider *local.LocalProvider) *LocalStorageHandler {
+	return &LocalStorageHandler{
+		provider: provider,
+	}
+}
+
+// Download streams a stored object after verifying its URL signature
+func (h *LocalStorageHandler) Download(c *fiber.Ctx) error {
+	storagePath := c.Params("*")
+
+	// Parse expiry and signature from the query string
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid or missing signature",
+		})
+	}
+
+	// Verify signature and expiry
+	if err := h.provider.VerifySignature(fiber.MethodGet, storagePath, expires, c.Query("signature")); err != nil {
+		if err == local.ErrURLExpired {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Download link has expired",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid or missing signature",
+		})
+	}
+
+	// Open the stored object
+	content, err := h.provider.Download(storagePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "File not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not read file",
+		})
+	}
+
+	// Determine content type from the stored extension
+	contentType := mime.TypeByExtension(filepath.Ext(storagePath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+
+	// Fiber closes the stream once the response has been written
+	return c.SendStream(content)
+}
+
+// Upload stores the request body after verifying its URL signature
+// The signature covers the Content-Type and Content-Length headers, like S3 presigned uploads
+func (h *LocalStorageHandler) Upload(c *fiber.Ctx) error {
+	storagePath := c.Params("*")
+
+	// Parse expiry from the query string
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid or missing signature",
+		})
+	}
+
+	// Chunked requests have no length and cannot match a signature
+	size := int64(c.Request().Header.ContentLength())
+	contentType := c.Get(fiber.HeaderContentType)
+
+	// Verify signature and expiry
+	if err := h.provider.VerifyUploadSignature(storagePath, contentType, size, expires, c.Query("signature")); err != nil {
+		if err == local.ErrURLExpired {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Upload link has expired",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid or missing signature",
+		})
+	}
+
+	if err := h.provider.Put(storagePath, requestBody(c), size); err != nil {
+		if err == local.ErrSizeMismatch {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Request body is shorter than Content-Length",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not store file",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"easy-storage/internal/domain/transfer"
+	"easy-storage/internal/domain/user"
+	"easy-storage/internal/infrastructure/api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TransferHandler handles ownership transfer endpoints
+type TransferHandler struct {
+	transferService *transfer.Service
+	userService     *user.Service
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(transferService *transfer.Service, userService *user.Service) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+		userService:     userService,
+	}
+}
+
+// CreateTransfer handles asking another user to take over a file or folder
+func (h *TransferHandler) CreateTransfer(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	var req dto.CreateTransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	recipientID := req.RecipientID
+	if recipientID == "" {
+		if req.RecipientEmail == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Recipient ID or email is required",
+			})
+		}
+
+		recipient, err := h.userService.GetUserByEmail(req.RecipientEmail)
+		if err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				return transferErrorResponse(c, transfer.ErrInvalidRecipient, "")
+			}
+			return transferErrorResponse(c, err, "Could not create transfer")
+		}
+		recipientID = recipient.ID
+	}
+
+	newTransfer, err := h.transferService.RequestTransfer(req.ResourceType, req.ResourceID, userID, recipientID)
+	if err != nil {
+		return transferErrorResponse(c, err, "Could not create transfer")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(buildTransferResponse(newTransfer))
+}
+
+// ListTransfers handles listing the transfers sent to and by the current user
+func (h *TransferHandler) ListTransfers(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	incoming, err := h.transferService.ListIncoming(userID)
+	if err != nil {
+		return transferErrorResponse(c, err, "Could not list transfers")
+	}
+
+	outgoing, err := h.transferService.ListOutgoing(userID)
+	if err != nil {
+		return transferErrorResponse(c, err, "Could not list transfers")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"incoming": buildTransferResponses(incoming),
+		"outgoing": buildTransferResponses(outgoing),
+	})
+}
+
+// GetTransfer handles retrieving a transfer sent to or by the current user
+func (h *TransferHandler) GetTransfer(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	existingTransfer, err := h.transferService.GetTransfer(c.Params("id"), userID)
+	if err != nil {
+		return transferErrorResponse(c, err, "Could not retrieve transfer")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(buildTransferResponse(existingTransfer))
+}
+
+// AcceptTransfer handles taking over the resource of a transfer
+func (h *TransferHandler) AcceptTransfer(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	accepted, err := h.transferService.AcceptTransfer(c.Params("id"), userID)
+	if err != nil {
+		return transferErrorResponse(c, err, "Could not accept transfer")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(buildTransferResponse(accepted))
+}
+
+// DeclineTransfer handles refusing a transfer
+func (h *TransferHandler) DeclineTransfer(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	declined, err := h.transferService.DeclineTransfer(c.Params("id"), userID)
+	if err != nil {
+		return transferErrorResponse(c, err, "Could not decline transfer")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(buildTransferResponse(declined))
+}
+
+// CancelTransfer handles withdrawing a transfer sent by the current user
+func (h *TransferHandler) CancelTransfer(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	cancelled, err := h.transferService.CancelTransfer(c.Params("id"), userID)
+	if err != nil {
+		return transferErrorResponse(c, err, "Could not cancel transfer")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(buildTransferResponse(cancelled))
+}
+
+// buildTransferResponses converts transfers into responses
+func buildTransferResponses(transfers []*transfer.Transfer) []dto.TransferResponse {
+	responses := make([]dto.TransferResponse, len(transfers))
+	for i, t := range transfers {
+		responses[i] = buildTransferResponse(t)
+	}
+	return responses
+}
+
+// buildTransferResponse converts a transfer into a response
+func buildTransferResponse(t *transfer.Transfer) dto.TransferResponse {
+	response := dto.TransferResponse{
+		ID:           t.ID,
+		ResourceID:   t.ResourceID,
+		ResourceType: t.ResourceType,
+		FromUserID:   t.FromUserID,
+		ToUserID:     t.ToUserID,
+		Status:       string(t.Status),
+		CreatedAt:    t.CreatedAt.Format(time.RFC3339),
+	}
+	if t.RespondedAt != nil {
+		respondedAt := t.RespondedAt.Format(time.RFC3339)
+		response.RespondedAt = &respondedAt
+	}
+	return response
+}
+
+// transferErrorResponse maps transfer errors to responses
+func transferErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch err {
+	case transfer.ErrTransferNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Transfer not found",
+		})
+	case transfer.ErrResourceNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Resource not found",
+		})
+	case transfer.ErrInvalidResourceType:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid resource type, must be 'file' or 'folder'",
+		})
+	case transfer.ErrInvalidRecipient:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Recipient not found or is yourself",
+		})
+	case transfer.ErrTransferPending:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "The resource already has a pending transfer",
+		})
+	case transfer.ErrTransferNotPending:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "The transfer is no longer pending",
+		})
+	case user.ErrStorageQuotaExceeded:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Storage quota exceeded",
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+}
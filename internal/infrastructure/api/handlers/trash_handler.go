@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"easy-storage/internal/domain/trash"
+	"easy-storage/internal/infrastructure/api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TrashHandler handles trash-related API endpoints
+type TrashHandler struct {
+	trashService *trash.Service
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService *trash.Service) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// ListTrash lists the folders and files in the user's trash
+func (h *TrashHandler) ListTrash(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	folders, files, err := h.trashService.List(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not list trash",
+		})
+	}
+
+	response := dto.TrashResponse{
+		Folders: make([]dto.TrashedFolderResponse, len(folders)),
+		Files:   make([]dto.TrashedFileResponse, len(files)),
+	}
+	for i, trashedFolder := range folders {
+		response.Folders[i] = dto.TrashedFolderResponse{
+			ID:        trashedFolder.ID,
+			Name:      trashedFolder.Name,
+			ParentID:  trashedFolder.ParentID,
+			TrashedAt: trashedFolder.TrashedAt.Format(time.RFC3339),
+		}
+	}
+	for i, trashedFile := range files {
+		response.Files[i] = dto.TrashedFileResponse{
+			ID:          trashedFile.ID,
+			Name:        trashedFile.Name,
+			Size:        trashedFile.Size,
+			ContentType: trashedFile.ContentType,
+			FolderID:    trashedFile.FolderID,
+			TrashedAt:   trashedFile.TrashedAt.Format(time.RFC3339),
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// RestoreFile takes a file out of the trash
+func (h *TrashHandler) RestoreFile(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	restoredFile, err := h.trashService.RestoreFile(c.Params("id"), userID)
+	if err != nil {
+		return trashErrorResponse(c, err, "Could not restore file")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fileResponse(restoredFile))
+}
+
+// RestoreFolder takes a folder and its contents out of the trash
+func (h *TrashHandler) RestoreFolder(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	restoredFolder, err := h.trashService.RestoreFolder(c.Params("id"), userID)
+	if err != nil {
+		return trashErrorResponse(c, err, "Could not restore folder")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.FolderResponse{
+		ID:        restoredFolder.ID,
+		Name:      restoredFolder.Name,
+		ParentID:  restoredFolder.ParentID,
+		CreatedAt: restoredFolder.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: restoredFolder.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// DeleteFile permanently deletes a file in the trash
+func (h *TrashHandler) DeleteFile(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.trashService.DeleteFile(c.Params("id"), userID); err != nil {
+		return trashErrorResponse(c, err, "Could not delete file")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteFolder permanently deletes a folder in the trash and its contents
+func (h *TrashHandler) DeleteFolder(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.trashService.DeleteFolder(c.Params("id"), userID); err != nil {
+		return trashErrorResponse(c, err, "Could not delete folder")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// EmptyTrash permanently deletes everything in the trash
+func (h *TrashHandler) EmptyTrash(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.trashService.Empty(userID); err != nil {
+		return trashErrorResponse(c, err, "Could not empty trash")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// trashErrorResponse maps trash errors to responses
+func trashErrorResponse(c *fiber.Ctx, err error, message string) error {
+	if err == trash.ErrItemNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Item not found in trash",
+		})
+	}
+
+	log.Printf("Trash operation failed: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/domain/upload"
+	"easy-storage/internal/domain/user"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// tus protocol constants, see https://tus.io/protocols/resumable-upload
+const (
+	tusVersion             = "1.0.0"
+	tusExtensions          = "creation,termination,checksum"
+	tusChecksumAlgorithms  = "md5,sha1,sha256"
+	tusOffsetContentType   = "application/offset+octet-stream"
+	statusChecksumMismatch = 460
+)
+
+// UploadHandler implements the tus resumable upload protocol
+type UploadHandler struct {
+	uploadService *upload.Service
+}
+
+// NewUploadHandler creates a new upload handler
+func NewUploadHandler(uploadService *upload.Service) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+	}
+}
+
+// TusResumable rejects requests for unsupported tus versions
+func (h *UploadHandler) TusResumable(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+
+	if c.Get("Tus-Resumable") != tusVersion {
+		c.Set("Tus-Version", tusVersion)
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": "Unsupported tus version",
+		})
+	}
+
+	return c.Next()
+}
+
+// Options describes the server's tus capabilities
+func (h *UploadHandler) Options(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Checksum-Algorithm", tusChecksumAlgorithms)
+	if maxSize := h.uploadService.MaxSize(); maxSize > 0 {
+		c.Set("Tus-Max-Size", strconv.FormatInt(maxSize, 10))
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateUpload handles the tus creation extension
+func (h *UploadHandler) CreateUpload(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	// Deferred lengths are not supported, the client must announce the size
+	size, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Upload-Length header is required",
+		})
+	}
+
+	metadata := parseUploadMetadata(c.Get("Upload-Metadata"))
+
+	filename := metadata["filename"]
+	if filename == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "filename metadata is required",
+		})
+	}
+
+	contentType := metadata["filetype"]
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// Create upload
+	newUpload, err := h.uploadService.CreateUpload(userID, metadata["folder_id"], filename, contentType, size)
+	if err != nil {
+		return uploadErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderLocation, c.BaseURL()+"/api/uploads/"+newUpload.ID)
+	c.Set("Upload-Offset", strconv.FormatInt(newUpload.Offset, 10))
+	if newUpload.IsComplete() {
+		c.Set("X-File-Id", newUpload.FileID)
+	}
+
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// GetUploadOffset reports how many bytes of an upload have been received
+func (h *UploadHandler) GetUploadOffset(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	existingUpload, err := h.uploadService.GetUpload(c.Params("id"), userID)
+	if err != nil {
+		return uploadErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("Upload-Offset", strconv.FormatInt(existingUpload.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(existingUpload.Size, 10))
+	if existingUpload.IsComplete() {
+		c.Set("X-File-Id", existingUpload.FileID)
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// PatchUpload appends a chunk to an upload
+func (h *UploadHandler) PatchUpload(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if c.Get(fiber.HeaderContentType) != tusOffsetContentType {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": "Content-Type must be " + tusOffsetContentType,
+		})
+	}
+
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Upload-Offset header is required",
+		})
+	}
+
+	chunk := requestBody(c)
+
+	// Verify the chunk checksum before any byte is written
+	if checksumHeader := c.Get("Upload-Checksum"); checksumHeader != "" {
+		verifiedChunk, err := verifyChunkChecksum(checksumHeader, chunk)
+		if err != nil {
+			return checksumErrorResponse(c, err)
+		}
+		defer verifiedChunk.Close()
+		chunk = verifiedChunk
+	}
+
+	updatedUpload, err := h.uploadService.WriteChunk(c.Params("id"), userID, offset, chunk)
+	if err != nil {
+		return uploadErrorResponse(c, err)
+	}
+
+	c.Set("Upload-Offset", strconv.FormatInt(updatedUpload.Offset, 10))
+	if updatedUpload.IsComplete() {
+		c.Set("X-File-Id", updatedUpload.FileID)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// TerminateUpload handles the tus termination extension
+func (h *UploadHandler) TerminateUpload(c *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID := c.Locals("userID").(string)
+
+	if err := h.uploadService.TerminateUpload(c.Params("id"), userID); err != nil {
+		return uploadErrorResponse(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// uploadErrorResponse maps upload errors to tus responses
+func uploadErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case upload.ErrUploadNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Upload not found",
+		})
+	case upload.ErrUploadExpired:
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "Upload has expired",
+		})
+	case upload.ErrOffsetMismatch:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Upload-Offset does not match the current offset",
+		})
+	case upload.ErrUploadTooLarge:
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": "Upload exceeds the maximum size",
+		})
+	case upload.ErrInvalidSize:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid Upload-Length",
+		})
+	case file.ErrInvalidFolder:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid folder",
+		})
+	case user.ErrStorageQuotaExceeded:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Storage quota exceeded",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not process upload",
+		})
+	}
+}
+
+// requestBody returns the request body as a stream when streaming is enabled
+func requestBody(c *fiber.Ctx) io.Reader {
+	if stream := c.Request().BodyStream(); stream != nil {
+		return stream
+	}
+	return bytes.NewReader(c.Body())
+}
+
+// parseUploadMetadata decodes the tus Upload-Metadata header
+// The header is a comma separated list of "key base64(value)" pairs
+func parseUploadMetadata(header string) map[string]string {
+	metadata := make(map[string]string)
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 {
+			continue
+		}
+
+		value := ""
+		if len(fields) > 1 {
+			decoded, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				continue
+			}
+			value = string(decoded)
+		}
+		metadata[fields[0]] = value
+	}
+
+	return metadata
+}
+
+// errChecksumMismatch and errUnsupportedChecksum describe invalid Upload-Checksum headers
+var (
+	errChecksumMismatch    = fiber.NewError(statusChecksumMismatch, "Checksum mismatch")
+	errUnsupportedChecksum = fiber.NewError(fiber.StatusBadRequest, "Unsupported checksum algorithm")
+)
+
+// verifyChunkChecksum spools a chunk to a temporary file while hashing it
+// and returns the spooled chunk only if it matches the Upload-Checksum header
+func verifyChunkChecksum(header string, chunk io.Reader) (io.ReadCloser, error) {
+	fields := strings.Fields(header)
+	if len(fields) != 2 {
+		return nil, errUnsupportedChecksum
+	}
+
+	hasher := newChecksumHash(fields[0])
+	if hasher == nil {
+		return nil, errUnsupportedChecksum
+	}
+
+	expected, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, errUnsupportedChecksum
+	}
+
+	spool, err := os.CreateTemp("", "tus-chunk-*")
+	if err != nil {
+		return nil, err
+	}
+	spooled := &spooledChunk{File: spool}
+
+	if _, err := io.Copy(io.MultiWriter(spool, hasher), chunk); err != nil {
+		spooled.Close()
+		return nil, err
+	}
+
+	if !bytes.Equal(hasher.Sum(nil), expected) {
+		spooled.Close()
+		return nil, errChecksumMismatch
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		spooled.Close()
+		return nil, err
+	}
+
+	return spooled, nil
+}
+
+// newChecksumHash returns the hash for a tus checksum algorithm, or nil if unsupported
+func newChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "md5":
+		return md5.New()
+	case "sha1":
+		return sha1.New()
+	case "sha256":
+		return sha256.New()
+	default:
+		return nil
+	}
+}
+
+// checksumErrorResponse maps checksum verification errors to tus responses
+func checksumErrorResponse(c *fiber.Ctx, err error) error {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{
+			"error": fiberErr.Message,
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Could not read upload chunk",
+	})
+}
+
+// spooledChunk is a temporary file removed when closed
+type spooledChunk struct {
+	*os.File
+}
+
+// Close closes and removes the temporary file
+func (s *spooledChunk) Close() error {
+	err := s.File.Close()
+	os.Remove(s.File.Name())
+	return err
+}
@@ -2,15 +2,18 @@
 package middleware
 
 import (
+	"log"
 	"strings"
 
+	"easy-storage/internal/domain/auth"
 	"easy-storage/internal/infrastructure/auth/jwt"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// AuthMiddleware creates middleware for JWT authentication
-func AuthMiddleware(jwtProvider *jwt.Provider) fiber.Handler {
+// AuthMiddleware creates middleware for JWT and personal access token authentication
+// Access tokens of sessions that were signed out are rejected
+func AuthMiddleware(jwtProvider *jwt.Provider, authService *auth.Service, personalTokens *auth.PersonalTokenService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get the Authorization header
 		authHeader := c.Get("Authorization")
@@ -31,18 +34,98 @@ func AuthMiddleware(jwtProvider *jwt.Provider) fiber.Handler {
 		// Extract the token
 		tokenString := tokenParts[1]
 
-		// Validate the token
+		// Scripts and integrations authenticate with personal access tokens
+		if auth.IsPersonalToken(tokenString) {
+			return authenticatePersonalToken(c, personalTokens, tokenString)
+		}
+
+		// Validate the token, refresh tokens cannot authenticate requests
 		claims, err := jwtProvider.ValidateToken(tokenString)
-		if err != nil {
+		if err != nil || claims.TokenType != jwt.AccessToken {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid or expired token",
 			})
 		}
 
+		// Check the session of the token is still active
+		active, err := sessionActive(authService, claims)
+		if err != nil {
+			log.Printf("Error checking session %s: %v", claims.SessionID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not verify session",
+			})
+		}
+		if !active {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Session has been signed out",
+			})
+		}
+
+		// Set user info in context
+		c.Locals("userID", claims.UserID)
+		c.Locals("email", claims.Email)
+		c.Locals("sessionID", claims.SessionID)
+
+		return c.Next()
+	}
+}
+
+// OptionalAuthMiddleware identifies the user of public endpoints when a valid token is sent
+// Requests without a token, or with an invalid one, continue anonymously,
+// personal access tokens are not accepted as they are not scoped for public endpoints
+func OptionalAuthMiddleware(jwtProvider *jwt.Provider, authService *auth.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenParts := strings.Split(c.Get("Authorization"), " ")
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+			return c.Next()
+		}
+
+		claims, err := jwtProvider.ValidateToken(tokenParts[1])
+		if err != nil || claims.TokenType != jwt.AccessToken {
+			return c.Next()
+		}
+
+		if active, err := sessionActive(authService, claims); err != nil || !active {
+			return c.Next()
+		}
+
 		// Set user info in context
 		c.Locals("userID", claims.UserID)
 		c.Locals("email", claims.Email)
+		c.Locals("sessionID", claims.SessionID)
 
 		return c.Next()
 	}
 }
+
+// authenticatePersonalToken identifies the user of a personal access token,
+// the routes the token can reach are restricted by RequireScope
+func authenticatePersonalToken(c *fiber.Ctx, personalTokens *auth.PersonalTokenService, token string) error {
+	personalToken, err := personalTokens.Authenticate(token)
+	if err != nil {
+		if err == auth.ErrInvalidPersonalToken {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+		log.Printf("Error checking personal access token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Could not verify token",
+		})
+	}
+
+	// Set user info in context
+	c.Locals("userID", personalToken.UserID)
+	c.Locals("personalToken", personalToken)
+
+	return c.Next()
+}
+
+// sessionActive checks if the session of an access token was not signed out
+// Tokens issued before sessions existed carry no session and stay valid until they expire
+func sessionActive(authService *auth.Service, claims *jwt.Claims) (bool, error) {
+	if claims.SessionID == "" {
+		return true, nil
+	}
+	return authService.IsSessionActive(claims.SessionID)
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"easy-storage/internal/domain/auth"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope restricts requests made with a personal access token to tokens given the scope
+// Requests made with the access token of a session are not restricted
+func RequireScope(scope auth.Scope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		personalToken, ok := c.Locals("personalToken").(*auth.PersonalAccessToken)
+		if ok && !personalToken.HasScope(scope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":          "Token is missing the required scope",
+				"required_scope": scope,
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireSession rejects requests made with a personal access token,
+// for account endpoints only the user themselves can use
+func RequireSession(c *fiber.Ctx) error {
+	if _, ok := c.Locals("personalToken").(*auth.PersonalAccessToken); ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Personal access tokens cannot use this endpoint",
+		})
+	}
+
+	return c.Next()
+}
@@ -3,13 +3,19 @@ package api
 
 import (
 	"easy-storage/internal/domain/access"
+	"easy-storage/internal/domain/auth"
 	"easy-storage/internal/domain/file"
 	"easy-storage/internal/domain/folder"
+	"easy-storage/internal/domain/group"
 	"easy-storage/internal/domain/share"
+	"easy-storage/internal/domain/transfer"
+	"easy-storage/internal/domain/trash"
+	"easy-storage/internal/domain/upload"
 	"easy-storage/internal/domain/user"
 	"easy-storage/internal/infrastructure/api/handlers"
 	"easy-storage/internal/infrastructure/api/middleware"
 	"easy-storage/internal/infrastructure/auth/jwt"
+	"easy-storage/internal/infrastructure/storage/local"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,51 +24,179 @@ import (
 func SetupRoutes(
 	app *fiber.App,
 	userService *user.Service,
+	authService *auth.Service,
+	personalTokenService *auth.PersonalTokenService,
+	mfaService *auth.MFAService,
 	fileService *file.Service,
 	folderService *folder.Service,
 	shareService *share.Service,
+	groupService *group.Service,
+	transferService *transfer.Service,
 	accessService *access.Service,
+	trashService *trash.Service,
+	uploadService *upload.Service,
+	directUploadService *upload.DirectService,
 	jwtProvider *jwt.Provider,
 ) {
-	authHandler := handlers.NewAuthHandler(userService, jwtProvider)
+	authHandler := handlers.NewAuthHandler(userService, authService)
+	sessionHandler := handlers.NewSessionHandler(authService)
+	personalTokenHandler := handlers.NewPersonalTokenHandler(personalTokenService)
+	mfaHandler := handlers.NewMFAHandler(mfaService, userService)
+	jwksHandler := handlers.NewJWKSHandler(jwtProvider)
 	fileHandler := handlers.NewFileHandler(fileService, accessService)
-	folderHandler := handlers.NewFolderHandler(folderService)
-	shareHandler := handlers.NewShareHandler(shareService, fileService, accessService)
+	fileVersionHandler := handlers.NewFileVersionHandler(fileService, accessService)
+	folderHandler := handlers.NewFolderHandler(folderService, fileService, accessService)
+	shareHandler := handlers.NewShareHandler(shareService, fileService, folderService, accessService)
+	groupHandler := handlers.NewGroupHandler(groupService, userService)
+	transferHandler := handlers.NewTransferHandler(transferService, userService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	uploadHandler := handlers.NewUploadHandler(uploadService)
+	directUploadHandler := handlers.NewDirectUploadHandler(directUploadService)
 
 	// Auth routes
-	auth := app.Group("/api/auth")
-	auth.Post("/register", authHandler.Register)
-	auth.Post("/login", authHandler.Login)
-	auth.Post("/refresh", authHandler.RefreshToken)
+	authGroup := app.Group("/api/auth")
+	authGroup.Post("/register", authHandler.Register)
+	authGroup.Post("/login", authHandler.Login)
+	authGroup.Post("/mfa", authHandler.VerifyMFA)
+	authGroup.Post("/refresh", authHandler.RefreshToken)
+	authGroup.Post("/logout", authHandler.Logout)
+
+	// Public keys of the access tokens, for services verifying them
+	app.Get("/.well-known/jwks.json", jwksHandler.GetKeys)
+
+	// tus discovery requests are answered without authentication
+	app.Options("/api/uploads", uploadHandler.Options)
+	app.Options("/api/uploads/:id", uploadHandler.Options)
 
 	// Protected routes
-	api := app.Group("/api", middleware.AuthMiddleware(jwtProvider))
+	// Personal access tokens only reach the routes their scopes allow
+	api := app.Group("/api", middleware.AuthMiddleware(jwtProvider, authService, personalTokenService))
+	filesRead := middleware.RequireScope(auth.ScopeFilesRead)
+	filesWrite := middleware.RequireScope(auth.ScopeFilesWrite)
+
 	api.Get("/me", authHandler.GetMe)
-	api.Post("/auth/change-password", authHandler.ChangePassword)
+	api.Post("/auth/logout-all", middleware.RequireSession, authHandler.LogoutAll)
+	api.Post("/auth/change-password", middleware.RequireSession, authHandler.ChangePassword)
+
+	// Session routes
+	sessionRoutes := api.Group("/me/sessions", middleware.RequireSession)
+	sessionRoutes.Get("/", sessionHandler.ListSessions)
+	sessionRoutes.Delete("/:id", sessionHandler.RevokeSession)
+
+	// Personal access token routes
+	personalTokenRoutes := api.Group("/me/tokens", middleware.RequireSession)
+	personalTokenRoutes.Post("/", personalTokenHandler.CreateToken)
+	personalTokenRoutes.Get("/", personalTokenHandler.ListTokens)
+	personalTokenRoutes.Delete("/:id", personalTokenHandler.RevokeToken)
+
+	// Two-factor authentication routes
+	mfaRoutes := api.Group("/me/mfa", middleware.RequireSession)
+	mfaRoutes.Get("/", mfaHandler.GetStatus)
+	mfaRoutes.Post("/totp", mfaHandler.EnrollTOTP)
+	mfaRoutes.Post("/totp/confirm", mfaHandler.ConfirmTOTP)
+	mfaRoutes.Delete("/totp", mfaHandler.DisableTOTP)
+	mfaRoutes.Post("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
 
 	// File routes
 	fileRoutes := api.Group("/files")
-	fileRoutes.Post("/", fileHandler.UploadFile)
-	fileRoutes.Get("/", fileHandler.ListFiles)
-	fileRoutes.Get("/:id", fileHandler.DownloadFile)
-	fileRoutes.Delete("/:id", fileHandler.DeleteFile)
+	fileRoutes.Post("/", filesWrite, fileHandler.UploadFile)
+	fileRoutes.Get("/", filesRead, fileHandler.ListFiles)
+	fileRoutes.Post("/archive", filesRead, fileHandler.DownloadArchive)
+	fileRoutes.Get("/:id", filesRead, fileHandler.DownloadFile)
+	fileRoutes.Patch("/:id", filesWrite, fileHandler.UpdateFile)
+	fileRoutes.Delete("/:id", filesWrite, fileHandler.DeleteFile)
+	fileRoutes.Post("/:id/copy", filesWrite, fileHandler.CopyFile)
+
+	// File version routes
+	fileRoutes.Get("/:id/versions", filesRead, fileVersionHandler.ListVersions)
+	fileRoutes.Post("/:id/versions", filesWrite, fileVersionHandler.UploadVersion)
+	fileRoutes.Post("/:id/versions/prune", filesWrite, fileVersionHandler.PruneVersions)
+	fileRoutes.Get("/:id/versions/:version", filesRead, fileVersionHandler.DownloadVersion)
+	fileRoutes.Post("/:id/versions/:version/restore", filesWrite, fileVersionHandler.RestoreVersion)
+	fileRoutes.Delete("/:id/versions/:version", filesWrite, fileVersionHandler.DeleteVersion)
 
 	// Folder routes
 	folderRoutes := api.Group("/folders")
-	folderRoutes.Post("/", folderHandler.CreateFolder)
-	folderRoutes.Get("/", folderHandler.ListFolders)
-	folderRoutes.Get("/:folder_id", folderHandler.GetFolderContents)
-	folderRoutes.Delete("/:folder_id", folderHandler.DeleteFolder)
+	folderRoutes.Post("/", filesWrite, folderHandler.CreateFolder)
+	folderRoutes.Get("/", filesRead, folderHandler.ListFolders)
+	folderRoutes.Get("/:folder_id", filesRead, folderHandler.GetFolderContents)
+	folderRoutes.Get("/:folder_id/archive", filesRead, folderHandler.DownloadFolder)
+	folderRoutes.Patch("/:folder_id", filesWrite, folderHandler.UpdateFolder)
+	folderRoutes.Delete("/:folder_id", filesWrite, folderHandler.DeleteFolder)
+	folderRoutes.Post("/:folder_id/copy", filesWrite, folderHandler.CopyFolder)
+
+	// Trash routes
+	trashRoutes := api.Group("/trash")
+	trashRoutes.Get("/", filesRead, trashHandler.ListTrash)
+	trashRoutes.Delete("/", filesWrite, trashHandler.EmptyTrash)
+	trashRoutes.Post("/files/:id/restore", filesWrite, trashHandler.RestoreFile)
+	trashRoutes.Delete("/files/:id", filesWrite, trashHandler.DeleteFile)
+	trashRoutes.Post("/folders/:id/restore", filesWrite, trashHandler.RestoreFolder)
+	trashRoutes.Delete("/folders/:id", filesWrite, trashHandler.DeleteFolder)
+
+	// Resumable upload routes (tus protocol)
+	uploadRoutes := api.Group("/uploads", filesWrite, uploadHandler.TusResumable)
+	uploadRoutes.Post("/", uploadHandler.CreateUpload)
+	uploadRoutes.Head("/:id", uploadHandler.GetUploadOffset)
+	uploadRoutes.Patch("/:id", uploadHandler.PatchUpload)
+	uploadRoutes.Delete("/:id", uploadHandler.TerminateUpload)
+
+	// Direct upload routes, the file itself is sent to a signed storage URL
+	directUploadRoutes := api.Group("/direct-uploads", filesWrite)
+	directUploadRoutes.Post("/", directUploadHandler.InitiateUpload)
+	directUploadRoutes.Post("/:id/complete", directUploadHandler.CompleteUpload)
 
 	// Share routes
-	shareGroup := app.Group("/api/shares")
+	shareGroup := app.Group("/api/shares", middleware.RequireScope(auth.ScopeSharesManage))
 	shareGroup.Post("/", shareHandler.CreateShare)
 	shareGroup.Get("/", shareHandler.ListShares)
 	shareGroup.Get("/shared-with-me", shareHandler.ListSharesWithMe)
+	shareGroup.Post("/invitations/claim", shareHandler.ClaimInvitation)
+	shareGroup.Get("/resource/:type/:id", shareHandler.ListSharesByResource)
 	shareGroup.Get("/:id", shareHandler.GetShare)
+	shareGroup.Patch("/:id", shareHandler.UpdateShare)
 	shareGroup.Delete("/:id", shareHandler.RevokeShare)
+	shareGroup.Post("/:id/upload", shareHandler.UploadToShare)
+	shareGroup.Get("/:id/events", shareHandler.ListShareEvents)
+	shareGroup.Get("/:id/stats", shareHandler.GetShareStats)
+
+	// Group routes
+	groupRoutes := api.Group("/groups", middleware.RequireScope(auth.ScopeGroupsManage))
+	groupRoutes.Post("/", groupHandler.CreateGroup)
+	groupRoutes.Get("/", groupHandler.ListGroups)
+	groupRoutes.Get("/:id", groupHandler.GetGroup)
+	groupRoutes.Patch("/:id", groupHandler.UpdateGroup)
+	groupRoutes.Delete("/:id", groupHandler.DeleteGroup)
+	groupRoutes.Post("/:id/members", groupHandler.AddMember)
+	groupRoutes.Patch("/:id/members/:user_id", groupHandler.UpdateMember)
+	groupRoutes.Delete("/:id/members/:user_id", groupHandler.RemoveMember)
+
+	// Ownership transfer routes
+	transferRoutes := api.Group("/transfers", middleware.RequireScope(auth.ScopeTransfersManage))
+	transferRoutes.Post("/", transferHandler.CreateTransfer)
+	transferRoutes.Get("/", transferHandler.ListTransfers)
+	transferRoutes.Get("/:id", transferHandler.GetTransfer)
+	transferRoutes.Post("/:id/accept", transferHandler.AcceptTransfer)
+	transferRoutes.Post("/:id/decline", transferHandler.DeclineTransfer)
+	transferRoutes.Delete("/:id", transferHandler.CancelTransfer)
 
 	// Public share access endpoint (no auth required)
-	app.Get("/share/:token", shareHandler.AccessShare)
-	app.Get("/share/:token/download", shareHandler.DownloadSharedFile)
+	// Signed in users are identified in the share's access log
+	publicShare := app.Group("/share", middleware.OptionalAuthMiddleware(jwtProvider, authService))
+	publicShare.Get("/:token", shareHandler.AccessShare)
+	publicShare.Post("/:token", shareHandler.ValidateShareAccess)
+	publicShare.Get("/:token/download", shareHandler.DownloadSharedFile)
+	publicShare.Get("/:token/archive", shareHandler.DownloadSharedFolder)
+	publicShare.Get("/:token/contents", shareHandler.ListSharedFolderContents)
+	publicShare.Get("/:token/files/:file_id", shareHandler.DownloadSharedFolderFile)
+	publicShare.Post("/:token/upload", shareHandler.UploadToSharedFolder)
+}
+
+// SetupLocalStorageRoutes exposes the signed URLs of the local storage provider
+func SetupLocalStorageRoutes(app *fiber.App, provider *local.LocalProvider) {
+	localStorageHandler := handlers.NewLocalStorageHandler(provider)
+
+	// Public endpoint, access is granted by the URL signature
+	app.Get(local.RoutePrefix+"*", localStorageHandler.Download)
+	app.Put(local.RoutePrefix+"*", localStorageHandler.Upload)
 }
@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK is a public key in the JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`   // Modulus of RSA keys
+	E   string `json:"e,omitempty"`   // Exponent of RSA keys
+	Crv string `json:"crv,omitempty"` // Curve of Ed25519 keys
+	X   string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// JWKSet is a set of public keys in the JSON Web Key format
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys tokens are verified with, the signing key first
+// HS256 secrets are never published, the set is empty for HS256 tokens
+func (p *Provider) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	for _, keyID := range p.keyIDs {
+		verification := p.verificationKeys[keyID]
+		jwk := JWK{
+			Use: "sig",
+			Alg: verification.method.Alg(),
+			Kid: keyID,
+		}
+
+		switch key := verification.key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(key)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -3,41 +3,147 @@ package jwt
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
+	"easy-storage/internal/config"
 	"easy-storage/internal/domain/user"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
+// Token types, so a refresh token is never accepted as an access token and vice versa
+const (
+	// AccessToken authenticates API requests
+	AccessToken = "access"
+	// RefreshToken is only exchanged for new tokens
+	RefreshToken = "refresh"
+	// MFAPendingToken proves the password of a user was checked, it is exchanged for tokens with a second factor
+	MFAPendingToken = "mfa_pending"
+)
+
+// mfaTokenExpiry is how long a user has to enter their second factor after their password
+const mfaTokenExpiry = 5 * time.Minute
+
 // Claims represents the JWT claims
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	TokenType string `json:"token_type"`
+	SessionID string `json:"sid,omitempty"` // Session of an access token, tokens of revoked sessions are rejected
 	jwt.RegisteredClaims
 }
 
 // Provider handles JWT token generation and validation
 type Provider struct {
-	secretKey     string
-	tokenExpiry   time.Duration
-	refreshExpiry time.Duration
+	method     jwt.SigningMethod
+	signingKey interface{} // HMAC secret or private key
+	keyID      string      // Sent in the kid header, empty for HS256 tokens
+	// Keys tokens are verified with by key ID, HS256 tokens carry no key ID
+	verificationKeys map[string]verificationKey
+	keyIDs           []string // Key IDs in the order keys were configured, the signing key first
+	tokenExpiry      time.Duration
+	refreshExpiry    time.Duration
 }
 
 // NewProvider creates a new JWT provider
-func NewProvider(secretKey string, tokenExpiryHours, refreshExpiryDays int) *Provider {
-	return &Provider{
-		secretKey:     secretKey,
-		tokenExpiry:   time.Duration(tokenExpiryHours) * time.Hour,
-		refreshExpiry: time.Duration(refreshExpiryDays) * 24 * time.Hour,
+// HS256 tokens are signed with the JWT secret, RS256 and EdDSA tokens with the private key of
+// the signing key file. Tokens of previous keys stay valid while their keys are configured
+// as verification keys, so signing keys can be rotated without signing users out
+func NewProvider(cfg *config.AuthConfig) (*Provider, error) {
+	p := &Provider{
+		verificationKeys: make(map[string]verificationKey),
+		tokenExpiry:      time.Duration(cfg.TokenExpiry) * time.Hour,
+		refreshExpiry:    time.Duration(cfg.RefreshExpiry) * 24 * time.Hour,
+	}
+
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+		if cfg.JWTSecret == "" {
+			return nil, errors.New("JWT_SECRET is required to sign HS256 tokens")
+		}
+		p.method = jwt.SigningMethodHS256
+		p.signingKey = []byte(cfg.JWTSecret)
+		p.verificationKeys[""] = verificationKey{method: p.method, key: p.signingKey}
+	case "RS256", "EdDSA":
+		if err := p.loadSigningKey(cfg.JWTAlgorithm, cfg.SigningKeyFile); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.JWTAlgorithm)
 	}
+
+	for _, path := range cfg.VerificationKeyFiles {
+		publicKey, err := loadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.addVerificationKey(publicKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return p, nil
 }
 
-// GenerateToken generates a new JWT token for a user
-func (p *Provider) GenerateToken(user *user.User) (string, error) {
+// loadSigningKey loads the private key tokens are signed with, it must match the algorithm
+func (p *Provider) loadSigningKey(algorithm, path string) error {
+	if path == "" {
+		return fmt.Errorf("JWT_SIGNING_KEY_FILE is required to sign %s tokens", algorithm)
+	}
+
+	signer, err := loadPrivateKey(path)
+	if err != nil {
+		return err
+	}
+
+	method, err := signingMethodOf(signer.Public())
+	if err != nil {
+		return err
+	}
+	if method.Alg() != algorithm {
+		return fmt.Errorf("%s: the key signs %s tokens, not %s", path, method.Alg(), algorithm)
+	}
+
+	keyID, err := keyIDOf(signer.Public())
+	if err != nil {
+		return err
+	}
+
+	p.method = method
+	p.signingKey = signer
+	p.keyID = keyID
+	return p.addVerificationKey(signer.Public())
+}
+
+// addVerificationKey accepts the tokens signed with the private part of a public key
+func (p *Provider) addVerificationKey(publicKey interface{}) error {
+	method, err := signingMethodOf(publicKey)
+	if err != nil {
+		return err
+	}
+
+	keyID, err := keyIDOf(publicKey)
+	if err != nil {
+		return err
+	}
+
+	if _, exists := p.verificationKeys[keyID]; exists {
+		return nil
+	}
+	p.verificationKeys[keyID] = verificationKey{method: method, key: publicKey}
+	p.keyIDs = append(p.keyIDs, keyID)
+	return nil
+}
+
+// GenerateToken generates a new JWT access token for a user, bound to one of their sessions
+func (p *Provider) GenerateToken(user *user.User, sessionID string) (string, error) {
 	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenType: AccessToken,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(p.tokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -45,44 +151,74 @@ func (p *Provider) GenerateToken(user *user.User) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(p.secretKey))
+	return p.sign(claims)
+}
+
+// GenerateRefreshToken generates a refresh token and returns it with its expiration date
+// Each token gets a unique ID, so tokens issued in the same second never collide
+func (p *Provider) GenerateRefreshToken(user *user.User) (string, time.Time, error) {
+	expiresAt := time.Now().Add(p.refreshExpiry)
+	claims := &Claims{
+		UserID:    user.ID,
+		TokenType: RefreshToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	signedToken, err := p.sign(claims)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	return signedToken, nil
+	return signedToken, expiresAt, nil
 }
 
-// GenerateRefreshToken generates a refresh token
-func (p *Provider) GenerateRefreshToken(user *user.User) (string, error) {
+// GenerateMFAToken generates the short-lived token of a sign in waiting for a second factor
+func (p *Provider) GenerateMFAToken(user *user.User) (string, error) {
 	claims := &Claims{
-		UserID: user.ID,
+		UserID:    user.ID,
+		TokenType: MFAPendingToken,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(p.refreshExpiry)),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(p.secretKey))
+	return p.sign(claims)
+}
+
+// ParseMFAToken validates the token of a sign in waiting for a second factor and returns its user ID
+func (p *Provider) ParseMFAToken(tokenString string) (string, error) {
+	claims, err := p.ValidateToken(tokenString)
 	if err != nil {
 		return "", err
 	}
-
-	return signedToken, nil
+	if claims.TokenType != MFAPendingToken {
+		return "", errors.New("not a two-factor sign in token")
+	}
+	return claims.UserID, nil
 }
 
-// ValidateToken validates a JWT token
+// ValidateToken validates a JWT token of any type, callers check the token type they expect
 func (p *Provider) ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		keyID, _ := token.Header["kid"].(string)
+		verification, exists := p.verificationKeys[keyID]
+		if !exists {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != verification.method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return []byte(p.secretKey), nil
+		return verification.key, nil
 	})
 
 	if err != nil {
@@ -95,3 +231,12 @@ func (p *Provider) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// sign signs claims with the signing key, naming the key in the kid header
+func (p *Provider) sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(p.method, claims)
+	if p.keyID != "" {
+		token.Header["kid"] = p.keyID
+	}
+	return token.SignedString(p.signingKey)
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunPeriodically runs a job every interval until the context is cancelled
+// Errors are logged and do not stop subsequent runs
+func RunPeriodically(ctx context.Context, name string, interval time.Duration, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(); err != nil {
+					log.Printf("Job %s failed: %v", name, err)
+				}
+			}
+		}
+	}()
+}
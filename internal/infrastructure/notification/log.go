@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"context"
+	"log"
+
+	"easy-storage/internal/domain/share"
+)
+
+// LogNotifier writes notifications to the application log instead of sending them
+// Meant for development, where no mail server is available
+type LogNotifier struct {
+	appURL string
+}
+
+// NewLogNotifier creates a notifier that logs notifications
+func NewLogNotifier(appURL string) *LogNotifier {
+	return &LogNotifier{
+		appURL: appURL,
+	}
+}
+
+// NotifyInvitation logs a share invitation
+func (n *LogNotifier) NotifyInvitation(ctx context.Context, invitation share.Invitation) error {
+	msg := invitationMessage(invitation, n.appURL)
+	log.Printf("Share invitation to %s: %s\n%s", invitation.Email, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"easy-storage/internal/domain/share"
+)
+
+// message is the plain text content of an email
+type message struct {
+	Subject string
+	Body    string
+}
+
+// invitationMessage writes the email sent for a share invitation
+// Registered recipients find the share in their shared items, others get the one-time link claiming the invitation
+func invitationMessage(invitation share.Invitation, appURL string) message {
+	appURL = strings.TrimRight(appURL, "/")
+
+	inviter := invitation.InviterName
+	if inviter == "" {
+		inviter = invitation.InviterEmail
+	}
+
+	resource := invitation.ResourceType
+	if invitation.ResourceName != "" {
+		resource = fmt.Sprintf("%s %q", invitation.ResourceType, invitation.ResourceName)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi,\n\n%s (%s) shared the %s with you as %s.\n\n",
+		inviter, invitation.InviterEmail, resource, invitation.Permission)
+	if invitation.Registered {
+		fmt.Fprintf(&body, "Open it from your shared items: %s/shared-with-me\n", appURL)
+	} else {
+		fmt.Fprintf(&body, "Sign in or create an account, then accept the invitation to open it: %s/invitations/%s\n", appURL, url.PathEscape(invitation.Token))
+		body.WriteString("This link is personal and works once, do not forward it.\n")
+	}
+
+	return message{
+		Subject: fmt.Sprintf("%s shared a %s with you", inviter, invitation.ResourceType),
+		Body:    body.String(),
+	}
+}
@@ -0,0 +1,123 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"easy-storage/internal/config"
+	"easy-storage/internal/domain/share"
+
+	"github.com/google/uuid"
+)
+
+// smtpTimeout bounds the whole exchange with the mail server
+const smtpTimeout = 15 * time.Second
+
+// SMTPNotifier sends notifications by email through an SMTP server
+// STARTTLS is used when the server offers it, authentication only when a username is configured
+type SMTPNotifier struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     mail.Address
+	appURL   string
+}
+
+// NewSMTPNotifier creates a notifier sending emails through the configured SMTP server
+func NewSMTPNotifier(cfg *config.MailConfig) (*SMTPNotifier, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM address: %w", err)
+	}
+
+	return &SMTPNotifier{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     *from,
+		appURL:   cfg.AppURL,
+	}, nil
+}
+
+// NotifyInvitation emails a share invitation to its recipient
+func (n *SMTPNotifier) NotifyInvitation(ctx context.Context, invitation share.Invitation) error {
+	return n.send(ctx, invitation.Email, invitationMessage(invitation, n.appURL))
+}
+
+// send delivers a message to a single recipient
+func (n *SMTPNotifier) send(ctx context.Context, to string, msg message) error {
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.host, n.port))
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(n.buildEmail(to, msg)); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// buildEmail writes the headers and body of a plain text email
+// The subject is encoded, so names in it cannot inject headers
+func (n *SMTPNotifier) buildEmail(to string, msg message) []byte {
+	var email strings.Builder
+	email.WriteString("From: " + n.from.String() + "\r\n")
+	email.WriteString("To: " + to + "\r\n")
+	email.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	email.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	email.WriteString(fmt.Sprintf("Message-ID: <%s@%s>\r\n", uuid.New(), n.from.Address[strings.LastIndex(n.from.Address, "@")+1:]))
+	email.WriteString("MIME-Version: 1.0\r\n")
+	email.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	email.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	email.WriteString("\r\n")
+	email.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(email.String())
+}
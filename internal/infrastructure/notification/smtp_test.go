@@ -0,0 +1,141 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"easy-storage/internal/config"
+	"easy-storage/internal/domain/share"
+
+	"github.com/google/uuid"
+)
+
+// receivedEmail is what the SMTP stub got from one session
+type receivedEmail struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// startSMTPStub serves a single SMTP session on a local listener
+// It offers neither STARTTLS nor AUTH, the received email is sent on the returned channel
+func startSMTPStub(t *testing.T) (host, port string, received <-chan receivedEmail) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	emails := make(chan receivedEmail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) {
+			conn.Write([]byte(line + "\r\n"))
+		}
+
+		var email receivedEmail
+		reply("220 localhost ESMTP stub")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				email.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				email.recipients = append(email.recipients, strings.Trim(line[len("RCPT TO:"):], "<> "))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				email.data = data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				emails <- email
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	host, port, err = net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("SplitHostPort: %v", err)
+	}
+	return host, port, emails
+}
+
+func TestSMTPNotifierSendsInvitation(t *testing.T) {
+	host, port, received := startSMTPStub(t)
+
+	notifier, err := NewSMTPNotifier(&config.MailConfig{
+		SMTPHost: host,
+		SMTPPort: port,
+		From:     "Easy Storage <noreply@example.com>",
+		AppURL:   "https://app.example.com/",
+	})
+	if err != nil {
+		t.Fatalf("NewSMTPNotifier: %v", err)
+	}
+
+	invitation := share.Invitation{
+		ShareID:      uuid.New(),
+		Email:        "friend@example.com",
+		InviterName:  "Alice",
+		InviterEmail: "alice@example.com",
+		ResourceName: "report.pdf",
+		ResourceType: "file",
+		Permission:   share.Viewer,
+		Token:        "invite-token",
+	}
+	if err := notifier.NotifyInvitation(context.Background(), invitation); err != nil {
+		t.Fatalf("NotifyInvitation: %v", err)
+	}
+
+	email := <-received
+	if email.from != "noreply@example.com" {
+		t.Errorf("MAIL FROM = %q, want noreply@example.com", email.from)
+	}
+	if len(email.recipients) != 1 || email.recipients[0] != "friend@example.com" {
+		t.Errorf("RCPT TO = %v, want [friend@example.com]", email.recipients)
+	}
+	for _, want := range []string{
+		"To: friend@example.com\r\n",
+		"Alice (alice@example.com) shared the file \"report.pdf\" with you as viewer.",
+		"https://app.example.com/invitations/invite-token\r\n",
+	} {
+		if !strings.Contains(email.data, want) {
+			t.Errorf("email does not contain %q:\n%s", want, email.data)
+		}
+	}
+}
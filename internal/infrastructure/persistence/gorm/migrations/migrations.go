@@ -2,17 +2,116 @@
 package migrations
 
 import (
+	"easy-storage/internal/domain/share"
 	"easy-storage/internal/infrastructure/persistence/gorm/models"
 
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // Migrate runs database migrations
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := purgeDeletedBeforeTrash(db); err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(
 		&models.User{},
 		&models.File{},
+		&models.FileVersion{},
 		&models.Folder{},
 		&models.Share{},
-	)
+		&models.ShareAccessEvent{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.OwnershipTransfer{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.PersonalAccessToken{},
+		&models.TOTPEnrollment{},
+		&models.RecoveryCode{},
+		&models.Upload{},
+		&models.UploadReservation{},
+	); err != nil {
+		return err
+	}
+
+	if err := backfillFileVersions(db); err != nil {
+		return err
+	}
+
+	if err := hashSharePasswords(db); err != nil {
+		return err
+	}
+
+	return migrateSharePermissions(db)
+}
+
+// purgeDeletedBeforeTrash permanently deletes the files and folders deleted before the trash existed
+// Their content is already gone from storage and their size released, so they must not show up in the trash
+// Only runs on databases without the trash columns yet, rows deleted afterwards are in the trash
+func purgeDeletedBeforeTrash(db *gorm.DB) error {
+	for _, model := range []interface{}{&models.File{}, &models.Folder{}} {
+		if !db.Migrator().HasTable(model) || db.Migrator().HasColumn(model, "trashed_with") {
+			continue
+		}
+		if err := db.Unscoped().Where("deleted_at IS NOT NULL").Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillFileVersions records the content of files created before versioning as their first version
+func backfillFileVersions(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO file_versions (id, file_id, version, size, content_type, path, user_id, created_at)
+		SELECT gen_random_uuid(), f.id, f.version, f.size, f.content_type, f.path, f.user_id, f.created_at
+		FROM files f
+		WHERE f.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM file_versions v WHERE v.file_id = f.id)
+	`).Error
+}
+
+// bcryptHashPattern matches a whole bcrypt hash, a plaintext password merely starting with $2 does not
+const bcryptHashPattern = `^\$2[aby]\$\d{2}\$.{53}$`
+
+// hashSharePasswords replaces the plaintext passwords of shares created before passwords were hashed
+func hashSharePasswords(db *gorm.DB) error {
+	var shares []models.Share
+	if err := db.Unscoped().
+		Where("password IS NOT NULL AND password <> '' AND password !~ ?", bcryptHashPattern).
+		Find(&shares).Error; err != nil {
+		return err
+	}
+
+	for _, share := range shares {
+		hash, err := bcrypt.GenerateFromPassword([]byte(*share.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+
+		if err := db.Unscoped().Model(&models.Share{}).
+			Where("id = ? AND password = ?", share.ID, *share.Password).
+			UpdateColumn("password", string(hash)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateSharePermissions replaces the READ and WRITE permissions of shares created before roles
+// READ shares become viewer shares and WRITE shares become editor shares
+func migrateSharePermissions(db *gorm.DB) error {
+	for legacy, role := range map[string]share.SharePermission{"READ": share.Viewer, "WRITE": share.Editor} {
+		if err := db.Unscoped().Model(&models.Share{}).
+			Where("permission = ?", legacy).
+			UpdateColumn("permission", string(role)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
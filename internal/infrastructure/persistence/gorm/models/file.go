@@ -9,16 +9,18 @@ import (
 
 // File represents a file in the database
 type File struct {
-	ID          string `gorm:"primaryKey;type:uuid"`
-	Name        string `gorm:"not null"`
-	Size        int64  `gorm:"not null"`
-	ContentType string `gorm:"not null"`
-	Path        string `gorm:"not null"` // Path in the storage system
-	UserID      string `gorm:"type:uuid;not null"`
-	FolderID    string `gorm:"type:uuid;default:null"`
+	ID          string  `gorm:"primaryKey;type:uuid"`
+	Name        string  `gorm:"not null"`
+	Size        int64   `gorm:"not null"`
+	ContentType string  `gorm:"not null"`
+	Path        string  `gorm:"not null"` // Path in the storage system
+	UserID      string  `gorm:"type:uuid;not null"`
+	FolderID    *string `gorm:"type:uuid;default:null"`
+	Version     int     `gorm:"not null;default:1"` // Number of the current version
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
-	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`           // Set while the file is in the trash
+	TrashedWith *string        `gorm:"type:uuid;index"` // Folder whose deletion moved the file to the trash
 }
 
 // BeforeCreate will set a UUID rather than numeric ID
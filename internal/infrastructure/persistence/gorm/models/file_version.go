@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FileVersion represents a stored revision of a file in the database
+type FileVersion struct {
+	ID          string `gorm:"primaryKey;type:uuid"`
+	FileID      string `gorm:"type:uuid;not null;uniqueIndex:idx_file_versions_file_version"`
+	Version     int    `gorm:"not null;uniqueIndex:idx_file_versions_file_version"`
+	Size        int64  `gorm:"not null"`
+	ContentType string `gorm:"not null"`
+	Path        string `gorm:"not null"` // Path in the storage system
+	UserID      string `gorm:"type:uuid;not null"`
+	CreatedAt   time.Time
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (v *FileVersion) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -9,13 +9,14 @@ import (
 
 // Folder represents a folder in the database
 type Folder struct {
-	ID        string `gorm:"primaryKey;type:uuid"`
-	Name      string `gorm:"not null"`
-	ParentID  string `gorm:"type:uuid;default:null"`
-	UserID    string `gorm:"type:uuid;not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID          string  `gorm:"primaryKey;type:uuid"`
+	Name        string  `gorm:"not null"`
+	ParentID    *string `gorm:"type:uuid;default:null"`
+	UserID      string  `gorm:"type:uuid;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`           // Set while the folder is in the trash
+	TrashedWith *string        `gorm:"type:uuid;index"` // Folder whose deletion moved the folder to the trash
 }
 
 // BeforeCreate will set a UUID rather than numeric ID
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Group represents a team of users in the database
+type Group struct {
+	ID        string `gorm:"primaryKey;type:uuid"`
+	Name      string `gorm:"not null"`
+	CreatedBy string `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (g *Group) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// GroupMember represents the membership of a user in a group in the database
+type GroupMember struct {
+	GroupID  string `gorm:"primaryKey;type:uuid"`
+	UserID   string `gorm:"primaryKey;type:uuid;index"`
+	Role     string `gorm:"type:varchar(10);not null"`
+	JoinedAt time.Time
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TOTPEnrollment represents the TOTP authenticator of a user in the database
+type TOTPEnrollment struct {
+	UserID       string `gorm:"primaryKey;type:uuid"`
+	Secret       string `gorm:"type:varchar(64);not null"`
+	LastUsedStep int64  `gorm:"not null;default:0"`
+	ConfirmedAt  *time.Time
+	CreatedAt    time.Time
+}
+
+// RecoveryCode represents the hash of a two-factor recovery code in the database
+type RecoveryCode struct {
+	ID       string `gorm:"primaryKey;type:uuid"`
+	UserID   string `gorm:"type:uuid;not null;index"`
+	CodeHash string `gorm:"type:varchar(64);not null"`
+	UsedAt   *time.Time
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (c *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
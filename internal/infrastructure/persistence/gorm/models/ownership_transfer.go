@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OwnershipTransfer represents a request to hand a file or folder over to another user in the database
+type OwnershipTransfer struct {
+	ID           string `gorm:"primaryKey;type:uuid"`
+	ResourceID   string `gorm:"type:uuid;not null;index"`
+	ResourceType string `gorm:"type:varchar(10);not null"`
+	FromUserID   string `gorm:"type:uuid;not null;index"`
+	ToUserID     string `gorm:"type:uuid;not null;index"`
+	Status       string `gorm:"type:varchar(10);not null;index"`
+	CreatedAt    time.Time
+	RespondedAt  *time.Time
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (t *OwnershipTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
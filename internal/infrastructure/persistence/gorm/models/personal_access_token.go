@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken represents the hash of a personal access token in the database
+type PersonalAccessToken struct {
+	ID         string `gorm:"primaryKey;type:uuid"`
+	UserID     string `gorm:"type:uuid;not null;index"`
+	Name       string `gorm:"not null"`
+	TokenHash  string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Hint       string `gorm:"type:varchar(4);not null"`
+	Scopes     string `gorm:"not null"` // Space separated scopes
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (t *PersonalAccessToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session represents a sign in of a user on a device in the database
+type Session struct {
+	ID         string `gorm:"primaryKey;type:uuid"`
+	UserID     string `gorm:"type:uuid;not null;index"`
+	UserAgent  string `gorm:"type:varchar(512)"`
+	IP         string `gorm:"type:varchar(45)"`
+	CreatedAt  time.Time
+	LastUsedAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index"`
+	RevokedAt  *time.Time
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ type Share struct {
 	Type         string     `gorm:"type:varchar(10)"`
 	Permission   string     `gorm:"type:varchar(10)"`
 	RecipientID  *uuid.UUID `gorm:"type:uuid;index;null"`
+	GroupID      *uuid.UUID `gorm:"type:uuid;index;null"`
 	Token        string     `gorm:"type:varchar(255);index;null"`
 	Password     *string    `gorm:"type:varchar(255);null"`
 	ExpiresAt    *time.Time `gorm:"null"`
@@ -24,6 +25,17 @@ type Share struct {
 	AccessCount  int        `gorm:"default:0"`
 	LastAccessAt *time.Time `gorm:"null"`
 	IsRevoked    bool       `gorm:"default:false"`
+	UploadOnly   bool       `gorm:"default:false"`
+	MaxFiles     int        `gorm:"default:0"`
+	MaxFileSize  int64      `gorm:"default:0"`
+	UploadCount  int        `gorm:"default:0"`
+
+	MaxDownloads     int  `gorm:"default:0"`
+	DownloadCount    int  `gorm:"default:0"`
+	BurnAfterReading bool `gorm:"default:false"`
+
+	RecipientEmail  string `gorm:"type:varchar(255);index"`
+	InviteTokenHash string `gorm:"type:varchar(64);index"`
 }
 
 // BeforeCreate will set a UUID rather than numeric ID
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShareAccessEvent represents a recorded access to a share in the database
+type ShareAccessEvent struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key"`
+	ShareID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_share_access_events_share_time"`
+	Action     string     `gorm:"type:varchar(20);not null"`
+	Outcome    string     `gorm:"type:varchar(20);not null"`
+	IP         string     `gorm:"type:varchar(45)"`
+	UserAgent  string     `gorm:"type:varchar(512)"`
+	UserID     *uuid.UUID `gorm:"type:uuid;null"`
+	OccurredAt time.Time  `gorm:"not null;index:idx_share_access_events_share_time"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (e *ShareAccessEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Upload represents a resumable upload in the database
+type Upload struct {
+	ID              string  `gorm:"primaryKey;type:uuid"`
+	UserID          string  `gorm:"type:uuid;not null;index"`
+	FolderID        *string `gorm:"type:uuid"`
+	Filename        string  `gorm:"not null"`
+	ContentType     string  `gorm:"not null"`
+	Size            int64   `gorm:"not null"`
+	Offset          int64   `gorm:"column:upload_offset;not null;default:0"`
+	StoragePath     string
+	StorageUploadID string
+	Parts           string `gorm:"type:text"` // JSON encoded list of uploaded parts
+	PendingPath     string
+	PendingSize     int64     `gorm:"default:0"`
+	FileID          *string   `gorm:"type:uuid"`
+	ExpiresAt       time.Time `gorm:"index"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (u *Upload) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UploadReservation represents quota reserved for a direct upload in the database
+type UploadReservation struct {
+	ID          string    `gorm:"primaryKey;type:uuid"`
+	UserID      string    `gorm:"type:uuid;not null;index"`
+	FolderID    *string   `gorm:"type:uuid"`
+	Filename    string    `gorm:"not null"`
+	ContentType string    `gorm:"not null"`
+	Size        int64     `gorm:"not null"`
+	StoragePath string    `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"index"`
+	CreatedAt   time.Time
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *UploadReservation) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
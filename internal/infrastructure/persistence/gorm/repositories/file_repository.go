@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"easy-storage/internal/domain/file"
 	"easy-storage/internal/infrastructure/persistence/gorm/models"
@@ -28,7 +29,10 @@ func (r *GormFileRepository) Save(f *file.File) error {
 		ContentType: f.ContentType,
 		Path:        f.Path,
 		UserID:      f.UserID,
-		FolderID:    f.FolderID,
+		FolderID:    nullableString(f.FolderID),
+		Version:     f.Version,
+		CreatedAt:   f.CreatedAt,
+		UpdatedAt:   f.UpdatedAt,
 	}
 
 	if err := r.db.Save(fileModel).Error; err != nil {
@@ -36,6 +40,7 @@ func (r *GormFileRepository) Save(f *file.File) error {
 	}
 
 	f.ID = fileModel.ID
+	f.UpdatedAt = fileModel.UpdatedAt
 	return nil
 }
 
@@ -49,17 +54,7 @@ func (r *GormFileRepository) FindByID(id string) (*file.File, error) {
 		return nil, err
 	}
 
-	return &file.File{
-		ID:          fileModel.ID,
-		Name:        fileModel.Name,
-		Size:        fileModel.Size,
-		ContentType: fileModel.ContentType,
-		Path:        fileModel.Path,
-		UserID:      fileModel.UserID,
-		FolderID:    fileModel.FolderID,
-		CreatedAt:   fileModel.CreatedAt,
-		UpdatedAt:   fileModel.UpdatedAt,
-	}, nil
+	return mapFileModelToDomain(&fileModel), nil
 }
 
 // FindByUserID finds files by user ID with pagination
@@ -87,18 +82,8 @@ func (r *GormFileRepository) FindByUserID(userID string, limit, offset int, sort
 	}
 
 	files := make([]*file.File, len(fileModels))
-	for i, fileModel := range fileModels {
-		files[i] = &file.File{
-			ID:          fileModel.ID,
-			Name:        fileModel.Name,
-			Size:        fileModel.Size,
-			ContentType: fileModel.ContentType,
-			Path:        fileModel.Path,
-			UserID:      fileModel.UserID,
-			FolderID:    fileModel.FolderID,
-			CreatedAt:   fileModel.CreatedAt,
-			UpdatedAt:   fileModel.UpdatedAt,
-		}
+	for i := range fileModels {
+		files[i] = mapFileModelToDomain(&fileModels[i])
 	}
 
 	return files, nil
@@ -115,8 +100,8 @@ func (r *GormFileRepository) FindByUserIDAndFolder(userID string, folderID strin
 	query := r.db.Where("user_id = ?", userID)
 
 	if folderID == "" {
-		// Find files in the root folder (where folder_id is null or empty)
-		query = query.Where("folder_id IS NULL OR folder_id = ''")
+		// Find files in the root folder (where folder_id is null)
+		query = query.Where("folder_id IS NULL")
 	} else {
 		// Find files in the specified folder
 		query = query.Where("folder_id = ?", folderID)
@@ -127,23 +112,34 @@ func (r *GormFileRepository) FindByUserIDAndFolder(userID string, folderID strin
 	}
 
 	files := make([]*file.File, len(fileModels))
-	for i, fileModel := range fileModels {
-		files[i] = &file.File{
-			ID:          fileModel.ID,
-			Name:        fileModel.Name,
-			Size:        fileModel.Size,
-			ContentType: fileModel.ContentType,
-			Path:        fileModel.Path,
-			UserID:      fileModel.UserID,
-			FolderID:    fileModel.FolderID,
-			CreatedAt:   fileModel.CreatedAt,
-			UpdatedAt:   fileModel.UpdatedAt,
-		}
+	for i := range fileModels {
+		files[i] = mapFileModelToDomain(&fileModels[i])
 	}
 
 	return files, nil
 }
 
+// FindByName finds the file with the given name in a folder, an empty folderID is the root folder
+func (r *GormFileRepository) FindByName(userID, folderID, name string) (*file.File, error) {
+	query := r.db.Where("user_id = ? AND name = ?", userID, name)
+
+	if folderID == "" {
+		query = query.Where("folder_id IS NULL")
+	} else {
+		query = query.Where("folder_id = ?", folderID)
+	}
+
+	var fileModel models.File
+	if err := query.Order("created_at asc").First(&fileModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	return mapFileModelToDomain(&fileModel), nil
+}
+
 // DeleteByFolder deletes all files in the database that belong to a specific folder
 func (r *GormFileRepository) DeleteByFolder(folderID string) error {
 	// Find all files in the folder to get their paths
@@ -157,3 +153,94 @@ func (r *GormFileRepository) DeleteByFolder(folderID string) error {
 	// Delete all files from database
 	return r.db.Delete(&models.File{}, "folder_id = ?", folderID).Error
 }
+
+// Trash moves a file to the trash
+func (r *GormFileRepository) Trash(id, trashedWith string) error {
+	return r.db.Model(&models.File{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at":   time.Now(),
+		"trashed_with": nullableString(trashedWith),
+	}).Error
+}
+
+// TrashByFolder moves all files of a folder to the trash
+func (r *GormFileRepository) TrashByFolder(folderID, trashedWith string) error {
+	return r.db.Model(&models.File{}).Where("folder_id = ?", folderID).Updates(map[string]interface{}{
+		"deleted_at":   time.Now(),
+		"trashed_with": nullableString(trashedWith),
+	}).Error
+}
+
+// FindTrashed finds the files a user moved to the trash themselves, most recently trashed first
+func (r *GormFileRepository) FindTrashed(userID string) ([]*file.File, error) {
+	return r.findTrashed(r.db.Where("user_id = ? AND trashed_with IS NULL", userID))
+}
+
+// FindTrashedBefore finds files moved to the trash themselves before the given time
+func (r *GormFileRepository) FindTrashedBefore(before time.Time) ([]*file.File, error) {
+	return r.findTrashed(r.db.Where("trashed_with IS NULL AND deleted_at < ?", before))
+}
+
+// FindTrashedWith finds the files trashed along with a folder
+func (r *GormFileRepository) FindTrashedWith(folderID string) ([]*file.File, error) {
+	return r.findTrashed(r.db.Where("trashed_with = ?", folderID))
+}
+
+// FindTrashedByID finds a file in the trash by ID
+func (r *GormFileRepository) FindTrashedByID(id string) (*file.File, error) {
+	var fileModel models.File
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&fileModel, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	return mapFileModelToDomain(&fileModel), nil
+}
+
+// Restore takes a file out of the trash into the given folder under the given name
+func (r *GormFileRepository) Restore(id, folderID, name string) error {
+	return r.db.Unscoped().Model(&models.File{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at":   nil,
+		"trashed_with": nil,
+		"folder_id":    nullableString(folderID),
+		"name":         name,
+	}).Error
+}
+
+// Purge permanently deletes a file
+func (r *GormFileRepository) Purge(id string) error {
+	return r.db.Unscoped().Delete(&models.File{}, "id = ?", id).Error
+}
+
+// findTrashed runs a query over the files in the trash
+func (r *GormFileRepository) findTrashed(query *gorm.DB) ([]*file.File, error) {
+	var fileModels []models.File
+	if err := query.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(&fileModels).Error; err != nil {
+		return nil, err
+	}
+
+	files := make([]*file.File, len(fileModels))
+	for i := range fileModels {
+		files[i] = mapFileModelToDomain(&fileModels[i])
+	}
+
+	return files, nil
+}
+
+// mapFileModelToDomain converts a file model into a domain file
+func mapFileModelToDomain(m *models.File) *file.File {
+	return &file.File{
+		ID:          m.ID,
+		Name:        m.Name,
+		Size:        m.Size,
+		ContentType: m.ContentType,
+		Path:        m.Path,
+		UserID:      m.UserID,
+		FolderID:    stringValue(m.FolderID),
+		Version:     m.Version,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		TrashedAt:   m.DeletedAt.Time,
+	}
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"easy-storage/internal/domain/file"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+// GormFileVersionRepository implements the file.VersionRepository interface using GORM
+type GormFileVersionRepository struct {
+	db *gorm.DB
+}
+
+// NewGormFileVersionRepository creates a new file version repository
+func NewGormFileVersionRepository(db *gorm.DB) file.VersionRepository {
+	return &GormFileVersionRepository{db: db}
+}
+
+// Save creates or updates a file version in the database
+func (r *GormFileVersionRepository) Save(v *file.FileVersion) error {
+	versionModel := &models.FileVersion{
+		ID:          v.ID,
+		FileID:      v.FileID,
+		Version:     v.Version,
+		Size:        v.Size,
+		ContentType: v.ContentType,
+		Path:        v.Path,
+		UserID:      v.UserID,
+		CreatedAt:   v.CreatedAt,
+	}
+
+	if err := r.db.Save(versionModel).Error; err != nil {
+		return err
+	}
+
+	v.ID = versionModel.ID
+	return nil
+}
+
+// FindByFileID finds the versions of a file, newest first
+func (r *GormFileVersionRepository) FindByFileID(fileID string) ([]*file.FileVersion, error) {
+	var versionModels []models.FileVersion
+	if err := r.db.Where("file_id = ?", fileID).Order("version desc").Find(&versionModels).Error; err != nil {
+		return nil, err
+	}
+
+	versions := make([]*file.FileVersion, len(versionModels))
+	for i := range versionModels {
+		versions[i] = mapFileVersionModelToDomain(&versionModels[i])
+	}
+
+	return versions, nil
+}
+
+// FindByFileIDAndVersion finds a specific version of a file
+func (r *GormFileVersionRepository) FindByFileIDAndVersion(fileID string, version int) (*file.FileVersion, error) {
+	var versionModel models.FileVersion
+	if err := r.db.First(&versionModel, "file_id = ? AND version = ?", fileID, version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrVersionNotFound
+		}
+		return nil, err
+	}
+
+	return mapFileVersionModelToDomain(&versionModel), nil
+}
+
+// FindFileIDsWithVersionsBefore finds files having a non-current version created before the given time
+func (r *GormFileVersionRepository) FindFileIDsWithVersionsBefore(before time.Time) ([]string, error) {
+	var fileIDs []string
+	err := r.db.Model(&models.FileVersion{}).
+		Distinct("file_versions.file_id").
+		Joins("JOIN files ON files.id = file_versions.file_id AND files.deleted_at IS NULL").
+		Where("file_versions.created_at < ? AND file_versions.version <> files.version", before).
+		Pluck("file_versions.file_id", &fileIDs).Error
+	return fileIDs, err
+}
+
+// Delete deletes a file version
+func (r *GormFileVersionRepository) Delete(id string) error {
+	return r.db.Delete(&models.FileVersion{}, "id = ?", id).Error
+}
+
+// DeleteByFileID deletes all versions of a file
+func (r *GormFileVersionRepository) DeleteByFileID(fileID string) error {
+	return r.db.Delete(&models.FileVersion{}, "file_id = ?", fileID).Error
+}
+
+// mapFileVersionModelToDomain converts a file version model into a domain file version
+func mapFileVersionModelToDomain(m *models.FileVersion) *file.FileVersion {
+	return &file.FileVersion{
+		ID:          m.ID,
+		FileID:      m.FileID,
+		Version:     m.Version,
+		Size:        m.Size,
+		ContentType: m.ContentType,
+		Path:        m.Path,
+		UserID:      m.UserID,
+		CreatedAt:   m.CreatedAt,
+	}
+}
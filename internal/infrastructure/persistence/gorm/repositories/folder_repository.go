@@ -5,6 +5,7 @@ import (
 	"easy-storage/internal/infrastructure/persistence/gorm/models"
 	"errors"
 	"log"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -22,10 +23,12 @@ func NewGormFolderRepository(db *gorm.DB) folder.Repository {
 // Save creates or updates a folder in the database
 func (r *GormFolderRepository) Save(f *folder.Folder) error {
 	folderModel := &models.Folder{
-		ID:       f.ID,
-		Name:     f.Name,
-		ParentID: f.ParentID,
-		UserID:   f.UserID,
+		ID:        f.ID,
+		Name:      f.Name,
+		ParentID:  nullableString(f.ParentID),
+		UserID:    f.UserID,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
 	}
 
 	if err := r.db.Save(folderModel).Error; err != nil {
@@ -33,6 +36,7 @@ func (r *GormFolderRepository) Save(f *folder.Folder) error {
 	}
 
 	f.ID = folderModel.ID
+	f.UpdatedAt = folderModel.UpdatedAt
 	return nil
 }
 
@@ -47,14 +51,7 @@ func (r *GormFolderRepository) FindByID(id string) (*folder.Folder, error) {
 		return nil, err
 	}
 
-	return &folder.Folder{
-		ID:        folderModel.ID,
-		Name:      folderModel.Name,
-		ParentID:  folderModel.ParentID,
-		UserID:    folderModel.UserID,
-		CreatedAt: folderModel.CreatedAt,
-		UpdatedAt: folderModel.UpdatedAt,
-	}, nil
+	return mapFolderModelToDomain(&folderModel), nil
 }
 
 // FindByUserID finds folders by user ID
@@ -65,15 +62,8 @@ func (r *GormFolderRepository) FindByUserID(userID string) ([]*folder.Folder, er
 	}
 
 	folders := make([]*folder.Folder, len(folderModels))
-	for i, model := range folderModels {
-		folders[i] = &folder.Folder{
-			ID:        model.ID,
-			Name:      model.Name,
-			ParentID:  model.ParentID,
-			UserID:    model.UserID,
-			CreatedAt: model.CreatedAt,
-			UpdatedAt: model.UpdatedAt,
-		}
+	for i := range folderModels {
+		folders[i] = mapFolderModelToDomain(&folderModels[i])
 	}
 
 	return folders, nil
@@ -115,15 +105,8 @@ func (r *GormFolderRepository) FindByUserAndParent(userID string, parentID strin
 	}
 
 	folders := make([]folder.Folder, len(folderModels))
-	for i, model := range folderModels {
-		folders[i] = folder.Folder{
-			ID:        model.ID,
-			Name:      model.Name,
-			ParentID:  model.ParentID,
-			UserID:    model.UserID,
-			CreatedAt: model.CreatedAt,
-			UpdatedAt: model.UpdatedAt,
-		}
+	for i := range folderModels {
+		folders[i] = *mapFolderModelToDomain(&folderModels[i])
 	}
 
 	return folders, nil
@@ -159,15 +142,8 @@ func (r *GormFolderRepository) FindByUserAndParentPaginated(userID string, paren
 	}
 
 	folders := make([]folder.Folder, len(folderModels))
-	for i, model := range folderModels {
-		folders[i] = folder.Folder{
-			ID:        model.ID,
-			Name:      model.Name,
-			ParentID:  model.ParentID,
-			UserID:    model.UserID,
-			CreatedAt: model.CreatedAt,
-			UpdatedAt: model.UpdatedAt,
-		}
+	for i := range folderModels {
+		folders[i] = *mapFolderModelToDomain(&folderModels[i])
 	}
 
 	return folders, totalCount, nil
@@ -193,16 +169,109 @@ func (r *GormFolderRepository) FindAllByUserPaginated(userID string, page, pageS
 	}
 
 	folders := make([]folder.Folder, len(folderModels))
-	for i, model := range folderModels {
-		folders[i] = folder.Folder{
-			ID:        model.ID,
-			Name:      model.Name,
-			ParentID:  model.ParentID,
-			UserID:    model.UserID,
-			CreatedAt: model.CreatedAt,
-			UpdatedAt: model.UpdatedAt,
-		}
+	for i := range folderModels {
+		folders[i] = *mapFolderModelToDomain(&folderModels[i])
 	}
 
 	return folders, totalCount, nil
 }
+
+// FindByName finds the folder with the given name in a parent folder, an empty parentID is the root folder
+func (r *GormFolderRepository) FindByName(userID, parentID, name string) (*folder.Folder, error) {
+	query := r.db.Where("user_id = ? AND name = ?", userID, name)
+
+	if parentID == "" {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", parentID)
+	}
+
+	var folderModel models.Folder
+	if err := query.First(&folderModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, folder.ErrFolderNotFound
+		}
+		return nil, err
+	}
+
+	return mapFolderModelToDomain(&folderModel), nil
+}
+
+// Trash moves a folder to the trash
+func (r *GormFolderRepository) Trash(id, trashedWith string) error {
+	return r.db.Model(&models.Folder{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at":   time.Now(),
+		"trashed_with": nullableString(trashedWith),
+	}).Error
+}
+
+// FindTrashed finds the folders a user moved to the trash themselves, most recently trashed first
+func (r *GormFolderRepository) FindTrashed(userID string) ([]*folder.Folder, error) {
+	return r.findTrashed(r.db.Where("user_id = ? AND trashed_with IS NULL", userID))
+}
+
+// FindTrashedBefore finds folders moved to the trash themselves before the given time
+func (r *GormFolderRepository) FindTrashedBefore(before time.Time) ([]*folder.Folder, error) {
+	return r.findTrashed(r.db.Where("trashed_with IS NULL AND deleted_at < ?", before))
+}
+
+// FindTrashedWith finds the subfolders trashed along with a folder
+func (r *GormFolderRepository) FindTrashedWith(folderID string) ([]*folder.Folder, error) {
+	return r.findTrashed(r.db.Where("trashed_with = ?", folderID))
+}
+
+// FindTrashedByID finds a folder in the trash by ID
+func (r *GormFolderRepository) FindTrashedByID(id string) (*folder.Folder, error) {
+	var folderModel models.Folder
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&folderModel, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, folder.ErrFolderNotFound
+		}
+		return nil, err
+	}
+
+	return mapFolderModelToDomain(&folderModel), nil
+}
+
+// Restore takes a folder out of the trash into the given parent under the given name
+func (r *GormFolderRepository) Restore(id, parentID, name string) error {
+	return r.db.Unscoped().Model(&models.Folder{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at":   nil,
+		"trashed_with": nil,
+		"parent_id":    nullableString(parentID),
+		"name":         name,
+	}).Error
+}
+
+// Purge permanently deletes a folder
+func (r *GormFolderRepository) Purge(id string) error {
+	return r.db.Unscoped().Delete(&models.Folder{}, "id = ?", id).Error
+}
+
+// findTrashed runs a query over the folders in the trash
+func (r *GormFolderRepository) findTrashed(query *gorm.DB) ([]*folder.Folder, error) {
+	var folderModels []models.Folder
+	if err := query.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(&folderModels).Error; err != nil {
+		return nil, err
+	}
+
+	folders := make([]*folder.Folder, len(folderModels))
+	for i := range folderModels {
+		folders[i] = mapFolderModelToDomain(&folderModels[i])
+	}
+
+	return folders, nil
+}
+
+// mapFolderModelToDomain converts a folder model into a domain folder
+func mapFolderModelToDomain(m *models.Folder) *folder.Folder {
+	return &folder.Folder{
+		ID:        m.ID,
+		Name:      m.Name,
+		ParentID:  stringValue(m.ParentID),
+		UserID:    m.UserID,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+		TrashedAt: m.DeletedAt.Time,
+	}
+}
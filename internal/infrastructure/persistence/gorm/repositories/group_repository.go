@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"errors"
+
+	"easy-storage/internal/domain/group"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+// GormGroupRepository implements the group.Repository interface using GORM
+type GormGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewGormGroupRepository creates a new group repository
+func NewGormGroupRepository(db *gorm.DB) group.Repository {
+	return &GormGroupRepository{db: db}
+}
+
+// Create stores a new group along with its first owner
+func (r *GormGroupRepository) Create(g *group.Group, owner *group.Member) error {
+	groupModel := &models.Group{
+		Name:      g.Name,
+		CreatedBy: g.CreatedBy,
+		CreatedAt: g.CreatedAt,
+		UpdatedAt: g.UpdatedAt,
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(groupModel).Error; err != nil {
+			return err
+		}
+
+		owner.GroupID = groupModel.ID
+		if err := tx.Create(mapGroupMemberToModel(owner)).Error; err != nil {
+			return err
+		}
+
+		g.ID = groupModel.ID
+		return nil
+	})
+}
+
+// FindByID finds a group by ID
+func (r *GormGroupRepository) FindByID(id string) (*group.Group, error) {
+	var groupModel models.Group
+	if err := r.db.First(&groupModel, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, group.ErrGroupNotFound
+		}
+		return nil, err
+	}
+
+	return mapGroupModelToDomain(&groupModel), nil
+}
+
+// FindByMember finds the groups a user is a member of
+func (r *GormGroupRepository) FindByMember(userID string) ([]*group.Group, error) {
+	var groupModels []models.Group
+	if err := r.db.
+		Joins("JOIN group_members ON group_members.group_id = groups.id").
+		Where("group_members.user_id = ?", userID).
+		Order("groups.name").
+		Find(&groupModels).Error; err != nil {
+		return nil, err
+	}
+
+	groups := make([]*group.Group, 0, len(groupModels))
+	for i := range groupModels {
+		groups = append(groups, mapGroupModelToDomain(&groupModels[i]))
+	}
+
+	return groups, nil
+}
+
+// Update updates a group
+func (r *GormGroupRepository) Update(g *group.Group) error {
+	return r.db.Model(&models.Group{}).
+		Where("id = ?", g.ID).
+		Updates(map[string]interface{}{
+			"name":       g.Name,
+			"updated_at": g.UpdatedAt,
+		}).Error
+}
+
+// Delete removes a group and all its memberships
+func (r *GormGroupRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.GroupMember{}, "group_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Group{}, "id = ?", id).Error
+	})
+}
+
+// AddMember adds a user to a group
+func (r *GormGroupRepository) AddMember(member *group.Member) error {
+	return r.db.Create(mapGroupMemberToModel(member)).Error
+}
+
+// FindMember finds the membership of a user in a group
+func (r *GormGroupRepository) FindMember(groupID, userID string) (*group.Member, error) {
+	var memberModel models.GroupMember
+	if err := r.db.First(&memberModel, "group_id = ? AND user_id = ?", groupID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, group.ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	return mapGroupMemberModelToDomain(&memberModel), nil
+}
+
+// FindMembers finds the members of a group, owners first
+func (r *GormGroupRepository) FindMembers(groupID string) ([]*group.Member, error) {
+	var memberModels []models.GroupMember
+	if err := r.db.Where("group_id = ?", groupID).
+		Order(gorm.Expr("role = ? DESC, joined_at", string(group.RoleOwner))).
+		Find(&memberModels).Error; err != nil {
+		return nil, err
+	}
+
+	members := make([]*group.Member, 0, len(memberModels))
+	for i := range memberModels {
+		members = append(members, mapGroupMemberModelToDomain(&memberModels[i]))
+	}
+
+	return members, nil
+}
+
+// UpdateMember updates the role of a member
+func (r *GormGroupRepository) UpdateMember(member *group.Member) error {
+	return r.db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", member.GroupID, member.UserID).
+		Update("role", string(member.Role)).Error
+}
+
+// RemoveMember removes a user from a group
+func (r *GormGroupRepository) RemoveMember(groupID, userID string) error {
+	result := r.db.Delete(&models.GroupMember{}, "group_id = ? AND user_id = ?", groupID, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return group.ErrMemberNotFound
+	}
+	return nil
+}
+
+// CountOwners counts the owners of a group
+func (r *GormGroupRepository) CountOwners(groupID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND role = ?", groupID, string(group.RoleOwner)).
+		Count(&count).Error
+	return count, err
+}
+
+// FindGroupIDsByMember finds the IDs of the groups a user is a member of
+func (r *GormGroupRepository) FindGroupIDsByMember(userID string) ([]string, error) {
+	var groupIDs []string
+	err := r.db.Model(&models.GroupMember{}).
+		Where("user_id = ?", userID).
+		Pluck("group_id", &groupIDs).Error
+	return groupIDs, err
+}
+
+// mapGroupModelToDomain converts a group model into a domain group
+func mapGroupModelToDomain(m *models.Group) *group.Group {
+	return &group.Group{
+		ID:        m.ID,
+		Name:      m.Name,
+		CreatedBy: m.CreatedBy,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// mapGroupMemberToModel converts a domain member into a member model
+func mapGroupMemberToModel(member *group.Member) *models.GroupMember {
+	return &models.GroupMember{
+		GroupID:  member.GroupID,
+		UserID:   member.UserID,
+		Role:     string(member.Role),
+		JoinedAt: member.JoinedAt,
+	}
+}
+
+// mapGroupMemberModelToDomain converts a member model into a domain member
+func mapGroupMemberModelToDomain(m *models.GroupMember) *group.Member {
+	return &group.Member{
+		GroupID:  m.GroupID,
+		UserID:   m.UserID,
+		Role:     group.Role(m.Role),
+		JoinedAt: m.JoinedAt,
+	}
+}
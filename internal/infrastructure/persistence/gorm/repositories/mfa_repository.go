@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"easy-storage/internal/domain/auth"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+// GormMFARepository implements the auth.MFARepository interface using GORM
+type GormMFARepository struct {
+	db *gorm.DB
+}
+
+// NewGormMFARepository creates a new two-factor authentication repository
+func NewGormMFARepository(db *gorm.DB) auth.MFARepository {
+	return &GormMFARepository{db: db}
+}
+
+// SaveTOTP stores the TOTP enrollment of a user, replacing their previous one
+func (r *GormMFARepository) SaveTOTP(e *auth.TOTPEnrollment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.TOTPEnrollment{}, "user_id = ?", e.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TOTPEnrollment{
+			UserID:       e.UserID,
+			Secret:       e.Secret,
+			LastUsedStep: e.LastUsedStep,
+			ConfirmedAt:  e.ConfirmedAt,
+			CreatedAt:    e.CreatedAt,
+		}).Error
+	})
+}
+
+// FindTOTP finds the TOTP enrollment of a user
+func (r *GormMFARepository) FindTOTP(userID string) (*auth.TOTPEnrollment, error) {
+	var model models.TOTPEnrollment
+	if err := r.db.First(&model, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrMFANotEnabled
+		}
+		return nil, err
+	}
+
+	return &auth.TOTPEnrollment{
+		UserID:       model.UserID,
+		Secret:       model.Secret,
+		LastUsedStep: model.LastUsedStep,
+		ConfirmedAt:  model.ConfirmedAt,
+		CreatedAt:    model.CreatedAt,
+	}, nil
+}
+
+// ConfirmTOTP confirms the TOTP enrollment of a user with the step of its first code
+func (r *GormMFARepository) ConfirmTOTP(userID string, step int64, confirmedAt time.Time) error {
+	return r.db.Model(&models.TOTPEnrollment{}).
+		Where("user_id = ?", userID).
+		UpdateColumns(map[string]interface{}{
+			"last_used_step": step,
+			"confirmed_at":   confirmedAt,
+		}).Error
+}
+
+// UseTOTPStep records the step of an accepted code, the update only applies to later steps
+func (r *GormMFARepository) UseTOTPStep(userID string, step int64) (bool, error) {
+	result := r.db.Model(&models.TOTPEnrollment{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		UpdateColumn("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteTOTP deletes the TOTP enrollment and the recovery codes of a user
+func (r *GormMFARepository) DeleteTOTP(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.TOTPEnrollment{}, "user_id = ?", userID).Error
+	})
+}
+
+// ReplaceRecoveryCodes replaces every recovery code of a user
+func (r *GormMFARepository) ReplaceRecoveryCodes(userID string, codes []*auth.RecoveryCode) error {
+	codeModels := make([]models.RecoveryCode, len(codes))
+	for i, code := range codes {
+		codeModels[i] = models.RecoveryCode{
+			UserID:   code.UserID,
+			CodeHash: code.CodeHash,
+			UsedAt:   code.UsedAt,
+		}
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		if len(codeModels) == 0 {
+			return nil
+		}
+		if err := tx.Create(&codeModels).Error; err != nil {
+			return err
+		}
+
+		for i := range codes {
+			codes[i].ID = codeModels[i].ID
+		}
+		return nil
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code as used, the update only applies once
+func (r *GormMFARepository) UseRecoveryCode(userID, codeHash string, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		UpdateColumn("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountRecoveryCodes counts the unused recovery codes of a user
+func (r *GormMFARepository) CountRecoveryCodes(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,17 @@
+package repositories
+
+// nullableString converts an empty string into a NULL column value
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// stringValue converts a nullable column value into a string, empty when NULL
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
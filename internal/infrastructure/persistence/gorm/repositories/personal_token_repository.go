@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"easy-storage/internal/domain/auth"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+// GormPersonalTokenRepository implements the auth.PersonalTokenRepository interface using GORM
+type GormPersonalTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPersonalTokenRepository creates a new personal access token repository
+func NewGormPersonalTokenRepository(db *gorm.DB) auth.PersonalTokenRepository {
+	return &GormPersonalTokenRepository{db: db}
+}
+
+// Save stores a new personal access token
+func (r *GormPersonalTokenRepository) Save(t *auth.PersonalAccessToken) error {
+	model := mapPersonalTokenToModel(t)
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+
+	t.ID = model.ID
+	return nil
+}
+
+// FindByID finds a personal access token by its ID
+func (r *GormPersonalTokenRepository) FindByID(id string) (*auth.PersonalAccessToken, error) {
+	return r.findOne("id = ?", id)
+}
+
+// FindByHash finds a personal access token by its hash
+func (r *GormPersonalTokenRepository) FindByHash(tokenHash string) (*auth.PersonalAccessToken, error) {
+	return r.findOne("token_hash = ?", tokenHash)
+}
+
+// FindByUser lists the personal access tokens of a user, newest first
+func (r *GormPersonalTokenRepository) FindByUser(userID string) ([]*auth.PersonalAccessToken, error) {
+	var tokenModels []models.PersonalAccessToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokenModels).Error; err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*auth.PersonalAccessToken, len(tokenModels))
+	for i := range tokenModels {
+		tokens[i] = mapPersonalTokenModelToDomain(&tokenModels[i])
+	}
+	return tokens, nil
+}
+
+// UpdateLastUsed records when a personal access token was last used
+func (r *GormPersonalTokenRepository) UpdateLastUsed(id string, usedAt time.Time) error {
+	return r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).Error
+}
+
+// Delete deletes a personal access token
+func (r *GormPersonalTokenRepository) Delete(id string) error {
+	return r.db.Delete(&models.PersonalAccessToken{}, "id = ?", id).Error
+}
+
+// findOne finds the personal access token matching a condition
+func (r *GormPersonalTokenRepository) findOne(query string, args ...interface{}) (*auth.PersonalAccessToken, error) {
+	var model models.PersonalAccessToken
+	if err := r.db.Where(query, args...).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrPersonalTokenNotFound
+		}
+		return nil, err
+	}
+
+	return mapPersonalTokenModelToDomain(&model), nil
+}
+
+// mapPersonalTokenToModel converts a domain personal access token into a personal access token model
+func mapPersonalTokenToModel(t *auth.PersonalAccessToken) *models.PersonalAccessToken {
+	scopes := make([]string, len(t.Scopes))
+	for i, scope := range t.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return &models.PersonalAccessToken{
+		ID:         t.ID,
+		UserID:     t.UserID,
+		Name:       t.Name,
+		TokenHash:  t.TokenHash,
+		Hint:       t.Hint,
+		Scopes:     strings.Join(scopes, " "),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// mapPersonalTokenModelToDomain converts a personal access token model into a domain personal access token
+func mapPersonalTokenModelToDomain(m *models.PersonalAccessToken) *auth.PersonalAccessToken {
+	fields := strings.Fields(m.Scopes)
+	scopes := make([]auth.Scope, len(fields))
+	for i, field := range fields {
+		scopes[i] = auth.Scope(field)
+	}
+
+	return &auth.PersonalAccessToken{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Name:       m.Name,
+		TokenHash:  m.TokenHash,
+		Hint:       m.Hint,
+		Scopes:     scopes,
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
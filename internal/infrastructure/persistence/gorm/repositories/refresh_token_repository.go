@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"easy-storage/internal/domain/auth"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+// GormRefreshTokenRepository implements the auth.Repository interface using GORM
+type GormRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRefreshTokenRepository creates a new refresh token repository
+func NewGormRefreshTokenRepository(db *gorm.DB) auth.Repository {
+	return &GormRefreshTokenRepository{db: db}
+}
+
+// Save stores a new refresh token
+func (r *GormRefreshTokenRepository) Save(t *auth.RefreshToken) error {
+	model := mapRefreshTokenToModel(t)
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+
+	t.ID = model.ID
+	return nil
+}
+
+// FindByHash finds a refresh token by its hash
+func (r *GormRefreshTokenRepository) FindByHash(tokenHash string) (*auth.RefreshToken, error) {
+	var model models.RefreshToken
+	if err := r.db.First(&model, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	return mapRefreshTokenModelToDomain(&model), nil
+}
+
+// MarkUsed records the use of a token, the update only applies while it is neither used nor revoked
+func (r *GormRefreshTokenRepository) MarkUsed(id string, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		UpdateColumn("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeSession revokes every token of a session
+func (r *GormRefreshTokenRepository) RevokeSession(sessionID string) error {
+	return r.revoke("session_id = ?", sessionID)
+}
+
+// RevokeUser revokes every token of a user
+func (r *GormRefreshTokenRepository) RevokeUser(userID string) error {
+	return r.revoke("user_id = ?", userID)
+}
+
+// DeleteExpired deletes the tokens that expired before a date
+func (r *GormRefreshTokenRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.RefreshToken{}).Error
+}
+
+// revoke revokes the tokens matching a condition that are not revoked yet
+func (r *GormRefreshTokenRepository) revoke(query string, args ...interface{}) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		UpdateColumn("revoked_at", time.Now()).Error
+}
+
+// mapRefreshTokenToModel converts a domain refresh token into a refresh token model
+func mapRefreshTokenToModel(t *auth.RefreshToken) *models.RefreshToken {
+	return &models.RefreshToken{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		SessionID: t.SessionID,
+		TokenHash: t.TokenHash,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+		UsedAt:    t.UsedAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
+
+// mapRefreshTokenModelToDomain converts a refresh token model into a domain refresh token
+func mapRefreshTokenModelToDomain(m *models.RefreshToken) *auth.RefreshToken {
+	return &auth.RefreshToken{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		SessionID: m.SessionID,
+		TokenHash: m.TokenHash,
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+		UsedAt:    m.UsedAt,
+		RevokedAt: m.RevokedAt,
+	}
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"easy-storage/internal/domain/auth"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+// GormSessionRepository implements the auth.SessionRepository interface using GORM
+type GormSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSessionRepository creates a new session repository
+func NewGormSessionRepository(db *gorm.DB) auth.SessionRepository {
+	return &GormSessionRepository{db: db}
+}
+
+// Save stores a new session
+func (r *GormSessionRepository) Save(s *auth.Session) error {
+	model := mapSessionToModel(s)
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+
+	s.ID = model.ID
+	return nil
+}
+
+// FindByID finds a session by its ID
+func (r *GormSessionRepository) FindByID(id string) (*auth.Session, error) {
+	var model models.Session
+	if err := r.db.First(&model, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return mapSessionModelToDomain(&model), nil
+}
+
+// FindActiveByUser lists the sessions of a user that are neither revoked nor expired
+func (r *GormSessionRepository) FindActiveByUser(userID string) ([]*auth.Session, error) {
+	var sessionModels []models.Session
+	if err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessionModels).Error; err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*auth.Session, len(sessionModels))
+	for i := range sessionModels {
+		sessions[i] = mapSessionModelToDomain(&sessionModels[i])
+	}
+	return sessions, nil
+}
+
+// Touch records that tokens were issued for a session from a client
+func (r *GormSessionRepository) Touch(id string, client auth.Client, usedAt, expiresAt time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"ip":           client.IP,
+			"user_agent":   truncateUserAgent(client.UserAgent),
+			"last_used_at": usedAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+// Revoke revokes a session
+func (r *GormSessionRepository) Revoke(id string) error {
+	return r.revoke("id = ?", id)
+}
+
+// RevokeUser revokes every session of a user
+func (r *GormSessionRepository) RevokeUser(userID string) error {
+	return r.revoke("user_id = ?", userID)
+}
+
+// DeleteExpired deletes the sessions that expired before a date
+func (r *GormSessionRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.Session{}).Error
+}
+
+// revoke revokes the sessions matching a condition that are not revoked yet
+func (r *GormSessionRepository) revoke(query string, args ...interface{}) error {
+	return r.db.Model(&models.Session{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		UpdateColumn("revoked_at", time.Now()).Error
+}
+
+// mapSessionToModel converts a domain session into a session model
+func mapSessionToModel(s *auth.Session) *models.Session {
+	return &models.Session{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		UserAgent:  truncateUserAgent(s.UserAgent),
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+		RevokedAt:  s.RevokedAt,
+	}
+}
+
+// mapSessionModelToDomain converts a session model into a domain session
+func mapSessionModelToDomain(m *models.Session) *auth.Session {
+	return &auth.Session{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		UserAgent:  m.UserAgent,
+		IP:         m.IP,
+		CreatedAt:  m.CreatedAt,
+		LastUsedAt: m.LastUsedAt,
+		ExpiresAt:  m.ExpiresAt,
+		RevokedAt:  m.RevokedAt,
+	}
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"easy-storage/internal/domain/share"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+)
+
+// maxUserAgentLength is the size of the user_agent columns
+const maxUserAgentLength = 512
+
+// truncateUserAgent cuts a user agent to the size of the user_agent columns
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) > maxUserAgentLength {
+		return userAgent[:maxUserAgentLength]
+	}
+	return userAgent
+}
+
+// ShareAccessEventRepository implements the share.EventRepository interface using GORM
+type ShareAccessEventRepository struct {
+	db *gorm.DB
+}
+
+// NewShareAccessEventRepository creates a new share access event repository
+func NewShareAccessEventRepository(db *gorm.DB) *ShareAccessEventRepository {
+	return &ShareAccessEventRepository{
+		db: db,
+	}
+}
+
+// Create stores a new access event
+func (r *ShareAccessEventRepository) Create(ctx context.Context, e *share.AccessEvent) error {
+	model := &models.ShareAccessEvent{
+		ID:         e.ID,
+		ShareID:    e.ShareID,
+		Action:     string(e.Action),
+		Outcome:    string(e.Outcome),
+		IP:         e.IP,
+		UserAgent:  truncateUserAgent(e.UserAgent),
+		UserID:     e.UserID,
+		OccurredAt: e.OccurredAt,
+	}
+	return r.db.WithContext(ctx).Create(model).Error
+}
+
+// ListByShare retrieves a page of the events of a share, newest first, with the total number of events
+func (r *ShareAccessEventRepository) ListByShare(ctx context.Context, shareID uuid.UUID, page, pageSize int) ([]*share.AccessEvent, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.ShareAccessEvent{}).Where("share_id = ?", shareID).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var eventModels []models.ShareAccessEvent
+	offset := (page - 1) * pageSize
+	if err := query.Order("occurred_at DESC").Offset(offset).Limit(pageSize).Find(&eventModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]*share.AccessEvent, len(eventModels))
+	for i, m := range eventModels {
+		events[i] = &share.AccessEvent{
+			ID:         m.ID,
+			ShareID:    m.ShareID,
+			Action:     share.AccessAction(m.Action),
+			Outcome:    share.AccessOutcome(m.Outcome),
+			IP:         m.IP,
+			UserAgent:  m.UserAgent,
+			UserID:     m.UserID,
+			OccurredAt: m.OccurredAt,
+		}
+	}
+
+	return events, total, nil
+}
+
+// StatsByShare aggregates the events of a share
+func (r *ShareAccessEventRepository) StatsByShare(ctx context.Context, shareID uuid.UUID) (*share.AccessStats, error) {
+	var row struct {
+		TotalEvents    int64
+		Views          int64
+		Downloads      int64
+		Uploads        int64
+		FailedAttempts int64
+		UniqueVisitors int64
+		FirstAccessAt  *time.Time
+		LastAccessAt   *time.Time
+	}
+
+	success := string(share.OutcomeSuccess)
+	err := r.db.WithContext(ctx).Model(&models.ShareAccessEvent{}).
+		Select(`COUNT(*) AS total_events,
+			COUNT(*) FILTER (WHERE outcome = ? AND action = ?) AS views,
+			COUNT(*) FILTER (WHERE outcome = ? AND action = ?) AS downloads,
+			COUNT(*) FILTER (WHERE outcome = ? AND action = ?) AS uploads,
+			COUNT(*) FILTER (WHERE outcome <> ?) AS failed_attempts,
+			COUNT(DISTINCT ip) FILTER (WHERE outcome = ?) AS unique_visitors,
+			MIN(occurred_at) FILTER (WHERE outcome = ?) AS first_access_at,
+			MAX(occurred_at) FILTER (WHERE outcome = ?) AS last_access_at`,
+			success, string(share.ActionView),
+			success, string(share.ActionDownload),
+			success, string(share.ActionUpload),
+			success, success, success, success).
+		Where("share_id = ?", shareID).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &share.AccessStats{
+		TotalEvents:    row.TotalEvents,
+		Views:          row.Views,
+		Downloads:      row.Downloads,
+		Uploads:        row.Uploads,
+		FailedAttempts: row.FailedAttempts,
+		UniqueVisitors: row.UniqueVisitors,
+		FirstAccessAt:  row.FirstAccessAt,
+		LastAccessAt:   row.LastAccessAt,
+	}, nil
+}
+
+// DeleteByShare removes the events of a share
+func (r *ShareAccessEventRepository) DeleteByShare(ctx context.Context, shareID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("share_id = ?", shareID).Delete(&models.ShareAccessEvent{}).Error
+}
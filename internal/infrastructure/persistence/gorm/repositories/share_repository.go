@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -88,13 +89,115 @@ func (r *ShareRepository) GetByRecipient(ctx context.Context, recipientID uuid.U
 	return mapModelsToDomain(models), nil
 }
 
-// Update updates an existing share
+// GetByGroups retrieves all shares shared with any of the groups
+func (r *ShareRepository) GetByGroups(ctx context.Context, groupIDs []uuid.UUID) ([]*share.Share, error) {
+	var models []models.Share
+	result := r.db.WithContext(ctx).Where("group_id IN ?", groupIDs).Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return mapModelsToDomain(models), nil
+}
+
+// Update updates the settings of an existing share
+// Counters and the revocation are only changed atomically by their own methods, so an update made
+// from a stale read cannot lose counts or un-revoke a share that used its last download meanwhile
 func (r *ShareRepository) Update(ctx context.Context, s *share.Share) error {
 	model := mapDomainToModel(s)
-	result := r.db.WithContext(ctx).Save(model)
+	result := r.db.WithContext(ctx).Omit("upload_count", "download_count", "access_count", "last_access_at", "is_revoked", "recipient_id", "invite_token_hash").Save(model)
 	return result.Error
 }
 
+// Revoke revokes a share
+func (r *ShareRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&models.Share{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"is_revoked": true,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return share.ErrShareNotFound
+	}
+	return nil
+}
+
+// GetByInviteToken retrieves a pending invitation by the hash of its invite token
+func (r *ShareRepository) GetByInviteToken(ctx context.Context, tokenHash string) (*share.Share, error) {
+	var model models.Share
+	result := r.db.WithContext(ctx).
+		Where("invite_token_hash = ? AND recipient_id IS NULL", tokenHash).
+		First(&model)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, share.ErrShareNotFound
+		}
+		return nil, result.Error
+	}
+	return mapModelToDomain(&model), nil
+}
+
+// ClaimInvitation gives a pending invitation its recipient if its invite token was not used meanwhile
+func (r *ShareRepository) ClaimInvitation(ctx context.Context, id uuid.UUID, tokenHash string, recipientID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Share{}).
+		Where("id = ? AND invite_token_hash = ? AND recipient_id IS NULL", id, tokenHash).
+		UpdateColumns(map[string]interface{}{
+			"recipient_id":      recipientID,
+			"invite_token_hash": "",
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// IncrementUploadCount counts an upload if the share has not reached its file limit
+func (r *ShareRepository) IncrementUploadCount(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Share{}).
+		Where("id = ? AND (max_files = 0 OR upload_count < max_files)", id).
+		UpdateColumn("upload_count", gorm.Expr("upload_count + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DecrementUploadCount gives back an upload counted by IncrementUploadCount
+func (r *ShareRepository) DecrementUploadCount(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.Share{}).
+		Where("id = ? AND upload_count > 0", id).
+		UpdateColumn("upload_count", gorm.Expr("upload_count - 1")).Error
+}
+
+// IncrementDownloadCount counts a download if the share is not revoked and has downloads left
+// The share is revoked by the same update when it uses its last download
+func (r *ShareRepository) IncrementDownloadCount(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Share{}).
+		Where("id = ? AND is_revoked = ? AND (max_downloads = 0 OR download_count < max_downloads)", id, false).
+		UpdateColumns(map[string]interface{}{
+			"download_count": gorm.Expr("download_count + 1"),
+			"is_revoked":     gorm.Expr("max_downloads > 0 AND download_count + 1 >= max_downloads"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RecordAccess increments the access count of a share and sets its last access time
+func (r *ShareRepository) RecordAccess(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.Share{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"access_count":   gorm.Expr("access_count + 1"),
+			"last_access_at": time.Now(),
+		}).Error
+}
+
 // Delete removes a share
 func (r *ShareRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	result := r.db.WithContext(ctx).Delete(&models.Share{}, "id = ?", id)
@@ -117,6 +220,7 @@ func mapDomainToModel(s *share.Share) *models.Share {
 		Type:         string(s.Type),
 		Permission:   string(s.Permission),
 		RecipientID:  s.RecipientID,
+		GroupID:      s.GroupID,
 		Token:        s.Token,
 		Password:     s.Password,
 		ExpiresAt:    s.ExpiresAt,
@@ -125,6 +229,17 @@ func mapDomainToModel(s *share.Share) *models.Share {
 		AccessCount:  s.AccessCount,
 		LastAccessAt: s.LastAccessAt,
 		IsRevoked:    s.IsRevoked,
+		UploadOnly:   s.UploadOnly,
+		MaxFiles:     s.MaxFiles,
+		MaxFileSize:  s.MaxFileSize,
+		UploadCount:  s.UploadCount,
+
+		MaxDownloads:     s.MaxDownloads,
+		DownloadCount:    s.DownloadCount,
+		BurnAfterReading: s.BurnAfterReading,
+
+		RecipientEmail:  s.RecipientEmail,
+		InviteTokenHash: s.InviteTokenHash,
 	}
 }
 
@@ -137,6 +252,7 @@ func mapModelToDomain(m *models.Share) *share.Share {
 		Type:         share.ShareType(m.Type),
 		Permission:   share.SharePermission(m.Permission),
 		RecipientID:  m.RecipientID,
+		GroupID:      m.GroupID,
 		Token:        m.Token,
 		Password:     m.Password,
 		ExpiresAt:    m.ExpiresAt,
@@ -145,6 +261,17 @@ func mapModelToDomain(m *models.Share) *share.Share {
 		AccessCount:  m.AccessCount,
 		LastAccessAt: m.LastAccessAt,
 		IsRevoked:    m.IsRevoked,
+		UploadOnly:   m.UploadOnly,
+		MaxFiles:     m.MaxFiles,
+		MaxFileSize:  m.MaxFileSize,
+		UploadCount:  m.UploadCount,
+
+		MaxDownloads:     m.MaxDownloads,
+		DownloadCount:    m.DownloadCount,
+		BurnAfterReading: m.BurnAfterReading,
+
+		RecipientEmail:  m.RecipientEmail,
+		InviteTokenHash: m.InviteTokenHash,
 	}
 }
 
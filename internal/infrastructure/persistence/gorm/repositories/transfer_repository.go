@@ -0,0 +1,290 @@
+package repositories
+
+import (
+	"errors"
+
+	"easy-storage/internal/domain/transfer"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// transferBatchSize bounds the number of IDs sent in a single update, databases limit query parameters
+const transferBatchSize = 1000
+
+// GormTransferRepository implements the transfer.Repository interface using GORM
+type GormTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTransferRepository creates a new ownership transfer repository
+func NewGormTransferRepository(db *gorm.DB) transfer.Repository {
+	return &GormTransferRepository{db: db}
+}
+
+// Save stores a new transfer
+func (r *GormTransferRepository) Save(t *transfer.Transfer) error {
+	model := mapTransferToModel(t)
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+
+	t.ID = model.ID
+	return nil
+}
+
+// FindByID finds a transfer by ID
+func (r *GormTransferRepository) FindByID(id string) (*transfer.Transfer, error) {
+	var model models.OwnershipTransfer
+	if err := r.db.First(&model, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, transfer.ErrTransferNotFound
+		}
+		return nil, err
+	}
+
+	return mapTransferModelToDomain(&model), nil
+}
+
+// FindIncoming finds the transfers sent to a user, newest first
+func (r *GormTransferRepository) FindIncoming(userID string) ([]*transfer.Transfer, error) {
+	return r.find("to_user_id = ?", userID)
+}
+
+// FindOutgoing finds the transfers sent by a user, newest first
+func (r *GormTransferRepository) FindOutgoing(userID string) ([]*transfer.Transfer, error) {
+	return r.find("from_user_id = ?", userID)
+}
+
+// HasPending checks if a resource has a pending transfer
+func (r *GormTransferRepository) HasPending(resourceID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.OwnershipTransfer{}).
+		Where("resource_id = ? AND status = ?", resourceID, string(transfer.StatusPending)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Respond stores the answer to a transfer if it is still pending
+func (r *GormTransferRepository) Respond(t *transfer.Transfer) error {
+	return respondTransfer(r.db, t)
+}
+
+// Complete stores an accepted transfer and moves the resource tree to the recipient in a single transaction
+// The tree and its storage are read in the transaction, so the quota charged is what changes hands
+func (r *GormTransferRepository) Complete(t *transfer.Transfer, rootName string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := respondTransfer(tx, t); err != nil {
+			return err
+		}
+
+		folderIDs, fileIDs, err := selectTransferTree(tx, t)
+		if err != nil {
+			return err
+		}
+
+		size, err := transferStorageUsage(tx, fileIDs)
+		if err != nil {
+			return err
+		}
+		if err := moveStorageUsed(tx, t.FromUserID, t.ToUserID, size); err != nil {
+			return err
+		}
+
+		// Trashed resources of the tree change hands too, the recipient finds them in their trash
+		for _, ids := range batchIDs(folderIDs) {
+			if err := tx.Unscoped().Model(&models.Folder{}).
+				Where("id IN ? AND user_id = ?", ids, t.FromUserID).
+				Update("user_id", t.ToUserID).Error; err != nil {
+				return err
+			}
+		}
+		for _, ids := range batchIDs(fileIDs) {
+			if err := tx.Unscoped().Model(&models.File{}).
+				Where("id IN ? AND user_id = ?", ids, t.FromUserID).
+				Update("user_id", t.ToUserID).Error; err != nil {
+				return err
+			}
+		}
+
+		// The transferred resource leaves the sender's folders for the recipient's root folder
+		if t.ResourceType == "folder" {
+			if err := tx.Model(&models.Folder{}).
+				Where("id = ? AND user_id = ?", t.ResourceID, t.ToUserID).
+				Updates(map[string]interface{}{"parent_id": nil, "name": rootName}).Error; err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Model(&models.File{}).
+				Where("id = ? AND user_id = ?", t.ResourceID, t.ToUserID).
+				Updates(map[string]interface{}{"folder_id": nil, "name": rootName}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Existing shares keep working under their new owner
+		for _, ids := range batchIDs(append(append([]string{}, folderIDs...), fileIDs...)) {
+			if err := tx.Model(&models.Share{}).
+				Where("resource_id IN ? AND owner_id = ?", ids, t.FromUserID).
+				Update("owner_id", t.ToUserID).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// find finds the transfers matching a condition, newest first
+func (r *GormTransferRepository) find(query string, args ...interface{}) ([]*transfer.Transfer, error) {
+	var transferModels []models.OwnershipTransfer
+	if err := r.db.Where(query, args...).
+		Order("created_at DESC").
+		Find(&transferModels).Error; err != nil {
+		return nil, err
+	}
+
+	transfers := make([]*transfer.Transfer, 0, len(transferModels))
+	for i := range transferModels {
+		transfers = append(transfers, mapTransferModelToDomain(&transferModels[i]))
+	}
+
+	return transfers, nil
+}
+
+// respondTransfer stores the answer to a transfer, the update only applies while it is pending
+func respondTransfer(db *gorm.DB, t *transfer.Transfer) error {
+	result := db.Model(&models.OwnershipTransfer{}).
+		Where("id = ? AND status = ?", t.ID, string(transfer.StatusPending)).
+		Updates(map[string]interface{}{
+			"status":       string(t.Status),
+			"responded_at": t.RespondedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return transfer.ErrTransferNotPending
+	}
+	return nil
+}
+
+// selectTransferTree lists the folders and files a transfer moves, trashed ones included
+// The transferred resource is locked, it must still belong to the sender and be out of the trash
+func selectTransferTree(tx *gorm.DB, t *transfer.Transfer) ([]string, []string, error) {
+	locked := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Session(&gorm.Session{})
+
+	if t.ResourceType != "folder" {
+		var root models.File
+		if err := locked.Where("id = ? AND user_id = ?", t.ResourceID, t.FromUserID).First(&root).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, transfer.ErrResourceNotFound
+			}
+			return nil, nil, err
+		}
+		return nil, []string{root.ID}, nil
+	}
+
+	var root models.Folder
+	if err := locked.Where("id = ? AND user_id = ?", t.ResourceID, t.FromUserID).First(&root).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, transfer.ErrResourceNotFound
+		}
+		return nil, nil, err
+	}
+
+	// Walk the tree level by level, a folder already seen is not walked twice
+	folderIDs := []string{root.ID}
+	seen := map[string]bool{root.ID: true}
+	for level := []string{root.ID}; len(level) > 0; {
+		var next []string
+		for _, ids := range batchIDs(level) {
+			var children []string
+			if err := locked.Unscoped().Model(&models.Folder{}).
+				Where("parent_id IN ? AND user_id = ?", ids, t.FromUserID).
+				Pluck("id", &children).Error; err != nil {
+				return nil, nil, err
+			}
+			for _, id := range children {
+				if !seen[id] {
+					seen[id] = true
+					next = append(next, id)
+				}
+			}
+		}
+		folderIDs = append(folderIDs, next...)
+		level = next
+	}
+
+	var fileIDs []string
+	for _, ids := range batchIDs(folderIDs) {
+		var files []string
+		if err := locked.Unscoped().Model(&models.File{}).
+			Where("folder_id IN ? AND user_id = ?", ids, t.FromUserID).
+			Pluck("id", &files).Error; err != nil {
+			return nil, nil, err
+		}
+		fileIDs = append(fileIDs, files...)
+	}
+
+	return folderIDs, fileIDs, nil
+}
+
+// transferStorageUsage sums the storage files take in their owner's quota, every version included
+// Files created before versioning may have no version records, their size is used instead
+func transferStorageUsage(tx *gorm.DB, fileIDs []string) (int64, error) {
+	var total int64
+	for _, ids := range batchIDs(fileIDs) {
+		var size int64
+		if err := tx.Unscoped().Model(&models.File{}).
+			Where("id IN ?", ids).
+			Select("COALESCE(SUM(COALESCE((SELECT SUM(file_versions.size) FROM file_versions WHERE file_versions.file_id = files.id), files.size)), 0)").
+			Scan(&size).Error; err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// batchIDs splits IDs into batches of at most transferBatchSize
+func batchIDs(ids []string) [][]string {
+	var batches [][]string
+	for len(ids) > transferBatchSize {
+		batches = append(batches, ids[:transferBatchSize])
+		ids = ids[transferBatchSize:]
+	}
+	if len(ids) > 0 {
+		batches = append(batches, ids)
+	}
+	return batches
+}
+
+// mapTransferToModel converts a domain transfer into a transfer model
+func mapTransferToModel(t *transfer.Transfer) *models.OwnershipTransfer {
+	return &models.OwnershipTransfer{
+		ID:           t.ID,
+		ResourceID:   t.ResourceID,
+		ResourceType: t.ResourceType,
+		FromUserID:   t.FromUserID,
+		ToUserID:     t.ToUserID,
+		Status:       string(t.Status),
+		CreatedAt:    t.CreatedAt,
+		RespondedAt:  t.RespondedAt,
+	}
+}
+
+// mapTransferModelToDomain converts a transfer model into a domain transfer
+func mapTransferModelToDomain(m *models.OwnershipTransfer) *transfer.Transfer {
+	return &transfer.Transfer{
+		ID:           m.ID,
+		ResourceID:   m.ResourceID,
+		ResourceType: m.ResourceType,
+		FromUserID:   m.FromUserID,
+		ToUserID:     m.ToUserID,
+		Status:       transfer.Status(m.Status),
+		CreatedAt:    m.CreatedAt,
+		RespondedAt:  m.RespondedAt,
+	}
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"easy-storage/internal/domain/common"
+	"easy-storage/internal/domain/upload"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+// GormUploadRepository implements the upload.Repository interface using GORM
+type GormUploadRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUploadRepository creates a new upload repository
+func NewGormUploadRepository(db *gorm.DB) upload.Repository {
+	return &GormUploadRepository{db: db}
+}
+
+// Save creates or updates an upload in the database
+func (r *GormUploadRepository) Save(u *upload.Upload) error {
+	parts, err := json.Marshal(u.Parts)
+	if err != nil {
+		return err
+	}
+
+	uploadModel := &models.Upload{
+		ID:              u.ID,
+		UserID:          u.UserID,
+		FolderID:        nullableString(u.FolderID),
+		Filename:        u.Filename,
+		ContentType:     u.ContentType,
+		Size:            u.Size,
+		Offset:          u.Offset,
+		StoragePath:     u.StoragePath,
+		StorageUploadID: u.StorageUploadID,
+		Parts:           string(parts),
+		PendingPath:     u.PendingPath,
+		PendingSize:     u.PendingSize,
+		FileID:          nullableString(u.FileID),
+		ExpiresAt:       u.ExpiresAt,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	}
+
+	if err := r.db.Save(uploadModel).Error; err != nil {
+		return err
+	}
+
+	u.ID = uploadModel.ID
+	return nil
+}
+
+// FindByID finds an upload by ID
+func (r *GormUploadRepository) FindByID(id string) (*upload.Upload, error) {
+	var uploadModel models.Upload
+	if err := r.db.First(&uploadModel, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, upload.ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	return mapUploadModelToDomain(&uploadModel)
+}
+
+// FindExpired finds uploads, finished or not, that expired before the given time
+func (r *GormUploadRepository) FindExpired(before time.Time) ([]*upload.Upload, error) {
+	var uploadModels []models.Upload
+	if err := r.db.Where("expires_at < ?", before).Find(&uploadModels).Error; err != nil {
+		return nil, err
+	}
+
+	uploads := make([]*upload.Upload, 0, len(uploadModels))
+	for i := range uploadModels {
+		u, err := mapUploadModelToDomain(&uploadModels[i])
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, u)
+	}
+
+	return uploads, nil
+}
+
+// Delete deletes an upload
+func (r *GormUploadRepository) Delete(id string) error {
+	return r.db.Delete(&models.Upload{}, "id = ?", id).Error
+}
+
+// mapUploadModelToDomain converts an upload model into a domain upload
+func mapUploadModelToDomain(m *models.Upload) (*upload.Upload, error) {
+	var parts []common.CompletedPart
+	if m.Parts != "" {
+		if err := json.Unmarshal([]byte(m.Parts), &parts); err != nil {
+			return nil, err
+		}
+	}
+
+	return &upload.Upload{
+		ID:              m.ID,
+		UserID:          m.UserID,
+		FolderID:        stringValue(m.FolderID),
+		Filename:        m.Filename,
+		ContentType:     m.ContentType,
+		Size:            m.Size,
+		Offset:          m.Offset,
+		StoragePath:     m.StoragePath,
+		StorageUploadID: m.StorageUploadID,
+		Parts:           parts,
+		PendingPath:     m.PendingPath,
+		PendingSize:     m.PendingSize,
+		FileID:          stringValue(m.FileID),
+		ExpiresAt:       m.ExpiresAt,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"easy-storage/internal/domain/upload"
+	"easy-storage/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+// GormReservationRepository implements the upload.ReservationRepository interface using GORM
+type GormReservationRepository struct {
+	db *gorm.DB
+}
+
+// NewGormReservationRepository creates a new upload reservation repository
+func NewGormReservationRepository(db *gorm.DB) upload.ReservationRepository {
+	return &GormReservationRepository{db: db}
+}
+
+// Save creates or updates a reservation in the database
+func (r *GormReservationRepository) Save(reservation *upload.Reservation) error {
+	reservationModel := &models.UploadReservation{
+		ID:          reservation.ID,
+		UserID:      reservation.UserID,
+		FolderID:    nullableString(reservation.FolderID),
+		Filename:    reservation.Filename,
+		ContentType: reservation.ContentType,
+		Size:        reservation.Size,
+		StoragePath: reservation.StoragePath,
+		ExpiresAt:   reservation.ExpiresAt,
+		CreatedAt:   reservation.CreatedAt,
+	}
+
+	if err := r.db.Save(reservationModel).Error; err != nil {
+		return err
+	}
+
+	reservation.ID = reservationModel.ID
+	return nil
+}
+
+// FindByID finds a reservation by ID
+func (r *GormReservationRepository) FindByID(id string) (*upload.Reservation, error) {
+	var reservationModel models.UploadReservation
+	if err := r.db.First(&reservationModel, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, upload.ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	return mapReservationModelToDomain(&reservationModel), nil
+}
+
+// FindExpired finds reservations that expired before the given time
+func (r *GormReservationRepository) FindExpired(before time.Time) ([]*upload.Reservation, error) {
+	var reservationModels []models.UploadReservation
+	if err := r.db.Where("expires_at < ?", before).Find(&reservationModels).Error; err != nil {
+		return nil, err
+	}
+
+	reservations := make([]*upload.Reservation, 0, len(reservationModels))
+	for i := range reservationModels {
+		reservations = append(reservations, mapReservationModelToDomain(&reservationModels[i]))
+	}
+
+	return reservations, nil
+}
+
+// Delete deletes a reservation
+func (r *GormReservationRepository) Delete(id string) error {
+	return r.db.Delete(&models.UploadReservation{}, "id = ?", id).Error
+}
+
+// mapReservationModelToDomain converts a reservation model into a domain reservation
+func mapReservationModelToDomain(m *models.UploadReservation) *upload.Reservation {
+	return &upload.Reservation{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		FolderID:    stringValue(m.FolderID),
+		Filename:    m.Filename,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		StoragePath: m.StoragePath,
+		ExpiresAt:   m.ExpiresAt,
+		CreatedAt:   m.CreatedAt,
+	}
+}
@@ -121,3 +121,28 @@ func (r *GormUserRepository) DecrementStorageUsed(userID string, size int64) err
 		Where("id = ?", userID).
 		Update("storage_used", gorm.Expr("GREATEST(storage_used - ?, 0)", size)).Error
 }
+
+// MoveStorageUsed moves storage used from one user to another in a single transaction
+func (r *GormUserRepository) MoveStorageUsed(fromUserID, toUserID string, size int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return moveStorageUsed(tx, fromUserID, toUserID, size)
+	})
+}
+
+// moveStorageUsed moves storage used from one user to another, meant to run in a transaction
+func moveStorageUsed(tx *gorm.DB, fromUserID, toUserID string, size int64) error {
+	// Only charge the recipient if the size fits in their quota
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND storage_used + ? <= storage_quota", toUserID, size).
+		Update("storage_used", gorm.Expr("storage_used + ?", size))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrStorageQuotaExceeded
+	}
+
+	return tx.Model(&models.User{}).
+		Where("id = ?", fromUserID).
+		Update("storage_used", gorm.Expr("GREATEST(storage_used - ?, 0)", size)).Error
+}
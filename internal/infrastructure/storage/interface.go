@@ -3,12 +3,16 @@ package storage
 
 import (
 	"io"
+	"time"
+
+	"easy-storage/internal/domain/common"
 )
 
 // Provider defines the interface for file storage
 type Provider interface {
 	// Upload uploads a file to storage and returns its path
-	Upload(filename string, contentType string, file io.Reader) (string, error)
+	// size is the declared length of file, -1 when it is unknown
+	Upload(filename string, contentType string, size int64, file io.Reader) (string, error)
 
 	// Download downloads a file from storage
 	Download(path string) (io.ReadCloser, error)
@@ -19,4 +23,29 @@ type Provider interface {
 	// GetSignedURL generates a presigned URL for downloading a file
 	// expiryTime is the duration in seconds for which the URL will be valid
 	GetSignedURL(path string, expiryTime int64) (string, error)
+
+	// GetSignedUploadURL generates a presigned URL for uploading a new object directly to storage
+	// It returns the path of the object and the URL, which only accepts the given content type and size
+	GetSignedUploadURL(filename string, contentType string, size int64, expiryTime int64) (string, string, error)
+
+	// Copy duplicates a stored object on the storage side and returns the path of the copy
+	Copy(path string) (string, error)
+
+	// Stat returns information about a stored object
+	Stat(path string) (common.ObjectInfo, error)
+
+	// CreateMultipartUpload starts a multipart upload and returns the object path and upload ID
+	CreateMultipartUpload(filename string, contentType string) (string, string, error)
+
+	// UploadPart uploads a single part of size bytes read from body
+	UploadPart(path string, uploadID string, partNumber int32, body io.Reader, size int64) (common.CompletedPart, error)
+
+	// CompleteMultipartUpload assembles the uploaded parts into the final object
+	CompleteMultipartUpload(path string, uploadID string, parts []common.CompletedPart) error
+
+	// AbortMultipartUpload discards a multipart upload and all of its parts
+	AbortMultipartUpload(path string, uploadID string) error
+
+	// AbortStaleMultipartUploads discards multipart uploads started more than olderThan ago
+	AbortStaleMultipartUploads(olderThan time.Duration) error
 }
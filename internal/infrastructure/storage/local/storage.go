@@ -0,0 +1,308 @@
+package local
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"easy-storage/internal/config"
+	"easy-storage/internal/domain/common"
+
+	"github.com/google/uuid"
+)
+
+// RoutePrefix is the API path under which signed local storage URLs are served
+const RoutePrefix = "/storage/local/"
+
+const (
+	dirPermissions  = 0o755
+	filePermissions = 0o644
+	tempPattern     = ".upload-*"
+)
+
+var (
+	// ErrInvalidPath is returned when a storage path escapes the storage root
+	ErrInvalidPath = errors.New("invalid storage path")
+
+	// ErrInvalidSignature is returned when a signed URL signature does not match
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrURLExpired is returned when a signed URL is used after its expiry
+	ErrURLExpired = errors.New("signed URL has expired")
+
+	// ErrSizeMismatch is returned when an upload body is shorter than its signed size
+	ErrSizeMismatch = errors.New("upload size does not match")
+)
+
+// LocalProvider implements the storage interface on the local filesystem
+type LocalProvider struct {
+	rootDir    string
+	signingKey []byte
+	publicURL  string
+}
+
+// NewLocalProvider creates a new local filesystem storage provider
+func NewLocalProvider(cfg *config.StorageConfig) (*LocalProvider, error) {
+	rootDir, err := filepath.Abs(cfg.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage path: %w", err)
+	}
+
+	if err := os.MkdirAll(rootDir, dirPermissions); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	signingKey := []byte(cfg.SigningKey)
+	if len(signingKey) == 0 {
+		// Without a configured key, signed URLs stop working after a restart
+		log.Printf("STORAGE_SIGNING_KEY is not set, generating an ephemeral signing key")
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("Initializing local storage provider in %s", rootDir)
+
+	return &LocalProvider{
+		rootDir:    rootDir,
+		signingKey: signingKey,
+		publicURL:  strings.TrimSuffix(cfg.PublicURL, "/"),
+	}, nil
+}
+
+// Upload writes a file to a temporary file and atomically moves it into place
+func (p *LocalProvider) Upload(filename string, contentType string, size int64, file io.Reader) (string, error) {
+	storagePath := newStoragePath(filename)
+
+	fullPath, err := p.resolve(storagePath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := writeAtomically(fullPath, file); err != nil {
+		return "", err
+	}
+
+	return storagePath, nil
+}
+
+// Download opens a file from local storage
+func (p *LocalProvider) Download(storagePath string) (io.ReadCloser, error) {
+	fullPath, err := p.resolve(storagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(fullPath)
+}
+
+// Delete removes a file from local storage
+func (p *LocalProvider) Delete(storagePath string) error {
+	fullPath, err := p.resolve(storagePath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// GetSignedURL generates an HMAC-signed URL served by the API itself
+// expiryTime is the duration in seconds for which the URL will be valid
+func (p *LocalProvider) GetSignedURL(storagePath string, expiryTime int64) (string, error) {
+	if _, err := p.resolve(storagePath); err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(time.Duration(expiryTime) * time.Second).Unix()
+	signature := p.sign("GET", storagePath, strconv.FormatInt(expires, 10))
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signature)
+
+	return p.publicURL + RoutePrefix + storagePath + "?" + query.Encode(), nil
+}
+
+// GetSignedUploadURL generates an HMAC-signed URL accepting a PUT of a new object
+// The content type and length are part of the signature, like S3 presigned uploads
+func (p *LocalProvider) GetSignedUploadURL(filename string, contentType string, size int64, expiryTime int64) (string, string, error) {
+	storagePath := newStoragePath(filename)
+
+	expires := time.Now().Add(time.Duration(expiryTime) * time.Second).Unix()
+	signature := p.sign("PUT", storagePath, contentType, strconv.FormatInt(size, 10), strconv.FormatInt(expires, 10))
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signature)
+
+	return storagePath, p.publicURL + RoutePrefix + storagePath + "?" + query.Encode(), nil
+}
+
+// Stat returns the size of a file in local storage
+// The content type is not recorded on disk and is left empty
+func (p *LocalProvider) Stat(storagePath string) (common.ObjectInfo, error) {
+	fullPath, err := p.resolve(storagePath)
+	if err != nil {
+		return common.ObjectInfo{}, err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return common.ObjectInfo{}, common.ErrObjectNotFound
+		}
+		return common.ObjectInfo{}, err
+	}
+
+	return common.ObjectInfo{Size: info.Size()}, nil
+}
+
+// Copy duplicates a file inside the storage directory
+func (p *LocalProvider) Copy(storagePath string) (string, error) {
+	source, err := p.Download(storagePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", common.ErrObjectNotFound
+		}
+		return "", err
+	}
+	defer source.Close()
+
+	return p.Upload(storagePath, "", -1, source)
+}
+
+// Put writes exactly size bytes to a storage path received through a signed upload URL
+func (p *LocalProvider) Put(storagePath string, content io.Reader, size int64) error {
+	fullPath, err := p.resolve(storagePath)
+	if err != nil {
+		return err
+	}
+
+	return writeAtomically(fullPath, &sizedReader{reader: io.LimitReader(content, size), remaining: size})
+}
+
+// VerifySignature checks that a signed download URL is authentic and has not expired
+func (p *LocalProvider) VerifySignature(method, storagePath string, expires int64, signature string) error {
+	expected := p.sign(method, storagePath, strconv.FormatInt(expires, 10))
+	return checkSignature(expected, signature, expires)
+}
+
+// VerifyUploadSignature checks that a signed upload URL is authentic, has not expired
+// and was issued for the given content type and size
+func (p *LocalProvider) VerifyUploadSignature(storagePath, contentType string, size, expires int64, signature string) error {
+	expected := p.sign("PUT", storagePath, contentType, strconv.FormatInt(size, 10), strconv.FormatInt(expires, 10))
+	return checkSignature(expected, signature, expires)
+}
+
+// sign computes the hex-encoded HMAC of the newline separated request fields
+func (p *LocalProvider) sign(fields ...string) string {
+	mac := hmac.New(sha256.New, p.signingKey)
+	mac.Write([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkSignature compares signatures in constant time and checks the expiry
+func checkSignature(expected, signature string, expires int64) error {
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	if time.Now().Unix() > expires {
+		return ErrURLExpired
+	}
+
+	return nil
+}
+
+// resolve converts a storage path into an absolute path inside the root directory
+func (p *LocalProvider) resolve(storagePath string) (string, error) {
+	cleaned := path.Clean("/" + storagePath)
+	if cleaned == "/" || cleaned != "/"+storagePath {
+		return "", ErrInvalidPath
+	}
+
+	return filepath.Join(p.rootDir, filepath.FromSlash(cleaned)), nil
+}
+
+// newStoragePath generates a unique path sharded by the first bytes of its UUID
+// e.g. "3f/a2/3fa2c1d4-....pdf", which keeps directory sizes bounded
+func newStoragePath(filename string) string {
+	id := uuid.New().String()
+	return id[0:2] + "/" + id[2:4] + "/" + id + filepath.Ext(filename)
+}
+
+// writeAtomically streams content to a temporary file next to the destination
+// and renames it into place, so readers never observe a partially written file
+func writeAtomically(fullPath string, content io.Reader) error {
+	dir := filepath.Dir(fullPath)
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(dir, tempPattern)
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+
+	if _, err := io.Copy(tempFile, content); err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := os.Chmod(tempPath, filePermissions); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := os.Rename(tempPath, fullPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	return nil
+}
+
+// sizedReader fails with ErrSizeMismatch if the content ends before the expected size
+type sizedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+// Read reads from the underlying reader and tracks the bytes still expected
+func (r *sizedReader) Read(buf []byte) (int, error) {
+	n, err := r.reader.Read(buf)
+	r.remaining -= int64(n)
+	if err == io.EOF && r.remaining > 0 {
+		return n, ErrSizeMismatch
+	}
+	return n, err
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"easy-storage/internal/domain/common"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUploadNotFound is returned when a multipart upload ID is unknown
+	ErrUploadNotFound = errors.New("multipart upload not found")
+
+	// ErrPartNotFound is returned when completing a multipart upload with a part that was never uploaded
+	ErrPartNotFound = errors.New("multipart upload part not found")
+)
+
+// multipartUpload holds the parts of an in-progress multipart upload
+type multipartUpload struct {
+	contentType string
+	parts       map[int32][]byte
+	createdAt   time.Time
+}
+
+// CreateMultipartUpload starts a multipart upload for a new object
+func (p *MemoryProvider) CreateMultipartUpload(filename string, contentType string) (string, string, error) {
+	uniquePath := time.Now().Format("2006/01/02/") + uuid.New().String() + filepath.Ext(filename)
+	uploadID := uuid.New().String()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.uploads[uploadID] = &multipartUpload{
+		contentType: contentType,
+		parts:       make(map[int32][]byte),
+		createdAt:   time.Now(),
+	}
+	return uniquePath, uploadID, nil
+}
+
+// UploadPart stores a single part of a multipart upload
+func (p *MemoryProvider) UploadPart(path string, uploadID string, partNumber int32, body io.Reader, size int64) (common.CompletedPart, error) {
+	if err := p.checkUploadFault(); err != nil {
+		return common.CompletedPart{}, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, size))
+	if err != nil {
+		return common.CompletedPart{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	upload, exists := p.uploads[uploadID]
+	if !exists {
+		return common.CompletedPart{}, ErrUploadNotFound
+	}
+	upload.parts[partNumber] = data
+
+	sum := md5.Sum(data)
+	return common.CompletedPart{
+		PartNumber: partNumber,
+		ETag:       hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the final object
+func (p *MemoryProvider) CompleteMultipartUpload(path string, uploadID string, parts []common.CompletedPart) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	upload, exists := p.uploads[uploadID]
+	if !exists {
+		return ErrUploadNotFound
+	}
+
+	sorted := append([]common.CompletedPart(nil), parts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PartNumber < sorted[j].PartNumber })
+
+	var buffer bytes.Buffer
+	for _, part := range sorted {
+		data, exists := upload.parts[part.PartNumber]
+		if !exists {
+			return ErrPartNotFound
+		}
+		buffer.Write(data)
+	}
+
+	p.objects[path] = object{data: buffer.Bytes(), contentType: upload.contentType}
+	delete(p.uploads, uploadID)
+	return nil
+}
+
+// AbortMultipartUpload discards a multipart upload and all of its parts
+func (p *MemoryProvider) AbortMultipartUpload(path string, uploadID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.uploads, uploadID)
+	return nil
+}
+
+// AbortStaleMultipartUploads discards multipart uploads started more than olderThan ago
+func (p *MemoryProvider) AbortStaleMultipartUploads(olderThan time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	for uploadID, upload := range p.uploads {
+		if upload.createdAt.Before(cutoff) {
+			delete(p.uploads, uploadID)
+		}
+	}
+
+	return nil
+}
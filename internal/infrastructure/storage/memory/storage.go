@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"easy-storage/internal/domain/common"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrObjectNotFound is returned when a path does not exist in memory
+	ErrObjectNotFound = common.ErrObjectNotFound
+
+	// ErrInjectedFailure is returned by operations failed on purpose through fault injection
+	ErrInjectedFailure = errors.New("injected storage failure")
+)
+
+// object is a stored file and its metadata
+type object struct {
+	data        []byte
+	contentType string
+}
+
+// MemoryProvider implements the storage interface in memory
+// It is safe for concurrent use and intended for tests, its signed URLs can't be fetched by clients
+type MemoryProvider struct {
+	mu          sync.RWMutex
+	objects     map[string]object
+	uploads     map[string]*multipartUpload
+	uploadCount int
+	failUpload  int
+	readDelay   time.Duration
+}
+
+// NewMemoryProvider creates a new in-memory storage provider
+func NewMemoryProvider() *MemoryProvider {
+	return &MemoryProvider{
+		objects: make(map[string]object),
+		uploads: make(map[string]*multipartUpload),
+	}
+}
+
+// FailNthUpload makes the nth upload from now fail with ErrInjectedFailure
+// Passing 0 disables upload fault injection
+func (p *MemoryProvider) FailNthUpload(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.uploadCount = 0
+	p.failUpload = n
+}
+
+// SetReadDelay delays every read from a downloaded object by the given duration
+func (p *MemoryProvider) SetReadDelay(delay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.readDelay = delay
+}
+
+// Upload stores a file in memory using the same date-prefixed paths as S3Provider
+func (p *MemoryProvider) Upload(filename string, contentType string, size int64, file io.Reader) (string, error) {
+	if err := p.checkUploadFault(); err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	// Generate a unique file path to avoid collisions
+	uniquePath := time.Now().Format("2006/01/02/") + uuid.New().String() + filepath.Ext(filename)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.objects[uniquePath] = object{data: data, contentType: contentType}
+	return uniquePath, nil
+}
+
+// Download returns a reader over a copy-free view of the stored object
+func (p *MemoryProvider) Download(path string) (io.ReadCloser, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	obj, exists := p.objects[path]
+	if !exists {
+		return nil, ErrObjectNotFound
+	}
+
+	// Stored slices are never mutated, so readers can share them safely
+	reader := io.Reader(bytes.NewReader(obj.data))
+	if p.readDelay > 0 {
+		reader = &slowReader{reader: reader, delay: p.readDelay}
+	}
+
+	return io.NopCloser(reader), nil
+}
+
+// Delete removes a file from memory
+func (p *MemoryProvider) Delete(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.objects, path)
+	return nil
+}
+
+// GetSignedURL generates a fake signed URL for a stored object, tests read the object with Download
+// expiryTime is the duration in seconds for which the URL will be valid
+func (p *MemoryProvider) GetSignedURL(path string, expiryTime int64) (string, error) {
+	if !p.Exists(path) {
+		return "", ErrObjectNotFound
+	}
+
+	expires := time.Now().Add(time.Duration(expiryTime) * time.Second).Unix()
+	return fmt.Sprintf("memory://%s?expires=%d", path, expires), nil
+}
+
+// GetSignedUploadURL generates a fake signed upload URL for a new object
+// Tests simulate the client upload with PutObject
+func (p *MemoryProvider) GetSignedUploadURL(filename string, contentType string, size int64, expiryTime int64) (string, string, error) {
+	// Generate a unique file path to avoid collisions
+	uniquePath := time.Now().Format("2006/01/02/") + uuid.New().String() + filepath.Ext(filename)
+
+	expires := time.Now().Add(time.Duration(expiryTime) * time.Second).Unix()
+	return uniquePath, fmt.Sprintf("memory://%s?method=PUT&expires=%d", uniquePath, expires), nil
+}
+
+// Stat returns the size and content type of a stored object
+func (p *MemoryProvider) Stat(path string) (common.ObjectInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	obj, exists := p.objects[path]
+	if !exists {
+		return common.ObjectInfo{}, ErrObjectNotFound
+	}
+
+	return common.ObjectInfo{Size: int64(len(obj.data)), ContentType: obj.contentType}, nil
+}
+
+// Copy duplicates a stored object under a new path
+func (p *MemoryProvider) Copy(path string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	obj, exists := p.objects[path]
+	if !exists {
+		return "", ErrObjectNotFound
+	}
+
+	// Stored slices are never mutated, so the copy can share them
+	uniquePath := time.Now().Format("2006/01/02/") + uuid.New().String() + filepath.Ext(path)
+	p.objects[uniquePath] = obj
+	return uniquePath, nil
+}
+
+// PutObject stores an object at the given path, as a client using a signed upload URL would
+func (p *MemoryProvider) PutObject(path string, contentType string, data []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.objects[path] = object{data: data, contentType: contentType}
+}
+
+// Exists reports whether an object is stored at the given path
+func (p *MemoryProvider) Exists(path string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, exists := p.objects[path]
+	return exists
+}
+
+// Count returns the number of stored objects
+func (p *MemoryProvider) Count() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return len(p.objects)
+}
+
+// checkUploadFault counts an upload attempt and fails it if it was targeted
+func (p *MemoryProvider) checkUploadFault() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failUpload == 0 {
+		return nil
+	}
+
+	p.uploadCount++
+	if p.uploadCount == p.failUpload {
+		return ErrInjectedFailure
+	}
+	return nil
+}
+
+// slowReader simulates a slow storage backend by sleeping before every read
+type slowReader struct {
+	reader io.Reader
+	delay  time.Duration
+}
+
+// Read sleeps for the configured delay and then reads from the underlying reader
+func (r *slowReader) Read(buf []byte) (int, error) {
+	time.Sleep(r.delay)
+	return r.reader.Read(buf)
+}
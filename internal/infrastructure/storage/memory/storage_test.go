@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"easy-storage/internal/domain/common"
+)
+
+func TestUploadDownloadRoundTrip(t *testing.T) {
+	p := NewMemoryProvider()
+
+	path, err := p.Upload("report.pdf", "application/pdf", 7, strings.NewReader("content"))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if !regexp.MustCompile(`^\d{4}/\d{2}/\d{2}/[0-9a-f-]{36}\.pdf$`).MatchString(path) {
+		t.Errorf("path %q is not date-prefixed like S3Provider paths", path)
+	}
+
+	reader, err := p.Download(path)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	defer reader.Close()
+	data, _ := io.ReadAll(reader)
+	if string(data) != "content" {
+		t.Errorf("downloaded %q, want %q", data, "content")
+	}
+
+	info, err := p.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size != 7 || info.ContentType != "application/pdf" {
+		t.Errorf("Stat = %+v, want size 7 and application/pdf", info)
+	}
+}
+
+func TestDownloadMissingObject(t *testing.T) {
+	p := NewMemoryProvider()
+
+	if _, err := p.Download("2024/01/01/missing.txt"); err != ErrObjectNotFound {
+		t.Errorf("Download error = %v, want ErrObjectNotFound", err)
+	}
+	if _, err := p.GetSignedURL("2024/01/01/missing.txt", 60); err != ErrObjectNotFound {
+		t.Errorf("GetSignedURL error = %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestFailNthUpload(t *testing.T) {
+	p := NewMemoryProvider()
+	p.FailNthUpload(2)
+
+	if _, err := p.Upload("a.txt", "text/plain", 1, strings.NewReader("a")); err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	if _, err := p.Upload("b.txt", "text/plain", 1, strings.NewReader("b")); err != ErrInjectedFailure {
+		t.Fatalf("second upload error = %v, want ErrInjectedFailure", err)
+	}
+	if _, err := p.Upload("c.txt", "text/plain", 1, strings.NewReader("c")); err != nil {
+		t.Fatalf("third upload: %v", err)
+	}
+	if p.Count() != 2 {
+		t.Errorf("Count = %d, want 2", p.Count())
+	}
+
+	p.FailNthUpload(0)
+	if _, err := p.Upload("d.txt", "text/plain", 1, strings.NewReader("d")); err != nil {
+		t.Errorf("upload after disabling faults: %v", err)
+	}
+}
+
+func TestSetReadDelay(t *testing.T) {
+	p := NewMemoryProvider()
+	path, _ := p.Upload("a.txt", "text/plain", 4, strings.NewReader("slow"))
+
+	p.SetReadDelay(20 * time.Millisecond)
+	reader, err := p.Download(path)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	start := time.Now()
+	data, _ := io.ReadAll(reader)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("read took %v, want at least the 20ms delay", elapsed)
+	}
+	if string(data) != "slow" {
+		t.Errorf("downloaded %q, want %q", data, "slow")
+	}
+}
+
+func TestCopyAndDelete(t *testing.T) {
+	p := NewMemoryProvider()
+	path, _ := p.Upload("a.txt", "text/plain", 8, strings.NewReader("original"))
+
+	copied, err := p.Copy(path)
+	if err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if copied == path {
+		t.Fatal("Copy returned the source path")
+	}
+
+	if err := p.Delete(path); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if p.Exists(path) {
+		t.Error("source still exists after Delete")
+	}
+	if !p.Exists(copied) {
+		t.Error("copy was removed along with the source")
+	}
+}
+
+func TestMultipartUpload(t *testing.T) {
+	p := NewMemoryProvider()
+	path, uploadID, err := p.CreateMultipartUpload("big.bin", "application/octet-stream")
+	if err != nil {
+		t.Fatalf("CreateMultipartUpload: %v", err)
+	}
+
+	// Parts are assembled by number, whatever order they arrive in
+	second, err := p.UploadPart(path, uploadID, 2, strings.NewReader("world"), 5)
+	if err != nil {
+		t.Fatalf("UploadPart 2: %v", err)
+	}
+	first, err := p.UploadPart(path, uploadID, 1, strings.NewReader("hello "), 6)
+	if err != nil {
+		t.Fatalf("UploadPart 1: %v", err)
+	}
+
+	if err := p.CompleteMultipartUpload(path, uploadID, []common.CompletedPart{second, first}); err != nil {
+		t.Fatalf("CompleteMultipartUpload: %v", err)
+	}
+
+	reader, _ := p.Download(path)
+	data, _ := io.ReadAll(reader)
+	if !bytes.Equal(data, []byte("hello world")) {
+		t.Errorf("assembled %q, want %q", data, "hello world")
+	}
+}
+
+func TestCompleteMultipartUploadMissingPart(t *testing.T) {
+	p := NewMemoryProvider()
+	path, uploadID, _ := p.CreateMultipartUpload("big.bin", "application/octet-stream")
+	part, _ := p.UploadPart(path, uploadID, 1, strings.NewReader("hello"), 5)
+
+	parts := []common.CompletedPart{part, {PartNumber: 2, ETag: "missing"}}
+	if err := p.CompleteMultipartUpload(path, uploadID, parts); err != ErrPartNotFound {
+		t.Errorf("CompleteMultipartUpload error = %v, want ErrPartNotFound", err)
+	}
+	if p.Exists(path) {
+		t.Error("object was created despite the missing part")
+	}
+
+	if err := p.CompleteMultipartUpload(path, "unknown", nil); err != ErrUploadNotFound {
+		t.Errorf("CompleteMultipartUpload error = %v, want ErrUploadNotFound", err)
+	}
+}
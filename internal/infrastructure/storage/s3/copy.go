@@ -0,0 +1,109 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"easy-storage/internal/domain/common"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxCopyObjectSize is the largest object S3 copies with a single CopyObject request
+	maxCopyObjectSize = 5 * 1024 * 1024 * 1024
+
+	// copyPartSize is the size of the parts used to copy larger objects
+	copyPartSize = 1024 * 1024 * 1024
+)
+
+// Copy duplicates an object inside the bucket without downloading it
+// Objects larger than 5GB are copied part by part with UploadPartCopy
+func (s *S3Provider) Copy(path string) (string, error) {
+	info, err := s.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Generate a unique file path to avoid collisions
+	uniquePath := time.Now().Format("2006/01/02/") + uuid.New().String() + filepath.Ext(path)
+
+	if info.Size <= maxCopyObjectSize {
+		_, err := s.client.CopyObject(context.TODO(), &s3.CopyObjectInput{
+			Bucket:     aws.String(s.bucketName),
+			Key:        aws.String(uniquePath),
+			CopySource: aws.String(s.copySource(path)),
+		})
+		if err != nil {
+			return "", err
+		}
+		return uniquePath, nil
+	}
+
+	if err := s.copyMultipart(path, uniquePath, info); err != nil {
+		return "", err
+	}
+
+	return uniquePath, nil
+}
+
+// copyMultipart copies a large object into a new multipart upload
+func (s *S3Provider) copyMultipart(source, destination string, info common.ObjectInfo) error {
+	result, err := s.client.CreateMultipartUpload(context.TODO(), &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(destination),
+		ContentType: aws.String(info.ContentType),
+	})
+	if err != nil {
+		return err
+	}
+	uploadID := aws.ToString(result.UploadId)
+
+	var parts []common.CompletedPart
+	for offset, partNumber := int64(0), int32(1); offset < info.Size; offset, partNumber = offset+copyPartSize, partNumber+1 {
+		end := offset + copyPartSize - 1
+		if end >= info.Size {
+			end = info.Size - 1
+		}
+
+		part, err := s.client.UploadPartCopy(context.TODO(), &s3.UploadPartCopyInput{
+			Bucket:          aws.String(s.bucketName),
+			Key:             aws.String(destination),
+			UploadId:        aws.String(uploadID),
+			PartNumber:      aws.Int32(partNumber),
+			CopySource:      aws.String(s.copySource(source)),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+		})
+		if err != nil {
+			_ = s.AbortMultipartUpload(destination, uploadID)
+			return err
+		}
+
+		parts = append(parts, common.CompletedPart{
+			PartNumber: partNumber,
+			ETag:       aws.ToString(part.CopyPartResult.ETag),
+		})
+	}
+
+	if err := s.CompleteMultipartUpload(destination, uploadID, parts); err != nil {
+		_ = s.AbortMultipartUpload(destination, uploadID)
+		return err
+	}
+
+	return nil
+}
+
+// copySource returns the URL-encoded "bucket/key" identifying an object to copy
+func (s *S3Provider) copySource(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.bucketName + "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,83 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"time"
+
+	"easy-storage/internal/domain/common"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+)
+
+// CreateMultipartUpload starts a multipart upload for a new object
+func (s *S3Provider) CreateMultipartUpload(filename string, contentType string) (string, string, error) {
+	// Generate a unique file path to avoid collisions
+	uniquePath := time.Now().Format("2006/01/02/") + uuid.New().String() + filepath.Ext(filename)
+
+	result, err := s.client.CreateMultipartUpload(context.TODO(), &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(uniquePath),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return uniquePath, aws.ToString(result.UploadId), nil
+}
+
+// UploadPart uploads a single part of a multipart upload
+func (s *S3Provider) UploadPart(path string, uploadID string, partNumber int32, body io.Reader, size int64) (common.CompletedPart, error) {
+	result, err := s.client.UploadPart(context.TODO(), &s3.UploadPartInput{
+		Bucket:        aws.String(s.bucketName),
+		Key:           aws.String(path),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return common.CompletedPart{}, err
+	}
+
+	return common.CompletedPart{
+		PartNumber: partNumber,
+		ETag:       aws.ToString(result.ETag),
+	}, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the final object
+func (s *S3Provider) CompleteMultipartUpload(path string, uploadID string, parts []common.CompletedPart) error {
+	completedParts := make([]types.CompletedPart, len(parts))
+	for i, part := range parts {
+		completedParts[i] = types.CompletedPart{
+			PartNumber: aws.Int32(part.PartNumber),
+			ETag:       aws.String(part.ETag),
+		}
+	}
+
+	_, err := s.client.CompleteMultipartUpload(context.TODO(), &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(s.bucketName),
+		Key:      aws.String(path),
+		UploadId: aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completedParts,
+		},
+	})
+	return err
+}
+
+// AbortMultipartUpload discards a multipart upload and all of its parts
+func (s *S3Provider) AbortMultipartUpload(path string, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(context.TODO(), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucketName),
+		Key:      aws.String(path),
+		UploadId: aws.String(uploadID),
+	})
+	return err
+}
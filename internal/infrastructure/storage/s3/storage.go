@@ -2,6 +2,7 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,18 +11,25 @@ import (
 	"time"
 
 	"easy-storage/internal/config"
+	"easy-storage/internal/domain/common"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/uuid"
 )
 
+// minPartSize is the smallest part size accepted by S3 for all but the last part
+const minPartSize = 5 * 1024 * 1024
+
 // S3Provider implements the storage interface for S3-compatible storage
 type S3Provider struct {
-	client     *s3.Client
-	bucketName string
+	client      *s3.Client
+	bucketName  string
+	partSize    int64
+	concurrency int
 }
 
 // NewS3Provider creates a new S3 storage provider
@@ -77,28 +85,32 @@ func NewS3Provider(cfg *config.StorageConfig) (*S3Provider, error) {
 
 	log.Printf("Successfully connected to S3 endpoint")
 
+	partSize := int64(cfg.PartSizeMB) * 1024 * 1024
+	if partSize < minPartSize {
+		partSize = minPartSize
+	}
+
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
 	return &S3Provider{
-		client:     client,
-		bucketName: cfg.Bucket,
+		client:      client,
+		bucketName:  cfg.Bucket,
+		partSize:    partSize,
+		concurrency: concurrency,
 	}, nil
 }
 
 // Upload uploads a file to S3 storage
-func (s *S3Provider) Upload(filename string, contentType string, file io.Reader) (string, error) {
-
-	log.Printf("S3 Credentials - AccessKey: %s, SecretKey: %s", s.bucketName, context.TODO())
+// Files larger than one part are streamed as a parallel multipart upload
+func (s *S3Provider) Upload(filename string, contentType string, size int64, file io.Reader) (string, error) {
 	// Generate a unique file path to avoid collisions
 	ext := filepath.Ext(filename)
 	uniquePath := time.Now().Format("2006/01/02/") + uuid.New().String() + ext
 
-	// Upload file to S3
-	_, err := s.client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(s.bucketName),
-		Key:         aws.String(uniquePath),
-		Body:        file,
-		ContentType: aws.String(contentType),
-	})
-	if err != nil {
+	if err := s.uploadStream(uniquePath, contentType, size, file); err != nil {
 		return "", err
 	}
 
@@ -150,3 +162,47 @@ func (s *S3Provider) GetSignedURL(path string, expiryTime int64) (string, error)
 
 	return request.URL, nil
 }
+
+// GetSignedUploadURL generates a presigned URL for uploading a new object directly to S3
+// The content type and length are part of the signature, so S3 rejects any other object
+func (s *S3Provider) GetSignedUploadURL(filename string, contentType string, size int64, expiryTime int64) (string, string, error) {
+	// Generate a unique file path to avoid collisions
+	ext := filepath.Ext(filename)
+	uniquePath := time.Now().Format("2006/01/02/") + uuid.New().String() + ext
+
+	presignClient := s3.NewPresignClient(s.client)
+
+	request, err := presignClient.PresignPutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucketName),
+		Key:           aws.String(uniquePath),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = time.Duration(expiryTime) * time.Second
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate presigned upload URL: %w", err)
+	}
+
+	return uniquePath, request.URL, nil
+}
+
+// Stat returns the size and content type of an object in S3 storage
+func (s *S3Provider) Stat(path string) (common.ObjectInfo, error) {
+	result, err := s.client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return common.ObjectInfo{}, common.ErrObjectNotFound
+		}
+		return common.ObjectInfo{}, err
+	}
+
+	return common.ObjectInfo{
+		Size:        aws.ToInt64(result.ContentLength),
+		ContentType: aws.ToString(result.ContentType),
+	}, nil
+}
@@ -0,0 +1,258 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"easy-storage/internal/domain/common"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// maxParts is the largest number of parts S3 accepts in a multipart upload
+const maxParts = 10000
+
+// maxPartSize is the largest part S3 accepts
+const maxPartSize = 5 * 1024 * 1024 * 1024
+
+// partGrowthInterval is the number of parts after which the parts of a stream of unknown length double in size
+// Starting from the 5 MiB minimum, 10,000 parts then hold more than 5 TiB, the largest object S3 accepts
+const partGrowthInterval = 900
+
+// errTooManyParts is returned when a stream is longer than its declared size allows
+var errTooManyParts = errors.New("upload exceeds the maximum number of parts")
+
+// partSizer gives the size of each part of a multipart upload
+type partSizer struct {
+	base    int64
+	growing bool // Parts grow every partGrowthInterval parts when the length of the stream is unknown
+}
+
+// newPartSizer sizes the parts of an upload of size bytes, -1 when unknown
+// Known sizes get parts large enough to fit in maxParts parts
+func (s *S3Provider) newPartSizer(size int64) partSizer {
+	if size < 0 {
+		return partSizer{base: s.partSize, growing: true}
+	}
+
+	base := s.partSize
+	if needed := (size + maxParts - 1) / maxParts; needed > base {
+		base = needed
+	}
+	return partSizer{base: base}
+}
+
+// size returns the size of a part, part numbers start at 1
+func (p partSizer) size(partNumber int32) int64 {
+	if !p.growing {
+		return p.base
+	}
+	return min(p.base<<((partNumber-1)/partGrowthInterval), maxPartSize)
+}
+
+// partUploader uploads the parts of a single multipart upload in parallel
+type partUploader struct {
+	provider *S3Provider
+	path     string
+	uploadID string
+	sizer    partSizer
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	parts   []common.CompletedPart
+	err     error
+	slots   chan struct{}      // Bounds memory use to one buffer per in-flight part
+	buffers chan *bytes.Buffer // Buffers of uploaded parts, reused for the next ones
+}
+
+// uploadStream uploads size bytes of content to path, size is -1 when unknown
+// Content shorter than one part is sent with a single PutObject request, buffers only grow with the data read
+func (s *S3Provider) uploadStream(path string, contentType string, size int64, content io.Reader) error {
+	sizer := s.newPartSizer(size)
+
+	firstPart := &bytes.Buffer{}
+	if size >= 0 {
+		firstPart.Grow(int(min(size, sizer.size(1))) + bytes.MinRead)
+	}
+	if err := readPart(content, firstPart, sizer.size(1)); err == io.EOF {
+		return s.putObject(path, contentType, firstPart.Bytes())
+	} else if err != nil {
+		return err
+	}
+
+	result, err := s.client.CreateMultipartUpload(context.TODO(), &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(path),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return err
+	}
+
+	uploader := &partUploader{
+		provider: s,
+		path:     path,
+		uploadID: aws.ToString(result.UploadId),
+		sizer:    sizer,
+		slots:    make(chan struct{}, s.concurrency),
+		buffers:  make(chan *bytes.Buffer, s.concurrency),
+	}
+
+	parts, err := uploader.upload(firstPart, content)
+	if err == nil {
+		err = s.CompleteMultipartUpload(path, uploader.uploadID, parts)
+	}
+
+	if err != nil {
+		// Abort so the uploaded parts do not keep using storage
+		if abortErr := s.AbortMultipartUpload(path, uploader.uploadID); abortErr != nil {
+			log.Printf("Error aborting multipart upload %s: %v", uploader.uploadID, abortErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// upload reads parts from content and uploads them concurrently
+func (u *partUploader) upload(firstPart *bytes.Buffer, content io.Reader) ([]common.CompletedPart, error) {
+	u.slots <- struct{}{} // The first part is already read
+
+	buffer := firstPart
+	var readErr error
+
+	for partNumber := int32(1); buffer.Len() > 0 && u.failure() == nil; partNumber++ {
+		if partNumber > maxParts {
+			readErr = errTooManyParts
+			break
+		}
+
+		u.wg.Add(1)
+		go u.uploadPart(partNumber, buffer)
+
+		if readErr != nil {
+			break
+		}
+
+		u.slots <- struct{}{}
+		buffer = u.buffer(u.sizer.size(partNumber + 1))
+		if readErr = readPart(content, buffer, u.sizer.size(partNumber+1)); readErr != nil && readErr != io.EOF {
+			break
+		}
+	}
+
+	u.wg.Wait()
+
+	if readErr != nil && readErr != io.EOF {
+		return nil, readErr
+	}
+	if err := u.failure(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(u.parts, func(i, j int) bool { return u.parts[i].PartNumber < u.parts[j].PartNumber })
+	return u.parts, nil
+}
+
+// buffer returns an empty buffer able to hold a part of size bytes without growing
+func (u *partUploader) buffer(size int64) *bytes.Buffer {
+	var buffer *bytes.Buffer
+	select {
+	case buffer = <-u.buffers:
+		buffer.Reset()
+	default:
+		buffer = &bytes.Buffer{}
+	}
+
+	// Room for the read that finds the end of the part
+	buffer.Grow(int(size) + bytes.MinRead)
+	return buffer
+}
+
+// uploadPart uploads one part, then frees its slot and keeps its buffer for the next parts
+func (u *partUploader) uploadPart(partNumber int32, buffer *bytes.Buffer) {
+	defer u.wg.Done()
+	defer func() {
+		select {
+		case u.buffers <- buffer:
+		default:
+		}
+		<-u.slots
+	}()
+
+	part, err := u.provider.UploadPart(u.path, u.uploadID, partNumber, bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err != nil {
+		if u.err == nil {
+			u.err = err
+		}
+		return
+	}
+	u.parts = append(u.parts, part)
+}
+
+// failure returns the first error reported by a part upload
+func (u *partUploader) failure() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.err
+}
+
+// readPart reads up to limit bytes of content into buffer
+// It returns io.EOF when content ends before limit, the part then holds the rest of the content
+func readPart(content io.Reader, buffer *bytes.Buffer, limit int64) error {
+	_, err := io.CopyN(buffer, content, limit)
+	return err
+}
+
+// putObject uploads a small object with a single request
+func (s *S3Provider) putObject(path string, contentType string, data []byte) error {
+	_, err := s.client.PutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(path),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+// AbortStaleMultipartUploads discards multipart uploads started more than olderThan ago
+func (s *S3Provider) AbortStaleMultipartUploads(olderThan time.Duration) error {
+	cutoff := time.Now().Add(-olderThan)
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(s.bucketName),
+	}
+
+	for {
+		result, err := s.client.ListMultipartUploads(context.TODO(), input)
+		if err != nil {
+			return err
+		}
+
+		for _, upload := range result.Uploads {
+			if upload.Initiated == nil || upload.Initiated.After(cutoff) {
+				continue
+			}
+			if err := s.AbortMultipartUpload(aws.ToString(upload.Key), aws.ToString(upload.UploadId)); err != nil {
+				log.Printf("Error aborting stale multipart upload %s: %v", aws.ToString(upload.UploadId), err)
+			}
+		}
+
+		if !aws.ToBool(result.IsTruncated) {
+			return nil
+		}
+		input.KeyMarker = result.NextKeyMarker
+		input.UploadIdMarker = result.NextUploadIdMarker
+	}
+}
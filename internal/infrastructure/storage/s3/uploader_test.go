@@ -0,0 +1,40 @@
+package s3
+
+import "testing"
+
+func TestPartSizerFitsMaxParts(t *testing.T) {
+	provider := &S3Provider{partSize: minPartSize}
+
+	tests := []struct {
+		name string
+		size int64
+	}{
+		{name: "empty", size: 0},
+		{name: "smaller than a part", size: 1024},
+		{name: "fits with the configured part size", size: minPartSize * maxParts},
+		{name: "needs larger parts", size: minPartSize*maxParts + 1},
+		{name: "1 TiB", size: 1 << 40},
+		{name: "unknown length", size: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sizer := provider.newPartSizer(tt.size)
+
+			var total int64
+			for partNumber := int32(1); partNumber <= maxParts; partNumber++ {
+				if size := sizer.size(partNumber); size < minPartSize || size > maxPartSize {
+					t.Fatalf("part %d is %d bytes, outside the sizes S3 accepts", partNumber, size)
+				}
+				total += sizer.size(partNumber)
+			}
+
+			want := tt.size
+			if tt.size < 0 {
+				want = 5 << 40 // The largest object S3 accepts
+			}
+			if total < want {
+				t.Errorf("%d parts hold %d bytes, want at least %d", maxParts, total, want)
+			}
+		})
+	}
+}